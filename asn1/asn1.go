@@ -0,0 +1,161 @@
+// Package asn1 implements streaming ASN.1 BER and DER encoding on top of binutils.BinaryReader and binutils.BinaryWriter.
+//
+// Reader iterates over elements one by one without loading whole structure into memory.
+// It accepts BER encoding including indefinite lengths and constructed strings,
+// or checks DER canonical form in strict mode. Writer always produces DER,
+// except for indefinite lengths explicitly requested using WriteHeader.
+package asn1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/amarin/binutils"
+)
+
+// Class is an ASN.1 tag class.
+type Class uint8
+
+// Tag classes defined by X.690.
+const (
+	ClassUniversal       Class = 0
+	ClassApplication     Class = 1
+	ClassContextSpecific Class = 2
+	ClassPrivate         Class = 3
+)
+
+// Universal class tag numbers.
+const (
+	TagEndOfContents   uint64 = 0
+	TagBoolean         uint64 = 1
+	TagInteger         uint64 = 2
+	TagBitString       uint64 = 3
+	TagOctetString     uint64 = 4
+	TagNull            uint64 = 5
+	TagOID             uint64 = 6
+	TagEnumerated      uint64 = 10
+	TagUTF8String      uint64 = 12
+	TagSequence        uint64 = 16
+	TagSet             uint64 = 17
+	TagNumericString   uint64 = 18
+	TagPrintableString uint64 = 19
+	TagT61String       uint64 = 20
+	TagIA5String       uint64 = 22
+	TagUTCTime         uint64 = 23
+	TagGeneralizedTime uint64 = 24
+	TagBMPString       uint64 = 30
+)
+
+// Identifier and length octets bits.
+const (
+	constructedBit  byte = 0x20
+	tagMask         byte = 0x1f
+	longFormBit     byte = 0x80
+	lengthReserved  byte = 0xff
+	indefiniteForm  byte = 0x80
+	continuationBit byte = 0x80
+)
+
+// IndefiniteLength is a Header.Length value of BER indefinite-length element.
+const IndefiniteLength = -1
+
+// Some predefined errors used during processing.
+var (
+	// Error indicates any ASN.1 errors.
+	Error = fmt.Errorf("%w: asn1", binutils.Error)
+
+	// ErrMalformed returned if encoded data is not valid BER.
+	ErrMalformed = fmt.Errorf("%w: malformed data", Error)
+
+	// ErrNotCanonical returned in strict mode if encoded data is valid BER but not DER.
+	ErrNotCanonical = fmt.Errorf("%w: not DER", Error)
+
+	// ErrUnexpectedTag returned if element tag differs from expected one.
+	ErrUnexpectedTag = fmt.Errorf("%w: unexpected tag", Error)
+
+	// ErrLimit returned if nesting depth or element length limit exceeded.
+	ErrLimit = fmt.Errorf("%w: limit exceeded", Error)
+
+	// ErrOverflow returned if value does not fit into Go type.
+	ErrOverflow = fmt.Errorf("%w: value overflow", Error)
+)
+
+// Header describes element identifier and length octets.
+type Header struct {
+	Class       Class  // tag class
+	Constructed bool   // true if element contains nested elements
+	Tag         uint64 // tag number
+	Length      int    // content length or IndefiniteLength
+}
+
+// String returns header representation like [UNIVERSAL 16 constructed, 10 bytes].
+func (h Header) String() string {
+	form, length := "primitive", strconv.Itoa(h.Length)+" bytes"
+	if h.Constructed {
+		form = "constructed"
+	}
+
+	if h.Length == IndefiniteLength {
+		length = "indefinite"
+	}
+
+	return fmt.Sprintf("[%v %d %v, %v]", h.Class, h.Tag, form, length)
+}
+
+// String returns class name.
+func (c Class) String() string {
+	switch c {
+	case ClassUniversal:
+		return "UNIVERSAL"
+	case ClassApplication:
+		return "APPLICATION"
+	case ClassContextSpecific:
+		return "CONTEXT"
+	default:
+		return "PRIVATE"
+	}
+}
+
+// ObjectIdentifier represents OBJECT IDENTIFIER value as sequence of arcs.
+type ObjectIdentifier []uint64
+
+// String returns dotted representation like 1.2.840.113549.
+func (oid ObjectIdentifier) String() string {
+	parts := make([]string, len(oid))
+	for idx, arc := range oid {
+		parts[idx] = strconv.FormatUint(arc, 10)
+	}
+
+	return strings.Join(parts, ".")
+}
+
+// Equal returns true if identifiers are equal.
+func (oid ObjectIdentifier) Equal(other ObjectIdentifier) bool {
+	if len(oid) != len(other) {
+		return false
+	}
+
+	for idx := range oid {
+		if oid[idx] != other[idx] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// BitString represents BIT STRING value.
+type BitString struct {
+	Bytes     []byte // bits packed starting from most significant bit of the first byte
+	BitLength int    // number of valid bits
+}
+
+// At returns bit at index or 0 if index is out of range.
+func (b BitString) At(index int) int {
+	if index < 0 || index >= b.BitLength {
+		return 0
+	}
+
+	return int(b.Bytes[index/8]>>(7-uint(index%8))) & 1
+}
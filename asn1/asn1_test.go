@@ -0,0 +1,403 @@
+package asn1_test
+
+import (
+	"bytes"
+	stdasn1 "encoding/asn1"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amarin/binutils"
+	"github.com/amarin/binutils/asn1"
+)
+
+func reader(t *testing.T, hexString string) *asn1.Reader {
+	data, err := hex.DecodeString(hexString)
+	require.NoError(t, err)
+
+	return asn1.NewReader(binutils.NewBinaryReader(bytes.NewReader(data)))
+}
+
+func write(t *testing.T, fill func(writer *asn1.Writer) error) string {
+	buffer := new(bytes.Buffer)
+	require.NoError(t, fill(asn1.NewWriter(binutils.NewBinaryWriter(buffer))))
+
+	return hex.EncodeToString(buffer.Bytes())
+}
+
+type certificateLike struct {
+	Version   int `asn1:"explicit,tag:0"`
+	Serial    *big.Int
+	Algorithm stdasn1.ObjectIdentifier
+	Name      string `asn1:"utf8"`
+	NotBefore time.Time
+	NotAfter  time.Time `asn1:"generalized"`
+	Flags     bool
+	Key       stdasn1.BitString
+	Data      []byte
+}
+
+func sample() certificateLike {
+	return certificateLike{
+		Version:   2,
+		Serial:    new(big.Int).Lsh(big.NewInt(-1), 70),
+		Algorithm: stdasn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11},
+		Name:      "Привет",
+		NotBefore: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+		NotAfter:  time.Date(2051, 1, 2, 3, 4, 5, 0, time.UTC),
+		Flags:     true,
+		Key:       stdasn1.BitString{Bytes: []byte{0xab, 0xc0}, BitLength: 10},
+		Data:      bytes.Repeat([]byte{1}, 200),
+	}
+}
+
+func TestWriter_MatchesStandardLibrary(t *testing.T) {
+	source := sample()
+	expected, err := stdasn1.Marshal(source)
+	require.NoError(t, err)
+
+	encoded := write(t, func(writer *asn1.Writer) error {
+		return writer.WriteSequence(func(nested *asn1.Writer) error {
+			for _, step := range []func() error{
+				func() error {
+					return nested.WriteExplicit(0, func(explicit *asn1.Writer) error {
+						return explicit.WriteInt64(int64(source.Version))
+					})
+				},
+				func() error { return nested.WriteInteger(source.Serial) },
+				func() error { return nested.WriteOID(asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}) },
+				func() error { return nested.WriteString(asn1.TagUTF8String, source.Name) },
+				func() error { return nested.WriteUTCTime(source.NotBefore) },
+				func() error { return nested.WriteGeneralizedTime(source.NotAfter) },
+				func() error { return nested.WriteBoolean(source.Flags) },
+				func() error { return nested.WriteBitString(asn1.BitString{Bytes: []byte{0xab, 0xc0}, BitLength: 10}) },
+				func() error { return nested.WriteOctetString(source.Data) },
+			} {
+				if err := step(); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	})
+	require.Equal(t, hex.EncodeToString(expected), encoded)
+}
+
+func TestReader_Strict(t *testing.T) {
+	source := sample()
+	encoded, err := stdasn1.Marshal(source)
+	require.NoError(t, err)
+
+	target := reader(t, hex.EncodeToString(encoded))
+	target.SetStrict(true)
+	require.True(t, target.Strict())
+
+	fields, err := target.ReadSequence()
+	require.NoError(t, err)
+
+	version, err := fields.Expect(asn1.ClassContextSpecific, 0)
+	require.NoError(t, err)
+	require.True(t, version.Constructed)
+
+	explicit, err := version.Elements().Expect(asn1.ClassUniversal, asn1.TagInteger)
+	require.NoError(t, err)
+
+	value, err := explicit.Int64()
+	require.NoError(t, err)
+	require.Equal(t, int64(2), value)
+
+	serial, err := fields.Expect(asn1.ClassUniversal, asn1.TagInteger)
+	require.NoError(t, err)
+
+	serialValue, err := serial.Integer()
+	require.NoError(t, err)
+	require.Equal(t, source.Serial, serialValue)
+
+	oid, err := fields.Expect(asn1.ClassUniversal, asn1.TagOID)
+	require.NoError(t, err)
+
+	oidValue, err := oid.OID()
+	require.NoError(t, err)
+	require.Equal(t, "1.2.840.113549.1.1.11", oidValue.String())
+	require.True(t, oidValue.Equal(asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 11}))
+
+	name, err := fields.Expect(asn1.ClassUniversal, asn1.TagUTF8String)
+	require.NoError(t, err)
+
+	text, err := name.Text()
+	require.NoError(t, err)
+	require.Equal(t, source.Name, text)
+
+	for _, expected := range []time.Time{source.NotBefore, source.NotAfter} {
+		element, err := fields.Next()
+		require.NoError(t, err)
+
+		when, err := element.Time()
+		require.NoError(t, err)
+		require.True(t, expected.Equal(when), when)
+	}
+
+	flags, err := fields.Expect(asn1.ClassUniversal, asn1.TagBoolean)
+	require.NoError(t, err)
+
+	flag, err := flags.Boolean()
+	require.NoError(t, err)
+	require.True(t, flag)
+
+	key, err := fields.Expect(asn1.ClassUniversal, asn1.TagBitString)
+	require.NoError(t, err)
+
+	bits, err := key.BitString()
+	require.NoError(t, err)
+	require.Equal(t, 10, bits.BitLength)
+	require.Equal(t, 1, bits.At(0))
+	require.Equal(t, 1, bits.At(9))
+	require.Equal(t, 0, bits.At(10))
+
+	data, err := fields.Expect(asn1.ClassUniversal, asn1.TagOctetString)
+	require.NoError(t, err)
+	require.Equal(t, 200, data.Length)
+
+	_, err = fields.Next() // skips unread data
+	require.True(t, errors.Is(err, io.EOF))
+
+	_, err = target.Next()
+	require.True(t, errors.Is(err, io.EOF))
+}
+
+func TestReader_BER(t *testing.T) {
+	// indefinite SEQUENCE { constructed OCTET STRING { "ab", "c" }, indefinite SEQUENCE { NULL }, long form tag 40 }
+	target := reader(t, "3080"+"2480040261620401630000"+"30800500"+"0000"+"9f280101"+"0000"+"0101ff")
+
+	sequence, err := target.Next()
+	require.NoError(t, err)
+	require.Equal(t, asn1.IndefiniteLength, sequence.Length)
+	require.Nil(t, sequence.Value)
+
+	fields := sequence.Elements()
+
+	octets, err := fields.Next()
+	require.NoError(t, err)
+
+	data, err := octets.Bytes()
+	require.NoError(t, err)
+	require.Equal(t, "abc", string(data))
+
+	_, err = fields.Next() // inner indefinite sequence skipped without reading
+	require.NoError(t, err)
+
+	tagged, err := fields.Next()
+	require.NoError(t, err)
+	require.Equal(t, asn1.Header{Class: asn1.ClassContextSpecific, Tag: 40, Length: 1}, tagged.Header)
+	require.Equal(t, "[CONTEXT 40 primitive, 1 bytes]", tagged.Header.String())
+
+	_, err = fields.Next()
+	require.True(t, errors.Is(err, io.EOF))
+
+	boolean, err := target.Expect(asn1.ClassUniversal, asn1.TagBoolean)
+	require.NoError(t, err)
+
+	value, err := boolean.Boolean()
+	require.NoError(t, err)
+	require.True(t, value)
+}
+
+func TestElement_Bytes_Tagged(t *testing.T) {
+	// [0] IMPLICIT OCTET STRING constructed from UNIVERSAL 4 segments as used by PKCS#7
+	target := reader(t, "a080"+"04026162"+"040163"+"0000")
+
+	tagged, err := target.Expect(asn1.ClassContextSpecific, 0)
+	require.NoError(t, err)
+
+	data, err := tagged.Bytes()
+	require.NoError(t, err)
+	require.Equal(t, "abc", string(data))
+
+	for _, hex := range []string{
+		"a080" + "a0026162" + "0000",            // segment is not UNIVERSAL
+		"a080" + "04026162" + "0c0163" + "0000", // segments of different types
+		"2480" + "0c026162" + "0000",            // segment type differs from UNIVERSAL element type
+	} {
+		element, err := reader(t, hex).Next()
+		require.NoError(t, err)
+
+		_, err = element.Bytes()
+		require.True(t, errors.Is(err, asn1.ErrMalformed), "%v: %v", hex, err)
+	}
+}
+
+func TestReader_Errors(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		hex      string
+		strict   bool
+		expected error
+	}{
+		{"indefinite_strict", "30800000", true, asn1.ErrNotCanonical},
+		{"long_length_strict", "04810100", true, asn1.ErrNotCanonical},
+		{"length_leading_zero", "0482000100", true, asn1.ErrNotCanonical},
+		{"low_tag_long_form", "9f0100", true, asn1.ErrNotCanonical},
+		{"tag_leading_zero", "9f800100", false, asn1.ErrMalformed},
+		{"reserved_length", "04ff", false, asn1.ErrMalformed},
+		{"indefinite_primitive", "0480", false, asn1.ErrMalformed},
+		{"unexpected_eoc", "0000", false, asn1.ErrMalformed},
+		{"length_exceeds_parent", "30030405010203", false, asn1.ErrMalformed},
+		{"missing_eoc", "3080", false, io.ErrUnexpectedEOF},
+		{"truncated_length", "0482", false, io.ErrUnexpectedEOF},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			target := reader(t, tt.hex)
+			target.SetStrict(tt.strict)
+
+			element, err := target.Next()
+			if err == nil && element.Constructed {
+				_, err = element.Elements().Next()
+			}
+
+			require.True(t, errors.Is(err, tt.expected), err)
+		})
+	}
+}
+
+func TestElement_Values(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		hex      string
+		strict   bool
+		decode   func(element *asn1.Element) (interface{}, error)
+		expected interface{}
+		err      error
+	}{
+		{"int_zero", "020100", false, func(e *asn1.Element) (interface{}, error) { return e.Int64() }, int64(0), nil},
+		{"int_negative", "0202ff7f", false, func(e *asn1.Element) (interface{}, error) { return e.Int64() }, int64(-129), nil},
+		{"int_not_minimal", "02020001", false, func(e *asn1.Element) (interface{}, error) { return e.Int64() }, nil, asn1.ErrMalformed},
+		{"int_empty", "0200", false, func(e *asn1.Element) (interface{}, error) { return e.Int64() }, nil, asn1.ErrMalformed},
+		{"int_overflow", "0209010000000000000000", false, func(e *asn1.Element) (interface{}, error) { return e.Int64() }, nil, asn1.ErrOverflow},
+		{"bool_ber", "010101", false, func(e *asn1.Element) (interface{}, error) { return e.Boolean() }, true, nil},
+		{"bool_der", "010101", true, func(e *asn1.Element) (interface{}, error) { return e.Boolean() }, nil, asn1.ErrNotCanonical},
+		{"null", "0500", true, func(e *asn1.Element) (interface{}, error) { return nil, e.Null() }, nil, nil},
+		{"oid_leading_zero", "06032a8001", false, func(e *asn1.Element) (interface{}, error) { return e.OID() }, nil, asn1.ErrMalformed},
+		{"oid_truncated", "06022a86", false, func(e *asn1.Element) (interface{}, error) { return e.OID() }, nil, asn1.ErrMalformed},
+		{"oid_joint", "0603883703", false, func(e *asn1.Element) (interface{}, error) { return e.OID() },
+			asn1.ObjectIdentifier{2, 999, 3}, nil},
+		{"bits_unused_ber", "030206ff", false, func(e *asn1.Element) (interface{}, error) { return e.BitString() },
+			asn1.BitString{Bytes: []byte{0xff}, BitLength: 2}, nil},
+		{"bits_unused_der", "030206ff", true, func(e *asn1.Element) (interface{}, error) { return e.BitString() }, nil, asn1.ErrNotCanonical},
+		{"bits_invalid", "030108", false, func(e *asn1.Element) (interface{}, error) { return e.BitString() }, nil, asn1.ErrMalformed},
+		{"utc_1950", "170d3530303130313030303030305a", true, func(e *asn1.Element) (interface{}, error) { return e.Time() },
+			time.Date(1950, 1, 1, 0, 0, 0, 0, time.UTC), nil},
+		{"utc_ber_offset", "17113230303130323033303430352b30313030", false,
+			func(e *asn1.Element) (interface{}, error) { t, err := e.Time(); return t.UTC(), err },
+			time.Date(2020, 1, 2, 2, 4, 5, 0, time.UTC), nil},
+		{"utc_der_offset", "17113230303130323033303430352b30313030", true, func(e *asn1.Element) (interface{}, error) { return e.Time() },
+			nil, asn1.ErrNotCanonical},
+		{"generalized_fraction", "181232303230303130323033303430352e32355a", true,
+			func(e *asn1.Element) (interface{}, error) { return e.Time() }, time.Date(2020, 1, 2, 3, 4, 5, 250000000, time.UTC), nil},
+		{"generalized_trailing_zero", "181232303230303130323033303430352e35305a", true,
+			func(e *asn1.Element) (interface{}, error) { return e.Time() }, nil, asn1.ErrNotCanonical},
+		{"time_wrong_tag", "0400", false, func(e *asn1.Element) (interface{}, error) { return e.Time() }, nil, asn1.ErrUnexpectedTag},
+		{"constructed_der", "24030401aa", true, func(e *asn1.Element) (interface{}, error) { return e.Bytes() }, nil, asn1.ErrNotCanonical},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			target := reader(t, tt.hex)
+			target.SetStrict(tt.strict)
+
+			element, err := target.Next()
+			require.NoError(t, err)
+
+			value, err := tt.decode(element)
+			if tt.err != nil {
+				require.True(t, errors.Is(err, tt.err), err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestReader_SetMaxDepth(t *testing.T) {
+	target := reader(t, "3004300230000500")
+	target.SetMaxDepth(2)
+
+	nested, err := target.ReadSequence()
+	require.NoError(t, err)
+
+	nested, err = nested.ReadSequence()
+	require.NoError(t, err)
+
+	_, err = nested.ReadSequence()
+	require.True(t, errors.Is(err, asn1.ErrLimit))
+
+	_, err = target.Expect(asn1.ClassUniversal, asn1.TagInteger)
+	require.True(t, errors.Is(err, asn1.ErrUnexpectedTag))
+}
+
+func TestReader_SetMaxLength(t *testing.T) {
+	_, err := reader(t, "04847fffffff").Raw()
+	require.True(t, errors.Is(err, asn1.ErrLimit), "default maximum must be checked, got %v", err)
+
+	target := reader(t, "30050403010203")
+	target.SetMaxLength(4)
+	_, err = target.ReadSequence()
+	require.True(t, errors.Is(err, asn1.ErrLimit), "configured maximum must be checked, got %v", err)
+
+	target = reader(t, "308004040102030400000403010203")
+	target.SetMaxLength(3)
+
+	nested, err := target.ReadSequence()
+	require.NoError(t, err)
+
+	_, err = nested.Next()
+	require.True(t, errors.Is(err, asn1.ErrLimit), "nested reader must inherit maximum, got %v", err)
+}
+
+func TestWriter_WriteSet(t *testing.T) {
+	encoded := write(t, func(writer *asn1.Writer) error {
+		return writer.WriteSet(func(nested *asn1.Writer) error {
+			for _, value := range []int64{300, 1, -1, 2} {
+				if err := nested.WriteInt64(value); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	})
+	require.Equal(t, "310d"+"020101"+"020102"+"0201ff"+"0202012c", encoded)
+}
+
+func TestWriter_Indefinite(t *testing.T) {
+	encoded := write(t, func(writer *asn1.Writer) error {
+		header := asn1.Header{Class: asn1.ClassUniversal, Constructed: true, Tag: asn1.TagSequence, Length: asn1.IndefiniteLength}
+		if err := writer.WriteHeader(header); err != nil {
+			return err
+		}
+
+		if err := writer.WriteNull(); err != nil {
+			return err
+		}
+
+		return writer.WriteEndOfContents()
+	})
+	require.Equal(t, "308005000000", encoded)
+
+	raw, err := reader(t, encoded).Raw()
+	require.NoError(t, err)
+	require.Equal(t, encoded, hex.EncodeToString(raw))
+
+	writer := asn1.NewWriter(binutils.NewBinaryWriter(new(bytes.Buffer)))
+	require.True(t, errors.Is(writer.WriteHeader(asn1.Header{Length: asn1.IndefiniteLength}), asn1.ErrMalformed))
+	require.True(t, errors.Is(writer.WriteUTCTime(time.Date(2050, 1, 1, 0, 0, 0, 0, time.UTC)), asn1.ErrOverflow))
+	require.True(t, errors.Is(writer.WriteOID(asn1.ObjectIdentifier{1, 40}), asn1.ErrMalformed))
+	require.True(t, errors.Is(writer.WriteBitString(asn1.BitString{Bytes: []byte{1}, BitLength: 9}), asn1.ErrMalformed))
+}
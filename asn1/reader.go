@@ -0,0 +1,511 @@
+package asn1
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/amarin/binutils"
+)
+
+// DefaultMaxDepth is a default maximum nesting level of constructed elements.
+const DefaultMaxDepth = 64
+
+// DefaultMaxLength is a default maximum content length of definite-length elements.
+const DefaultMaxLength = binutils.DefaultMaxFrameSize
+
+// Element represents single ASN.1 element taken by Reader.
+type Element struct {
+	Header
+	// Value reads element content. It is nil for indefinite-length element, use Elements to iterate its content.
+	Value  *binutils.BinaryReader
+	parent *Reader
+	nested *Reader
+}
+
+// Reader iterates over ASN.1 elements taken from binutils.BinaryReader.
+type Reader struct {
+	reader     *binutils.BinaryReader
+	strict     bool
+	maxDepth   int
+	maxLength  int
+	depth      int
+	indefinite bool  // elements are terminated by end-of-contents octets
+	err        error // sticky error returned by Next
+	current    *Element
+}
+
+// NewReader creates Reader taking elements from reader.
+func NewReader(reader *binutils.BinaryReader) *Reader {
+	return &Reader{reader: reader, maxDepth: DefaultMaxDepth, maxLength: DefaultMaxLength}
+}
+
+// SetStrict enables or disables strict mode.
+// In strict mode elements and values not encoded in DER canonical form are rejected with ErrNotCanonical.
+// Nested readers inherit strict mode at creation time.
+func (r *Reader) SetStrict(strict bool) {
+	r.strict = strict
+}
+
+// Strict returns true if strict mode enabled.
+func (r *Reader) Strict() bool {
+	return r.strict
+}
+
+// SetMaxDepth sets maximum nesting level of constructed elements.
+func (r *Reader) SetMaxDepth(depth int) {
+	r.maxDepth = depth
+}
+
+// SetMaxLength sets maximum content length of definite-length elements.
+// Nested readers inherit maximum length at creation time.
+func (r *Reader) SetMaxLength(length int) {
+	r.maxLength = length
+}
+
+// notCanonical returns ErrNotCanonical in strict mode or nil otherwise.
+func (r *Reader) notCanonical(format string, args ...interface{}) error {
+	if !r.strict {
+		return nil
+	}
+
+	return fmt.Errorf("%w: "+format, append([]interface{}{ErrNotCanonical}, args...)...)
+}
+
+// readByte reads single identifier or length octet.
+// Inside indefinite-length element io.EOF is unexpected.
+func (r *Reader) readByte() (byte, error) {
+	value, err := r.reader.ReadUint8()
+	if errors.Is(err, io.EOF) && r.indefinite {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return value, err
+}
+
+// readHeader reads identifier and length octets.
+func (r *Reader) readHeader() (header Header, err error) {
+	identifier, err := r.readByte()
+	if err != nil {
+		return header, err
+	}
+
+	header.Class = Class(identifier >> 6)
+	header.Constructed = identifier&constructedBit != 0
+	header.Tag = uint64(identifier & tagMask)
+
+	if header.Tag == uint64(tagMask) {
+		if header.Tag, err = r.readTag(); err != nil {
+			return header, err
+		}
+	}
+
+	if header.Length, err = r.readLength(); err != nil {
+		return header, err
+	}
+
+	if header.Length == IndefiniteLength && !header.Constructed {
+		return header, fmt.Errorf("%w: indefinite length of primitive element", ErrMalformed)
+	}
+
+	return header, nil
+}
+
+// readTag reads high tag number form.
+func (r *Reader) readTag() (tag uint64, err error) {
+	for idx := 0; ; idx++ {
+		part, err := r.readByte()
+
+		switch {
+		case errors.Is(err, io.EOF):
+			return 0, io.ErrUnexpectedEOF
+		case err != nil:
+			return 0, err
+		case idx == 0 && part == continuationBit:
+			return 0, fmt.Errorf("%w: tag number has leading zero", ErrMalformed)
+		case tag > math.MaxUint64>>7:
+			return 0, fmt.Errorf("%w: tag number", ErrOverflow)
+		}
+
+		tag = tag<<7 | uint64(part&0x7f)
+
+		if part&continuationBit == 0 {
+			break
+		}
+	}
+
+	if tag < uint64(tagMask) {
+		return 0, r.notCanonical("tag %d in high tag number form", tag)
+	}
+
+	return tag, nil
+}
+
+// readLength reads definite or indefinite length octets.
+func (r *Reader) readLength() (int, error) {
+	first, err := r.readByte()
+
+	switch {
+	case errors.Is(err, io.EOF):
+		return 0, io.ErrUnexpectedEOF
+	case err != nil:
+		return 0, err
+	case first < longFormBit:
+		return int(first), nil
+	case first == indefiniteForm:
+		return IndefiniteLength, r.notCanonical("indefinite length")
+	case first == lengthReserved:
+		return 0, fmt.Errorf("%w: reserved length octet", ErrMalformed)
+	}
+
+	var length uint64
+
+	for idx := 0; idx < int(first&^longFormBit); idx++ {
+		part, err := r.readByte()
+
+		switch {
+		case errors.Is(err, io.EOF):
+			return 0, io.ErrUnexpectedEOF
+		case err != nil:
+			return 0, err
+		case idx == 0 && part == 0:
+			if err = r.notCanonical("length has leading zero"); err != nil {
+				return 0, err
+			}
+		case length > math.MaxInt32>>8:
+			return 0, fmt.Errorf("%w: length", ErrOverflow)
+		}
+
+		length = length<<8 | uint64(part)
+	}
+
+	if length < uint64(longFormBit) {
+		return 0, r.notCanonical("length %d in long form", length)
+	}
+
+	return int(length), nil
+}
+
+// Next skips unread content of previous element if any and reads next element header.
+// Returns io.EOF if no more elements available.
+func (r *Reader) Next() (*Element, error) {
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	if r.current != nil {
+		if err := r.current.skip(); err != nil {
+			return nil, err
+		}
+
+		r.current = nil
+	}
+
+	header, err := r.readHeader()
+	if err != nil {
+		if errors.Is(err, io.EOF) && r.indefinite {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return nil, err
+	}
+
+	if header.Class == ClassUniversal && header.Tag == TagEndOfContents {
+		if !r.indefinite || header.Constructed || header.Length != 0 {
+			return nil, fmt.Errorf("%w: unexpected end-of-contents", ErrMalformed)
+		}
+
+		r.err = io.EOF
+
+		return nil, io.EOF
+	}
+
+	if header.Length > r.maxLength {
+		return nil, fmt.Errorf("%w: length %d exceeds %d", ErrLimit, header.Length, r.maxLength)
+	}
+
+	if remaining := r.reader.Remaining(); remaining >= 0 && header.Length > remaining {
+		return nil, fmt.Errorf("%w: length %d exceeds %d bytes left in parent", ErrMalformed, header.Length, remaining)
+	}
+
+	r.current = &Element{Header: header, parent: r}
+	if header.Length != IndefiniteLength {
+		r.current.Value = r.reader.Sub(header.Length)
+	}
+
+	return r.current, nil
+}
+
+// Expect reads next element checking its class and tag. Returns ErrUnexpectedTag if they differ.
+func (r *Reader) Expect(class Class, tag uint64) (*Element, error) {
+	element, err := r.Next()
+	if err == nil && (element.Class != class || element.Tag != tag) {
+		return nil, fmt.Errorf("%w: %v, expected %v", ErrUnexpectedTag, quote(element.Class, element.Tag), quote(class, tag))
+	}
+
+	return element, err
+}
+
+// ReadSequence reads SEQUENCE header and returns Reader over its elements.
+func (r *Reader) ReadSequence() (*Reader, error) {
+	element, err := r.Expect(ClassUniversal, TagSequence)
+	if err != nil {
+		return nil, err
+	}
+
+	nested := element.Elements()
+	if nested.err != nil {
+		return nil, nested.err
+	}
+
+	return nested, nil
+}
+
+// Raw reads next element and returns its encoding.
+// Definite length octets of the returned encoding are always in DER form.
+func (r *Reader) Raw() ([]byte, error) {
+	element, err := r.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	buffer := new(bytes.Buffer)
+	if err = element.writeRaw(NewWriter(binutils.NewBinaryWriter(buffer))); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// writeRaw writes element encoding.
+func (e *Element) writeRaw(writer *Writer) error {
+	if err := writer.WriteHeader(e.Header); err != nil {
+		return err
+	}
+
+	if e.Value != nil {
+		content, err := e.Value.ReadBytesCount(e.Value.Remaining())
+		if err != nil {
+			return err
+		}
+
+		return writer.writer.WriteBytes(content)
+	}
+
+	nested := e.Elements()
+
+	for {
+		child, err := nested.Next()
+
+		switch {
+		case errors.Is(err, io.EOF):
+			return writer.WriteEndOfContents()
+		case err != nil:
+			return err
+		}
+
+		if err = child.writeRaw(writer); err != nil {
+			return err
+		}
+	}
+}
+
+// skip skips element content left unread.
+func (e *Element) skip() error {
+	if e.Value != nil {
+		_, err := e.Value.Drain()
+
+		return err
+	}
+
+	nested := e.Elements()
+
+	for {
+		if _, err := nested.Next(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+	}
+}
+
+// Elements returns Reader over nested elements of constructed element.
+func (e *Element) Elements() *Reader {
+	if e.nested != nil {
+		return e.nested
+	}
+
+	parent := e.parent
+	e.nested = &Reader{
+		reader:    e.Value,
+		strict:    parent.strict,
+		maxDepth:  parent.maxDepth,
+		maxLength: parent.maxLength,
+		depth:     parent.depth + 1,
+	}
+
+	switch {
+	case !e.Constructed:
+		e.nested.err = fmt.Errorf("%w: %v is primitive", ErrMalformed, e.Header)
+	case e.nested.depth > parent.maxDepth:
+		e.nested.err = fmt.Errorf("%w: depth %d", ErrLimit, parent.maxDepth)
+	case e.Value == nil:
+		e.nested.reader, e.nested.indefinite = parent.reader, true
+	}
+
+	return e.nested
+}
+
+// Bytes reads element content not taken yet.
+// Content of BER constructed string is concatenated from its segments.
+// Segments are UNIVERSAL elements tagged by underlying string type, e.g. UNIVERSAL 4 for OCTET STRING.
+// If element itself is not UNIVERSAL, e.g. implicitly tagged, underlying type is taken from the first segment.
+func (e *Element) Bytes() ([]byte, error) {
+	if !e.Constructed {
+		return e.Value.ReadBytesCount(e.Value.Remaining())
+	}
+
+	if err := e.parent.notCanonical("constructed string"); err != nil {
+		return nil, err
+	}
+
+	var (
+		result []byte
+		tag    = e.Tag                     // underlying string type tag
+		known  = e.Class == ClassUniversal // true if underlying string type is known
+		nested = e.Elements()
+	)
+
+	for {
+		segment, err := nested.Next()
+
+		switch {
+		case errors.Is(err, io.EOF):
+			return result, nil
+		case err != nil:
+			return nil, err
+		case !known && segment.Class == ClassUniversal:
+			tag, known = segment.Tag, true
+		}
+
+		if segment.Class != ClassUniversal || segment.Tag != tag {
+			return nil, fmt.Errorf("%w: constructed string segment %v", ErrMalformed, segment.Header)
+		}
+
+		data, err := segment.Bytes()
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, data...)
+	}
+}
+
+// primitive reads content of primitive element.
+func (e *Element) primitive() ([]byte, error) {
+	if e.Constructed {
+		return nil, fmt.Errorf("%w: %v is constructed", ErrMalformed, e.Header)
+	}
+
+	return e.Bytes()
+}
+
+// Boolean decodes BOOLEAN content.
+func (e *Element) Boolean() (bool, error) {
+	content, err := e.primitive()
+
+	switch {
+	case err != nil:
+		return false, err
+	case len(content) != 1:
+		return false, fmt.Errorf("%w: boolean length %d", ErrMalformed, len(content))
+	case content[0] != 0 && content[0] != 0xff:
+		return true, e.parent.notCanonical("boolean %#02x", content[0])
+	default:
+		return content[0] != 0, nil
+	}
+}
+
+// Null decodes NULL content.
+func (e *Element) Null() error {
+	content, err := e.primitive()
+	if err == nil && len(content) != 0 {
+		return fmt.Errorf("%w: null length %d", ErrMalformed, len(content))
+	}
+
+	return err
+}
+
+// Integer decodes INTEGER or ENUMERATED content.
+func (e *Element) Integer() (*big.Int, error) {
+	content, err := e.primitive()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseInteger(content)
+}
+
+// Int64 decodes INTEGER or ENUMERATED content. Returns ErrOverflow if value does not fit int64.
+func (e *Element) Int64() (int64, error) {
+	value, err := e.Integer()
+
+	switch {
+	case err != nil:
+		return 0, err
+	case !value.IsInt64():
+		return 0, fmt.Errorf("%w: int64", ErrOverflow)
+	default:
+		return value.Int64(), nil
+	}
+}
+
+// OID decodes OBJECT IDENTIFIER content.
+func (e *Element) OID() (ObjectIdentifier, error) {
+	content, err := e.primitive()
+	if err != nil {
+		return nil, err
+	}
+
+	return parseOID(content)
+}
+
+// BitString decodes BIT STRING content. In strict mode unused bits must be zero.
+func (e *Element) BitString() (BitString, error) {
+	content, err := e.primitive()
+	if err != nil {
+		return BitString{}, err
+	}
+
+	return parseBitString(content, e.parent.strict)
+}
+
+// Text decodes character string content as is.
+func (e *Element) Text() (string, error) {
+	content, err := e.Bytes()
+
+	return string(content), err
+}
+
+// Time decodes UTCTime or GeneralizedTime content depending on element tag.
+// In strict mode only DER forms having seconds and Z suffix accepted.
+func (e *Element) Time() (time.Time, error) {
+	content, err := e.primitive()
+
+	switch {
+	case err != nil:
+		return time.Time{}, err
+	case e.Tag == TagUTCTime:
+		return parseUTCTime(content, e.parent.strict)
+	case e.Tag == TagGeneralizedTime:
+		return parseGeneralizedTime(content, e.parent.strict)
+	default:
+		return time.Time{}, fmt.Errorf("%w: %v, expected time", ErrUnexpectedTag, quote(e.Class, e.Tag))
+	}
+}
@@ -0,0 +1,268 @@
+package asn1
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"time"
+)
+
+// appendBase128 appends value encoded as base 128 integer having continuation bits.
+func appendBase128(buffer []byte, value uint64) []byte {
+	size := 1
+	for rest := value >> 7; rest > 0; rest >>= 7 {
+		size++
+	}
+
+	for idx := size - 1; idx >= 0; idx-- {
+		part := byte(value>>(7*uint(idx))) & 0x7f
+		if idx > 0 {
+			part |= continuationBit
+		}
+
+		buffer = append(buffer, part)
+	}
+
+	return buffer
+}
+
+// parseBase128 parses base 128 integer from data start. Returns value and bytes consumed.
+func parseBase128(data []byte) (value uint64, size int, err error) {
+	for size < len(data) {
+		part := data[size]
+		size++
+
+		switch {
+		case size == 1 && part == continuationBit:
+			return 0, 0, fmt.Errorf("%w: base 128 integer has leading zero", ErrMalformed)
+		case value > math.MaxUint64>>7:
+			return 0, 0, fmt.Errorf("%w: base 128 integer", ErrOverflow)
+		}
+
+		value = value<<7 | uint64(part&0x7f)
+
+		if part&continuationBit == 0 {
+			return value, size, nil
+		}
+	}
+
+	return 0, 0, fmt.Errorf("%w: truncated base 128 integer", ErrMalformed)
+}
+
+// encodeInteger returns minimal two's complement representation of value.
+func encodeInteger(value *big.Int) []byte {
+	if value.Sign() >= 0 {
+		data := value.Bytes()
+		if len(data) == 0 || data[0]&0x80 != 0 {
+			data = append([]byte{0}, data...)
+		}
+
+		return data
+	}
+
+	// two's complement of negative value is inverted bytes of (-value - 1)
+	data := new(big.Int).Sub(new(big.Int).Neg(value), big.NewInt(1)).Bytes()
+	for idx := range data {
+		data[idx] ^= 0xff
+	}
+
+	if len(data) == 0 || data[0]&0x80 == 0 {
+		data = append([]byte{0xff}, data...)
+	}
+
+	return data
+}
+
+// parseInteger parses two's complement INTEGER content.
+func parseInteger(data []byte) (*big.Int, error) {
+	switch {
+	case len(data) == 0:
+		return nil, fmt.Errorf("%w: empty integer", ErrMalformed)
+	case len(data) > 1 && (data[0] == 0 && data[1]&0x80 == 0 || data[0] == 0xff && data[1]&0x80 != 0):
+		return nil, fmt.Errorf("%w: integer is not minimally encoded", ErrMalformed)
+	}
+
+	if data[0]&0x80 == 0 {
+		return new(big.Int).SetBytes(data), nil
+	}
+
+	inverted := make([]byte, len(data))
+	for idx, value := range data {
+		inverted[idx] = value ^ 0xff
+	}
+
+	value := new(big.Int).SetBytes(inverted)
+
+	return value.Neg(value.Add(value, big.NewInt(1))), nil
+}
+
+// encodeOID returns OBJECT IDENTIFIER content.
+func encodeOID(oid ObjectIdentifier) ([]byte, error) {
+	if len(oid) < 2 || oid[0] > 2 || (oid[0] < 2 && oid[1] >= 40) || oid[1] > math.MaxUint64-80 {
+		return nil, fmt.Errorf("%w: invalid object identifier %v", ErrMalformed, oid)
+	}
+
+	data := appendBase128(nil, oid[0]*40+oid[1])
+	for _, arc := range oid[2:] {
+		data = appendBase128(data, arc)
+	}
+
+	return data, nil
+}
+
+// parseOID parses OBJECT IDENTIFIER content.
+func parseOID(data []byte) (ObjectIdentifier, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("%w: empty object identifier", ErrMalformed)
+	}
+
+	first, size, err := parseBase128(data)
+	if err != nil {
+		return nil, err
+	}
+
+	oid := make(ObjectIdentifier, 2, 8)
+
+	switch {
+	case first < 40:
+		oid[1] = first
+	case first < 80:
+		oid[0], oid[1] = 1, first-40
+	default:
+		oid[0], oid[1] = 2, first-80
+	}
+
+	for offset := size; offset < len(data); offset += size {
+		var arc uint64
+		if arc, size, err = parseBase128(data[offset:]); err != nil {
+			return nil, err
+		}
+
+		oid = append(oid, arc)
+	}
+
+	return oid, nil
+}
+
+// encodeBitString returns BIT STRING content having unused bits cleared.
+func encodeBitString(value BitString) ([]byte, error) {
+	if value.BitLength < 0 || (value.BitLength+7)/8 != len(value.Bytes) {
+		return nil, fmt.Errorf("%w: bit length %d for %d bytes", ErrMalformed, value.BitLength, len(value.Bytes))
+	}
+
+	unused := (8 - value.BitLength%8) % 8
+	data := append([]byte{byte(unused)}, value.Bytes...)
+
+	if unused > 0 {
+		data[len(data)-1] &= 0xff << uint(unused)
+	}
+
+	return data, nil
+}
+
+// parseBitString parses BIT STRING content. In strict mode unused bits must be zero.
+func parseBitString(data []byte, strict bool) (BitString, error) {
+	if len(data) == 0 {
+		return BitString{}, fmt.Errorf("%w: empty bit string", ErrMalformed)
+	}
+
+	unused := int(data[0])
+
+	switch {
+	case unused > 7 || (len(data) == 1 && unused > 0):
+		return BitString{}, fmt.Errorf("%w: %d unused bits", ErrMalformed, unused)
+	case strict && len(data) > 1 && data[len(data)-1]&(1<<uint(unused)-1) != 0:
+		return BitString{}, fmt.Errorf("%w: unused bits are not zero", ErrNotCanonical)
+	}
+
+	return BitString{Bytes: data[1:], BitLength: (len(data)-1)*8 - unused}, nil
+}
+
+// Time layouts used to format and parse time values.
+const (
+	utcTimeLayout         = "060102150405Z0700"
+	generalizedTimeLayout = "20060102150405.999999999Z0700"
+)
+
+// Time layouts accepted by non-strict mode in addition to DER ones.
+var (
+	utcTimeLayoutsBER         = []string{"0601021504Z0700"}                               // nolint:gochecknoglobals
+	generalizedTimeLayoutsBER = []string{"20060102150405.999999999", "200601021504Z0700"} // nolint:gochecknoglobals
+)
+
+// encodeUTCTime returns UTCTime content. Only years 1950..2049 are representable.
+func encodeUTCTime(value time.Time) ([]byte, error) {
+	value = value.UTC()
+	if year := value.Year(); year < 1950 || year >= 2050 {
+		return nil, fmt.Errorf("%w: year %d is out of UTCTime range", ErrOverflow, year)
+	}
+
+	return []byte(value.Format(utcTimeLayout)), nil
+}
+
+// parseUTCTime parses UTCTime content. In strict mode only DER form YYMMDDhhmmssZ accepted.
+func parseUTCTime(data []byte, strict bool) (time.Time, error) {
+	text := string(data)
+
+	value, err := time.Parse(utcTimeLayout, text)
+	if err != nil && !strict {
+		for _, layout := range utcTimeLayoutsBER {
+			if value, err = time.Parse(layout, text); err == nil {
+				break
+			}
+		}
+	}
+
+	switch {
+	case err != nil:
+		return time.Time{}, fmt.Errorf("%w: UTCTime %q", ErrMalformed, text)
+	case strict && value.UTC().Format(utcTimeLayout) != text:
+		return time.Time{}, fmt.Errorf("%w: UTCTime %q", ErrNotCanonical, text)
+	}
+
+	if value.Year() >= 2050 { // two digits years 50..99 mean 1950..1999
+		value = value.AddDate(-100, 0, 0)
+	}
+
+	return value, nil
+}
+
+// encodeGeneralizedTime returns GeneralizedTime content.
+func encodeGeneralizedTime(value time.Time) ([]byte, error) {
+	value = value.UTC()
+	if year := value.Year(); year < 0 || year > 9999 {
+		return nil, fmt.Errorf("%w: year %d is out of GeneralizedTime range", ErrOverflow, year)
+	}
+
+	return []byte(value.Format(generalizedTimeLayout)), nil
+}
+
+// parseGeneralizedTime parses GeneralizedTime content.
+// In strict mode only DER form YYYYMMDDhhmmss[.fff]Z accepted.
+func parseGeneralizedTime(data []byte, strict bool) (time.Time, error) {
+	text := string(data)
+
+	value, err := time.Parse(generalizedTimeLayout, text)
+	if err != nil && !strict {
+		for _, layout := range generalizedTimeLayoutsBER {
+			if value, err = time.Parse(layout, text); err == nil {
+				break
+			}
+		}
+	}
+
+	switch {
+	case err != nil:
+		return time.Time{}, fmt.Errorf("%w: GeneralizedTime %q", ErrMalformed, text)
+	case strict && value.UTC().Format(generalizedTimeLayout) != text:
+		return time.Time{}, fmt.Errorf("%w: GeneralizedTime %q", ErrNotCanonical, text)
+	}
+
+	return value, nil
+}
+
+// quote returns printable representation of tag used in errors.
+func quote(class Class, tag uint64) string {
+	return class.String() + " " + strconv.FormatUint(tag, 10)
+}
@@ -0,0 +1,224 @@
+package asn1
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/amarin/binutils"
+)
+
+// Writer writes ASN.1 elements into binutils.BinaryWriter.
+type Writer struct {
+	writer *binutils.BinaryWriter
+	buffer []byte // header encoding buffer
+}
+
+// NewWriter creates Writer writing elements into writer.
+func NewWriter(writer *binutils.BinaryWriter) *Writer {
+	return &Writer{writer: writer, buffer: make([]byte, 0, 2*binutils.Uint64size)}
+}
+
+// WriteHeader writes identifier and length octets.
+// Use IndefiniteLength to start BER indefinite-length constructed element and finish it using WriteEndOfContents.
+func (w *Writer) WriteHeader(header Header) error {
+	if header.Class > ClassPrivate {
+		return fmt.Errorf("%w: class %d", ErrMalformed, header.Class)
+	}
+
+	if header.Length == IndefiniteLength && !header.Constructed {
+		return fmt.Errorf("%w: indefinite length of primitive element", ErrMalformed)
+	}
+
+	identifier := byte(header.Class) << 6
+	if header.Constructed {
+		identifier |= constructedBit
+	}
+
+	if header.Tag < uint64(tagMask) {
+		w.buffer = append(w.buffer[:0], identifier|byte(header.Tag))
+	} else {
+		w.buffer = appendBase128(append(w.buffer[:0], identifier|tagMask), header.Tag)
+	}
+
+	switch {
+	case header.Length == IndefiniteLength:
+		w.buffer = append(w.buffer, indefiniteForm)
+	case header.Length < 0:
+		return fmt.Errorf("%w: length %d", ErrMalformed, header.Length)
+	case header.Length < int(longFormBit):
+		w.buffer = append(w.buffer, byte(header.Length))
+	default:
+		size := 0
+		for rest := header.Length; rest > 0; rest >>= 8 {
+			size++
+		}
+
+		w.buffer = append(w.buffer, longFormBit|byte(size))
+		for idx := size - 1; idx >= 0; idx-- {
+			w.buffer = append(w.buffer, byte(header.Length>>(8*uint(idx))))
+		}
+	}
+
+	return w.writer.WriteBytes(w.buffer)
+}
+
+// WriteEndOfContents writes end-of-contents octets finishing indefinite-length element.
+func (w *Writer) WriteEndOfContents() error {
+	return w.writer.WriteBytes([]byte{0, 0})
+}
+
+// WritePrimitive writes primitive element having specified content.
+func (w *Writer) WritePrimitive(class Class, tag uint64, content []byte) error {
+	if err := w.WriteHeader(Header{Class: class, Tag: tag, Length: len(content)}); err != nil {
+		return err
+	}
+
+	return w.writer.WriteBytes(content)
+}
+
+// WriteConstructed writes constructed element containing nested elements written by fill.
+// Nested elements are buffered to write definite length.
+func (w *Writer) WriteConstructed(class Class, tag uint64, fill func(nested *Writer) error) error {
+	content, err := w.nested(fill)
+	if err != nil {
+		return err
+	}
+
+	if err = w.WriteHeader(Header{Class: class, Constructed: true, Tag: tag, Length: len(content)}); err != nil {
+		return err
+	}
+
+	return w.writer.WriteBytes(content)
+}
+
+// nested returns content written by fill.
+func (w *Writer) nested(fill func(nested *Writer) error) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	if err := fill(NewWriter(binutils.NewBinaryWriter(buffer))); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// WriteSequence writes SEQUENCE containing nested elements written by fill.
+func (w *Writer) WriteSequence(fill func(nested *Writer) error) error {
+	return w.WriteConstructed(ClassUniversal, TagSequence, fill)
+}
+
+// WriteSet writes SET OF containing nested elements written by fill.
+// Elements are sorted by their encodings as required by DER.
+func (w *Writer) WriteSet(fill func(nested *Writer) error) error {
+	content, err := w.nested(fill)
+	if err != nil {
+		return err
+	}
+
+	var elements [][]byte
+
+	reader := NewReader(binutils.NewBinaryReader(bytes.NewReader(content)))
+	for offset := 0; offset < len(content); {
+		raw, err := reader.Raw()
+		if err != nil {
+			return err
+		}
+
+		elements, offset = append(elements, raw), offset+len(raw)
+	}
+
+	sort.Slice(elements, func(i, j int) bool { return bytes.Compare(elements[i], elements[j]) < 0 })
+
+	err = w.WriteHeader(Header{Class: ClassUniversal, Constructed: true, Tag: TagSet, Length: len(content)})
+	for _, element := range elements {
+		if err != nil {
+			return err
+		}
+
+		err = w.writer.WriteBytes(element)
+	}
+
+	return err
+}
+
+// WriteExplicit writes context-specific constructed element wrapping element written by fill.
+func (w *Writer) WriteExplicit(tag uint64, fill func(nested *Writer) error) error {
+	return w.WriteConstructed(ClassContextSpecific, tag, fill)
+}
+
+// WriteBoolean writes BOOLEAN.
+func (w *Writer) WriteBoolean(value bool) error {
+	content := []byte{0}
+	if value {
+		content[0] = 0xff
+	}
+
+	return w.WritePrimitive(ClassUniversal, TagBoolean, content)
+}
+
+// WriteNull writes NULL.
+func (w *Writer) WriteNull() error {
+	return w.WritePrimitive(ClassUniversal, TagNull, nil)
+}
+
+// WriteInteger writes INTEGER.
+func (w *Writer) WriteInteger(value *big.Int) error {
+	return w.WritePrimitive(ClassUniversal, TagInteger, encodeInteger(value))
+}
+
+// WriteInt64 writes INTEGER.
+func (w *Writer) WriteInt64(value int64) error {
+	return w.WriteInteger(big.NewInt(value))
+}
+
+// WriteOID writes OBJECT IDENTIFIER.
+func (w *Writer) WriteOID(value ObjectIdentifier) error {
+	content, err := encodeOID(value)
+	if err != nil {
+		return err
+	}
+
+	return w.WritePrimitive(ClassUniversal, TagOID, content)
+}
+
+// WriteBitString writes BIT STRING. Unused bits of the last byte are written as zeros.
+func (w *Writer) WriteBitString(value BitString) error {
+	content, err := encodeBitString(value)
+	if err != nil {
+		return err
+	}
+
+	return w.WritePrimitive(ClassUniversal, TagBitString, content)
+}
+
+// WriteOctetString writes OCTET STRING.
+func (w *Writer) WriteOctetString(value []byte) error {
+	return w.WritePrimitive(ClassUniversal, TagOctetString, value)
+}
+
+// WriteString writes character string of universal tag such as TagUTF8String or TagPrintableString.
+func (w *Writer) WriteString(tag uint64, value string) error {
+	return w.WritePrimitive(ClassUniversal, tag, []byte(value))
+}
+
+// WriteUTCTime writes UTCTime in DER form YYMMDDhhmmssZ. Time is converted to UTC.
+func (w *Writer) WriteUTCTime(value time.Time) error {
+	content, err := encodeUTCTime(value)
+	if err != nil {
+		return err
+	}
+
+	return w.WritePrimitive(ClassUniversal, TagUTCTime, content)
+}
+
+// WriteGeneralizedTime writes GeneralizedTime in DER form YYYYMMDDhhmmss[.fff]Z. Time is converted to UTC.
+func (w *Writer) WriteGeneralizedTime(value time.Time) error {
+	content, err := encodeGeneralizedTime(value)
+	if err != nil {
+		return err
+	}
+
+	return w.WritePrimitive(ClassUniversal, TagGeneralizedTime, content)
+}
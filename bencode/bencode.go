@@ -0,0 +1,44 @@
+// Package bencode implements BitTorrent bencoding on top of binutils.BinaryReader and binutils.BinaryWriter.
+//
+// Encoder always writes canonical form: dictionary keys are sorted as raw byte strings.
+// Decoder strictly rejects integers and lengths having leading zeros, negative zero and empty integers.
+// Raw byte span of any decoded sub-value could be taken using Decoder.Raw or RawMessage targets,
+// for example to calculate torrent info-hash from original "info" dictionary bytes.
+package bencode
+
+import (
+	"fmt"
+
+	"github.com/amarin/binutils"
+)
+
+// Type markers defined by specification.
+const (
+	markerInt    byte = 'i'
+	markerList   byte = 'l'
+	markerDict   byte = 'd'
+	markerEnd    byte = 'e'
+	markerLength byte = ':'
+)
+
+// Some predefined errors used during processing.
+var (
+	// Error indicates any bencode errors.
+	Error = fmt.Errorf("%w: bencode", binutils.Error)
+
+	// ErrUnsupportedType returned if value type could not be encoded or decoded.
+	ErrUnsupportedType = fmt.Errorf("%w: unsupported type", Error)
+
+	// ErrSyntax returned if encoded data is not well-formed or not canonical.
+	ErrSyntax = fmt.Errorf("%w: syntax error", Error)
+
+	// ErrLimit returned if decoded data exceeds decoder limits.
+	ErrLimit = fmt.Errorf("%w: limit exceeded", Error)
+
+	// ErrOverflow returned if decoded integer does not fit into Go type.
+	ErrOverflow = fmt.Errorf("%w: value overflow", Error)
+)
+
+// RawMessage is a raw encoded bencode value.
+// Encoder writes it as is, Decoder stores original bytes of decoded value into it.
+type RawMessage []byte
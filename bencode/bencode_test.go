@@ -0,0 +1,209 @@
+package bencode_test
+
+import (
+	"bytes"
+	"crypto/sha1" // nolint:gosec
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amarin/binutils"
+	"github.com/amarin/binutils/bencode"
+)
+
+func encode(t *testing.T, value interface{}) string {
+	buffer := new(bytes.Buffer)
+	require.NoError(t, bencode.NewEncoder(binutils.NewBinaryWriter(buffer)).Encode(value))
+
+	return buffer.String()
+}
+
+func decoder(data string) *bencode.Decoder {
+	return bencode.NewDecoder(binutils.NewBinaryReader(bytes.NewBufferString(data)))
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		value    interface{}
+		expected string
+	}{
+		{"int", 42, "i42e"},
+		{"zero", int8(0), "i0e"},
+		{"negative", int64(-3), "i-3e"},
+		{"uint", uint64(18446744073709551615), "i18446744073709551615e"},
+		{"bool", true, "i1e"},
+		{"string", "spam", "4:spam"},
+		{"empty_string", "", "0:"},
+		{"bytes", []byte{0, 0xff}, "2:\x00\xff"},
+		{"list", []interface{}{"spam", 42}, "l4:spami42ee"},
+		{"dict_sorted", map[string]interface{}{"zz": 1, "a": "x", "Z": []int{}}, "d1:Zle1:a1:x2:zzi1ee"},
+		{"raw", bencode.RawMessage("i1e"), "i1e"},
+		{"struct", struct {
+			Name   string `bencode:"name"`
+			Length int    `bencode:"length"`
+			Skip   int    `bencode:"-"`
+			Empty  string `bencode:"empty,omitempty"`
+			Info   bencode.RawMessage
+		}{Name: "a", Length: 1, Info: bencode.RawMessage("de")}, "d4:Infode6:lengthi1e4:name1:ae"},
+		{"struct_options", struct {
+			Name  string `bencode:",omitempty,unknown"`
+			Count int    `bencode:"count,unknown,omitempty"`
+			Flag  bool   `bencode:"flag,unknown"`
+		}{}, "d4:flagi0ee"},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, encode(t, tt.value))
+		})
+	}
+
+	err := bencode.NewEncoder(binutils.NewBinaryWriter(new(bytes.Buffer))).Encode(map[int]int{1: 1})
+	require.True(t, errors.Is(err, bencode.ErrUnsupportedType))
+}
+
+func TestDecoder_DecodeInterface(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		data     string
+		expected interface{}
+	}{
+		{"int", "i42e", int64(42)},
+		{"zero", "i0e", int64(0)},
+		{"negative", "i-3e", int64(-3)},
+		{"string", "4:spam", "spam"},
+		{"empty_string", "0:", ""},
+		{"list", "l4:spami42ee", []interface{}{"spam", int64(42)}},
+		{"empty_list", "le", []interface{}{}},
+		{"dict", "d3:cow3:moo4:spaml1:a1:bee",
+			map[string]interface{}{"cow": "moo", "spam": []interface{}{"a", "b"}}},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := decoder(tt.data).DecodeInterface()
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestDecoder_Strict(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		data     string
+		expected error
+	}{
+		{"leading_zero", "i03e", bencode.ErrSyntax},
+		{"negative_zero", "i-0e", bencode.ErrSyntax},
+		{"negative_leading_zero", "i-01e", bencode.ErrSyntax},
+		{"empty_int", "ie", bencode.ErrSyntax},
+		{"minus_only", "i-e", bencode.ErrSyntax},
+		{"not_digit", "i1ae", bencode.ErrSyntax},
+		{"length_leading_zero", "01:a", bencode.ErrSyntax},
+		{"unexpected", "x", bencode.ErrSyntax},
+		{"non_string_key", "di1ei1ee", bencode.ErrSyntax},
+		{"overflow", "i9223372036854775808e", bencode.ErrOverflow},
+		{"long_int", "i" + strings.Repeat("1", 1<<16) + "e", bencode.ErrOverflow},
+		{"long_length", strings.Repeat("1", 1<<16) + ":a", bencode.ErrOverflow},
+		{"depth", "lllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllllee", bencode.ErrLimit},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decoder(tt.data).DecodeInterface()
+			require.True(t, errors.Is(err, tt.expected), err)
+		})
+	}
+
+	value, err := decoder("i-9223372036854775808e").DecodeInterface()
+	require.NoError(t, err)
+	require.Equal(t, int64(math.MinInt64), value)
+
+	target := decoder("5:hello")
+	target.SetLimits(bencode.Limits{MaxDepth: 1, MaxStringLen: 4})
+	require.Equal(t, bencode.Limits{MaxDepth: 1, MaxStringLen: 4}, target.Limits())
+	_, err = target.DecodeString()
+	require.True(t, errors.Is(err, bencode.ErrLimit))
+}
+
+func TestDecoder_Raw(t *testing.T) {
+	info := "d6:lengthi3e4:name1:a12:piece lengthi16384ee"
+	torrent := "d8:announce3:url4:info" + info + "e"
+
+	target := decoder(torrent)
+	require.NoError(t, target.DecodeDictStart())
+
+	var infoRaw bencode.RawMessage
+
+	for {
+		done, err := target.DecodeEnd()
+		require.NoError(t, err)
+
+		if done {
+			break
+		}
+
+		key, err := target.DecodeString()
+		require.NoError(t, err)
+
+		if key != "info" {
+			raw, err := target.Raw()
+			require.NoError(t, err)
+			require.Equal(t, bencode.RawMessage("3:url"), raw)
+
+			continue
+		}
+
+		infoRaw, err = target.Raw()
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, info, string(infoRaw))
+	require.Equal(t, sha1.Sum([]byte(info)), sha1.Sum(infoRaw)) // nolint:gosec
+}
+
+func TestDecoder_Decode(t *testing.T) {
+	type file struct {
+		Length int64    `bencode:"length"`
+		Path   []string `bencode:"path"`
+	}
+
+	type torrent struct {
+		Announce string             `bencode:"announce"`
+		Info     bencode.RawMessage `bencode:"info"`
+		Files    []file             `bencode:"files"`
+		Private  bool               `bencode:"private,omitempty"`
+		Pieces   []byte             `bencode:"pieces"`
+		Extra    map[string]int     `bencode:"extra"`
+		Any      interface{}        `bencode:"any"`
+	}
+
+	source := torrent{
+		Announce: "http://tracker",
+		Info:     bencode.RawMessage("d4:name1:ae"),
+		Files:    []file{{Length: 10, Path: []string{"dir", "a"}}},
+		Private:  true,
+		Pieces:   []byte{1, 2, 3},
+		Extra:    map[string]int{"x": 1},
+		Any:      []interface{}{int64(1)},
+	}
+
+	encoded := encode(t, source)
+
+	var target torrent
+	require.NoError(t, decoder(encoded+"i1e").Decode(&target))
+	require.Equal(t, source, target)
+
+	var unknown struct {
+		Name string `bencode:"name"`
+	}
+
+	require.NoError(t, decoder("d5:extrali1ee4:name1:ae").Decode(&unknown))
+	require.Equal(t, "a", unknown.Name)
+
+	var small uint8
+	require.True(t, errors.Is(decoder("i-1e").Decode(&small), bencode.ErrOverflow))
+	require.True(t, errors.Is(decoder("i1e").Decode(small), binutils.ErrNilPointer))
+}
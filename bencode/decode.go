@@ -0,0 +1,506 @@
+package bencode
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/amarin/binutils"
+)
+
+// Limits restricts decoded data to protect decoder from malicious input.
+type Limits struct {
+	MaxDepth     int // maximum nesting level of lists and dictionaries
+	MaxStringLen int // maximum byte string length
+}
+
+// DefaultLimits used by decoders unless changed by SetLimits.
+var DefaultLimits = Limits{ // nolint:gochecknoglobals
+	MaxDepth:     64,
+	MaxStringLen: binutils.DefaultMaxFrameSize,
+}
+
+// Decoder reads bencoded values from binutils.BinaryReader.
+type Decoder struct {
+	reader    *binutils.BinaryReader
+	limits    Limits
+	depth     int
+	peeked    bool // pending byte read by peek but not consumed yet
+	pending   byte
+	capturing int    // active Raw calls
+	capture   []byte // consumed bytes while capturing
+}
+
+// NewDecoder creates Decoder reading from reader using DefaultLimits.
+func NewDecoder(reader *binutils.BinaryReader) *Decoder {
+	return &Decoder{reader: reader, limits: DefaultLimits}
+}
+
+// SetLimits sets decoder limits.
+func (d *Decoder) SetLimits(limits Limits) {
+	d.limits = limits
+}
+
+// Limits returns current decoder limits.
+func (d *Decoder) Limits() Limits {
+	return d.limits
+}
+
+// peek returns next byte without consuming it.
+func (d *Decoder) peek() (byte, error) {
+	if !d.peeked {
+		value, err := d.reader.ReadUint8()
+		if err != nil {
+			return 0, err
+		}
+
+		d.peeked, d.pending = true, value
+	}
+
+	return d.pending, nil
+}
+
+// consumed records consumed bytes if capturing.
+func (d *Decoder) consumed(data ...byte) {
+	if d.capturing > 0 {
+		d.capture = append(d.capture, data...)
+	}
+}
+
+// readByte consumes next byte.
+func (d *Decoder) readByte() (byte, error) {
+	value, err := d.peek()
+	if err != nil {
+		return 0, err
+	}
+
+	d.peeked = false
+	d.consumed(value)
+
+	return value, nil
+}
+
+// maxIntLen defines maximum integer text length, enough for any int64 value including sign.
+const maxIntLen = 20
+
+// readUntil consumes bytes up to and including stop byte. Returns bytes before stop.
+// Returns ErrOverflow if no stop byte found within maxIntLen bytes.
+func (d *Decoder) readUntil(stop byte) ([]byte, error) {
+	var (
+		buffer [maxIntLen]byte
+		data   = buffer[:0]
+	)
+
+	for {
+		next, err := d.readByte()
+
+		switch {
+		case err != nil:
+			return nil, err
+		case next == stop:
+			return data, nil
+		case len(data) == maxIntLen:
+			return nil, fmt.Errorf("%w: integer %q... exceeds %d bytes", ErrOverflow, data, maxIntLen)
+		}
+
+		data = append(data, next)
+	}
+}
+
+// readInt consumes integer digits terminated by stop byte, rejecting non-canonical forms.
+func (d *Decoder) readInt(stop byte) (int64, error) {
+	digits, err := d.readUntil(stop)
+	if err != nil {
+		return 0, err
+	}
+
+	unsigned := digits
+	if len(unsigned) > 0 && unsigned[0] == '-' {
+		unsigned = unsigned[1:]
+	}
+
+	switch {
+	case len(unsigned) == 0:
+		return 0, fmt.Errorf("%w: empty integer", ErrSyntax)
+	case unsigned[0] == '0' && len(digits) > 1:
+		return 0, fmt.Errorf("%w: integer %q has leading zero or negative zero", ErrSyntax, digits)
+	}
+
+	for _, digit := range unsigned {
+		if digit < '0' || digit > '9' {
+			return 0, fmt.Errorf("%w: invalid integer %q", ErrSyntax, digits)
+		}
+	}
+
+	value, err := strconv.ParseInt(string(digits), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%w: integer %q", ErrOverflow, digits)
+	}
+
+	return value, nil
+}
+
+// readString consumes byte string following its first length digit.
+func (d *Decoder) readString() ([]byte, error) {
+	length, err := d.readInt(markerLength)
+
+	switch {
+	case err != nil:
+		return nil, err
+	case length < 0:
+		return nil, fmt.Errorf("%w: negative string length", ErrSyntax)
+	case length > int64(d.limits.MaxStringLen):
+		return nil, fmt.Errorf("%w: string length %d", ErrLimit, length)
+	}
+
+	data := make([]byte, length)
+	if err = d.reader.ReadBytesInto(data); err != nil {
+		return nil, err
+	}
+
+	d.consumed(data...)
+
+	return data, nil
+}
+
+// expect consumes next byte checking it starts expected value type.
+func (d *Decoder) expect(marker byte, expected string) error {
+	value, err := d.peek()
+
+	switch {
+	case err != nil:
+		return err
+	case marker == markerLength && value >= '0' && value <= '9': // strings start with length
+		return nil
+	case value != marker:
+		return fmt.Errorf("%w: unexpected %q, expected %v", ErrSyntax, value, expected)
+	}
+
+	_, err = d.readByte()
+
+	return err
+}
+
+// atEnd checks if next byte ends list or dictionary and consumes it if so.
+func (d *Decoder) atEnd() (bool, error) {
+	value, err := d.peek()
+	if err != nil || value != markerEnd {
+		return false, err
+	}
+
+	_, err = d.readByte()
+
+	return true, err
+}
+
+// enter increments nesting depth checking limit.
+func (d *Decoder) enter() error {
+	if d.depth >= d.limits.MaxDepth {
+		return fmt.Errorf("%w: depth %d", ErrLimit, d.limits.MaxDepth)
+	}
+
+	d.depth++
+
+	return nil
+}
+
+// leave decrements nesting depth.
+func (d *Decoder) leave() {
+	d.depth--
+}
+
+// DecodeInt reads integer.
+func (d *Decoder) DecodeInt() (int64, error) {
+	if err := d.expect(markerInt, "integer"); err != nil {
+		return 0, err
+	}
+
+	return d.readInt(markerEnd)
+}
+
+// DecodeBytes reads byte string.
+func (d *Decoder) DecodeBytes() ([]byte, error) {
+	if err := d.expect(markerLength, "string"); err != nil {
+		return nil, err
+	}
+
+	return d.readString()
+}
+
+// DecodeString reads byte string as string.
+func (d *Decoder) DecodeString() (string, error) {
+	data, err := d.DecodeBytes()
+
+	return string(data), err
+}
+
+// DecodeListStart reads list start marker. Check list end using DecodeEnd before every item.
+func (d *Decoder) DecodeListStart() error {
+	return d.expect(markerList, "list")
+}
+
+// DecodeDictStart reads dictionary start marker. Check dictionary end using DecodeEnd before every key.
+func (d *Decoder) DecodeDictStart() error {
+	return d.expect(markerDict, "dictionary")
+}
+
+// DecodeEnd reads list or dictionary end marker.
+// Returns false without consuming anything if next item is not an end marker.
+func (d *Decoder) DecodeEnd() (bool, error) {
+	return d.atEnd()
+}
+
+// Raw reads next value and returns its original encoded bytes.
+func (d *Decoder) Raw() (RawMessage, error) {
+	start := len(d.capture)
+
+	d.capturing++
+	_, err := d.DecodeInterface()
+	d.capturing--
+
+	raw := append(RawMessage(nil), d.capture[start:]...)
+
+	if d.capturing == 0 {
+		d.capture = d.capture[:0]
+	}
+
+	return raw, err
+}
+
+// DecodeInterface reads any value. Integers are decoded as int64, byte strings as string,
+// lists as []interface{} and dictionaries as map[string]interface{}.
+func (d *Decoder) DecodeInterface() (interface{}, error) {
+	marker, err := d.peek()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case marker == markerInt:
+		return d.DecodeInt()
+	case marker >= '0' && marker <= '9':
+		return d.DecodeString()
+	case marker == markerList:
+		return d.decodeList()
+	case marker == markerDict:
+		return d.decodeDict()
+	default:
+		return nil, fmt.Errorf("%w: unexpected %q", ErrSyntax, marker)
+	}
+}
+
+// decodeList reads list items.
+func (d *Decoder) decodeList() (interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer d.leave()
+
+	if err := d.DecodeListStart(); err != nil {
+		return nil, err
+	}
+
+	result := make([]interface{}, 0)
+
+	for {
+		if done, err := d.atEnd(); err != nil || done {
+			return result, err
+		}
+
+		item, err := d.DecodeInterface()
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, item)
+	}
+}
+
+// decodeDict reads dictionary pairs.
+func (d *Decoder) decodeDict() (interface{}, error) {
+	result := make(map[string]interface{})
+
+	err := d.decodePairs(func(key string) error {
+		value, err := d.DecodeInterface()
+		result[key] = value
+
+		return err
+	})
+
+	return result, err
+}
+
+// decodePairs reads dictionary keys and calls valueFn to read every key value.
+func (d *Decoder) decodePairs(valueFn func(key string) error) error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+
+	if err := d.DecodeDictStart(); err != nil {
+		return err
+	}
+
+	for {
+		if done, err := d.atEnd(); err != nil || done {
+			return err
+		}
+
+		key, err := d.DecodeString()
+		if err != nil {
+			return fmt.Errorf("key: %w", err)
+		}
+
+		if err = valueFn(key); err != nil {
+			return err
+		}
+	}
+}
+
+// Decode reads value into target pointer.
+// Target could be any type supported by Encoder. Dictionary keys missing in target struct are skipped.
+func (d *Decoder) Decode(target interface{}) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return fmt.Errorf("%w: %T", binutils.ErrNilPointer, target)
+	}
+
+	return d.decodeValue(value.Elem())
+}
+
+// decodeValue reads value into target using reflection.
+func (d *Decoder) decodeValue(target reflect.Value) error { // nolint:gocyclo
+	if target.Type() == reflect.TypeOf(RawMessage{}) {
+		raw, err := d.Raw()
+		target.SetBytes(raw)
+
+		return err
+	}
+
+	switch target.Kind() {
+	case reflect.Ptr:
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+
+		return d.decodeValue(target.Elem())
+	case reflect.Interface:
+		value, err := d.DecodeInterface()
+		if err == nil {
+			target.Set(reflect.ValueOf(value))
+		}
+
+		return err
+	case reflect.Bool:
+		value, err := d.DecodeInt()
+		if err == nil && value != 0 && value != 1 {
+			return fmt.Errorf("%w: %d to bool", ErrOverflow, value)
+		}
+
+		target.SetBool(value == 1)
+
+		return err
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := d.DecodeInt()
+		if err == nil && target.OverflowInt(value) {
+			return fmt.Errorf("%w: %v", ErrOverflow, target.Type())
+		}
+
+		target.SetInt(value)
+
+		return err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		value, err := d.DecodeInt()
+		if err == nil && (value < 0 || target.OverflowUint(uint64(value))) {
+			return fmt.Errorf("%w: %v", ErrOverflow, target.Type())
+		}
+
+		target.SetUint(uint64(value))
+
+		return err
+	case reflect.String:
+		value, err := d.DecodeString()
+		target.SetString(value)
+
+		return err
+	case reflect.Slice:
+		return d.decodeSlice(target)
+	case reflect.Map:
+		if target.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("%w: map key %v", ErrUnsupportedType, target.Type().Key())
+		}
+
+		target.Set(reflect.MakeMap(target.Type()))
+
+		return d.decodePairs(func(key string) error {
+			value := reflect.New(target.Type().Elem()).Elem()
+			if err := d.decodeValue(value); err != nil {
+				return err
+			}
+
+			target.SetMapIndex(reflect.ValueOf(key).Convert(target.Type().Key()), value)
+
+			return nil
+		})
+	case reflect.Struct:
+		return d.decodeStruct(target)
+	default:
+		return fmt.Errorf("%w: %v", ErrUnsupportedType, target.Type())
+	}
+}
+
+// decodeSlice reads byte string or list into slice target.
+func (d *Decoder) decodeSlice(target reflect.Value) error {
+	if target.Type().Elem().Kind() == reflect.Uint8 {
+		value, err := d.DecodeBytes()
+		target.SetBytes(value)
+
+		return err
+	}
+
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+
+	if err := d.DecodeListStart(); err != nil {
+		return err
+	}
+
+	target.Set(reflect.MakeSlice(target.Type(), 0, 0))
+
+	for {
+		if done, err := d.atEnd(); err != nil || done {
+			return err
+		}
+
+		item := reflect.New(target.Type().Elem()).Elem()
+		if err := d.decodeValue(item); err != nil {
+			return err
+		}
+
+		target.Set(reflect.Append(target, item))
+	}
+}
+
+// decodeStruct reads dictionary into struct target.
+func (d *Decoder) decodeStruct(target reflect.Value) error {
+	fields := make(map[string]int)
+	for _, field := range structFields(target.Type()) {
+		fields[field.name] = field.index
+	}
+
+	return d.decodePairs(func(key string) error {
+		index, found := fields[key]
+		if !found {
+			_, err := d.DecodeInterface()
+			return err
+		}
+
+		if err := d.decodeValue(target.Field(index)); err != nil {
+			return fmt.Errorf("field %v: %w", key, err)
+		}
+
+		return nil
+	})
+}
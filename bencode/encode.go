@@ -0,0 +1,234 @@
+package bencode
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/amarin/binutils"
+)
+
+// Encoder writes bencoded values into binutils.BinaryWriter.
+type Encoder struct {
+	writer *binutils.BinaryWriter
+	buffer []byte // integer and length formatting buffer
+}
+
+// NewEncoder creates Encoder writing into writer.
+func NewEncoder(writer *binutils.BinaryWriter) *Encoder {
+	return &Encoder{writer: writer, buffer: make([]byte, 0, 24)}
+}
+
+// EncodeInt writes integer.
+func (e *Encoder) EncodeInt(value int64) error {
+	e.buffer = append(strconv.AppendInt(append(e.buffer[:0], markerInt), value, 10), markerEnd)
+
+	return e.writer.WriteBytes(e.buffer)
+}
+
+// EncodeUint writes unsigned integer.
+func (e *Encoder) EncodeUint(value uint64) error {
+	e.buffer = append(strconv.AppendUint(append(e.buffer[:0], markerInt), value, 10), markerEnd)
+
+	return e.writer.WriteBytes(e.buffer)
+}
+
+// EncodeBytes writes byte string.
+func (e *Encoder) EncodeBytes(value []byte) error {
+	e.buffer = append(strconv.AppendInt(e.buffer[:0], int64(len(value)), 10), markerLength)
+
+	if err := e.writer.WriteBytes(e.buffer); err != nil {
+		return err
+	}
+
+	return e.writer.WriteBytes(value)
+}
+
+// EncodeString writes string as byte string.
+func (e *Encoder) EncodeString(value string) error {
+	return e.EncodeBytes([]byte(value))
+}
+
+// EncodeListStart writes list start marker. Finish list items using EncodeEnd.
+func (e *Encoder) EncodeListStart() error {
+	return e.writer.WriteUint8(markerList)
+}
+
+// EncodeDictStart writes dictionary start marker. Finish key and value pairs using EncodeEnd.
+// Note keys order is caller responsibility when dictionary is encoded item by item.
+func (e *Encoder) EncodeDictStart() error {
+	return e.writer.WriteUint8(markerDict)
+}
+
+// EncodeEnd writes list or dictionary end marker.
+func (e *Encoder) EncodeEnd() error {
+	return e.writer.WriteUint8(markerEnd)
+}
+
+// Encode writes any supported value.
+// Supported values are integers, bool (as 0 or 1), strings, []byte, RawMessage
+// and arrays, slices, string keyed maps, structs or pointers of supported values.
+// Maps and structs are encoded as dictionaries with keys sorted.
+// Structs are keyed by field names, use `bencode:"name"` field tag to override name,
+// `bencode:"-"` to skip field or `bencode:"name,omitempty"` to skip field having zero value.
+func (e *Encoder) Encode(value interface{}) error {
+	switch typed := value.(type) {
+	case RawMessage:
+		return e.writer.WriteBytes(typed)
+	case string:
+		return e.EncodeString(typed)
+	case []byte:
+		return e.EncodeBytes(typed)
+	case int:
+		return e.EncodeInt(int64(typed))
+	case int64:
+		return e.EncodeInt(typed)
+	default:
+		return e.encodeValue(reflect.ValueOf(value))
+	}
+}
+
+// encodeValue writes value using reflection.
+func (e *Encoder) encodeValue(value reflect.Value) error {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return fmt.Errorf("%w: nil %v", ErrUnsupportedType, value.Type())
+		}
+
+		return e.Encode(value.Elem().Interface())
+	case reflect.Bool:
+		if value.Bool() {
+			return e.EncodeInt(1)
+		}
+
+		return e.EncodeInt(0)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return e.EncodeInt(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return e.EncodeUint(value.Uint())
+	case reflect.String:
+		return e.EncodeString(value.String())
+	case reflect.Slice, reflect.Array:
+		if value.Type() == reflect.TypeOf(RawMessage{}) {
+			return e.writer.WriteBytes(value.Bytes())
+		}
+
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			data := make([]byte, value.Len())
+			reflect.Copy(reflect.ValueOf(data), value)
+
+			return e.EncodeBytes(data)
+		}
+
+		if err := e.EncodeListStart(); err != nil {
+			return err
+		}
+
+		for idx := 0; idx < value.Len(); idx++ {
+			if err := e.encodeValue(value.Index(idx)); err != nil {
+				return err
+			}
+		}
+
+		return e.EncodeEnd()
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return fmt.Errorf("%w: map key %v", ErrUnsupportedType, value.Type().Key())
+		}
+
+		keys := value.MapKeys()
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+		if err := e.EncodeDictStart(); err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			if err := e.EncodeString(key.String()); err != nil {
+				return err
+			}
+
+			if err := e.encodeValue(value.MapIndex(key)); err != nil {
+				return err
+			}
+		}
+
+		return e.EncodeEnd()
+	case reflect.Struct:
+		return e.encodeStruct(value)
+	default:
+		return fmt.Errorf("%w: %v", ErrUnsupportedType, value.Type())
+	}
+}
+
+// encodeStruct writes struct as dictionary having fields sorted by names.
+func (e *Encoder) encodeStruct(value reflect.Value) error {
+	fields := structFields(value.Type())
+	sort.Slice(fields, func(i, j int) bool { return fields[i].name < fields[j].name })
+
+	if err := e.EncodeDictStart(); err != nil {
+		return err
+	}
+
+	for idx, field := range fields {
+		if idx > 0 && fields[idx-1].name == field.name {
+			return fmt.Errorf("%w: duplicate field name %v", ErrUnsupportedType, field.name)
+		}
+
+		fieldValue := value.Field(field.index)
+		if field.omitEmpty && fieldValue.IsZero() {
+			continue
+		}
+
+		if err := e.EncodeString(field.name); err != nil {
+			return err
+		}
+
+		if err := e.encodeValue(fieldValue); err != nil {
+			return fmt.Errorf("field %v: %w", field.name, err)
+		}
+	}
+
+	return e.EncodeEnd()
+}
+
+// structField describes encoded struct field.
+type structField struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+// structFields returns exported struct fields not skipped by `bencode:"-"` tag.
+// Tag value is a field name optionally followed by comma separated options,
+// omitempty option skips field having zero value, other options are ignored.
+func structFields(structType reflect.Type) (fields []structField) {
+	for idx := 0; idx < structType.NumField(); idx++ {
+		field := structType.Field(idx)
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+
+		options := strings.Split(field.Tag.Get("bencode"), ",")
+		name := options[0]
+
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+
+		result := structField{name: name, index: idx}
+		for _, option := range options[1:] {
+			result.omitEmpty = result.omitEmpty || option == "omitempty"
+		}
+
+		fields = append(fields, result)
+	}
+
+	return fields
+}
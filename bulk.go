@@ -0,0 +1,388 @@
+package binutils
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+	"unsafe"
+)
+
+// bulkChunkSize defines bytes count encoded at once by bulk slice reads and writes.
+// Value must be divisible by any supported element size.
+const bulkChunkSize = 4096
+
+// hostByteOrder holds bytes order of current platform.
+var hostByteOrder = func() binary.ByteOrder {
+	probe := uint16(1)
+	if *(*byte)(unsafe.Pointer(&probe)) == 1 {
+		return binary.LittleEndian
+	}
+
+	return binary.BigEndian
+}()
+
+// bytesView returns bytes slice sharing memory of size bytes starting at data.
+func bytesView(data unsafe.Pointer, size int) (view []byte) {
+	header := (*reflect.SliceHeader)(unsafe.Pointer(&view))
+	header.Data = uintptr(data)
+	header.Len = size
+	header.Cap = size
+
+	return view
+}
+
+// writeBulk writes count elements of specified size starting at data.
+// If configured byte order matches host byte order elements memory is written as is,
+// otherwise elements are encoded by put into chunks of bulkChunkSize bytes.
+func (w *BinaryWriter) writeBulk(data unsafe.Pointer, count int, size int, put func(dst []byte, idx int)) error {
+	if count == 0 {
+		return nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.order == hostByteOrder {
+		_, err := w.writeLocked(bytesView(data, count*size))
+
+		return err
+	}
+
+	chunk := AllocateBytes(bulkChunkSize)
+
+	for idx := 0; idx < count; {
+		chunkLen := 0
+		for ; idx < count && chunkLen < len(chunk); idx, chunkLen = idx+1, chunkLen+size {
+			put(chunk[chunkLen:], idx)
+		}
+
+		if _, err := w.writeLocked(chunk[:chunkLen]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readBulk fills count elements of specified size starting at data.
+// If configured byte order matches host byte order bytes are read directly into elements memory,
+// otherwise taken by chunks of bulkChunkSize bytes and decoded by get.
+func (r *BinaryReader) readBulk(data unsafe.Pointer, count int, size int, get func(src []byte, idx int)) error {
+	if count == 0 {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.order == hostByteOrder {
+		return r.readLocked(bytesView(data, count*size))
+	}
+
+	chunk := AllocateBytes(bulkChunkSize)
+
+	for idx := 0; idx < count; {
+		chunkLen := (count - idx) * size
+		if chunkLen > len(chunk) {
+			chunkLen = len(chunk)
+		}
+
+		if err := r.readLocked(chunk[:chunkLen]); err != nil {
+			return err
+		}
+
+		for offset := 0; offset < chunkLen; idx, offset = idx+1, offset+size {
+			get(chunk[offset:], idx)
+		}
+	}
+
+	return nil
+}
+
+// WriteUint16s writes uint16 values slice into writer using configured byte order.
+func (w *BinaryWriter) WriteUint16s(data []uint16) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	tracer := w.traceBegin()
+	err := w.writeBulk(unsafe.Pointer(&data[0]), len(data), Uint16size, func(dst []byte, idx int) {
+		w.order.PutUint16(dst, data[idx])
+	})
+
+	if tracer != nil {
+		tracer.end("WriteUint16s", append([]uint16(nil), data...), err)
+	}
+
+	return err
+}
+
+// WriteInt16s writes int16 values slice into writer using configured byte order.
+func (w *BinaryWriter) WriteInt16s(data []int16) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	tracer := w.traceBegin()
+	err := w.writeBulk(unsafe.Pointer(&data[0]), len(data), Int16size, func(dst []byte, idx int) {
+		w.order.PutUint16(dst, uint16(data[idx]))
+	})
+
+	if tracer != nil {
+		tracer.end("WriteInt16s", append([]int16(nil), data...), err)
+	}
+
+	return err
+}
+
+// WriteUint32s writes uint32 values slice into writer using configured byte order.
+func (w *BinaryWriter) WriteUint32s(data []uint32) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	tracer := w.traceBegin()
+	err := w.writeBulk(unsafe.Pointer(&data[0]), len(data), Uint32size, func(dst []byte, idx int) {
+		w.order.PutUint32(dst, data[idx])
+	})
+
+	if tracer != nil {
+		tracer.end("WriteUint32s", append([]uint32(nil), data...), err)
+	}
+
+	return err
+}
+
+// WriteInt32s writes int32 values slice into writer using configured byte order.
+func (w *BinaryWriter) WriteInt32s(data []int32) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	tracer := w.traceBegin()
+	err := w.writeBulk(unsafe.Pointer(&data[0]), len(data), Int32size, func(dst []byte, idx int) {
+		w.order.PutUint32(dst, uint32(data[idx]))
+	})
+
+	if tracer != nil {
+		tracer.end("WriteInt32s", append([]int32(nil), data...), err)
+	}
+
+	return err
+}
+
+// WriteUint64s writes uint64 values slice into writer using configured byte order.
+func (w *BinaryWriter) WriteUint64s(data []uint64) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	tracer := w.traceBegin()
+	err := w.writeBulk(unsafe.Pointer(&data[0]), len(data), Uint64size, func(dst []byte, idx int) {
+		w.order.PutUint64(dst, data[idx])
+	})
+
+	if tracer != nil {
+		tracer.end("WriteUint64s", append([]uint64(nil), data...), err)
+	}
+
+	return err
+}
+
+// WriteInt64s writes int64 values slice into writer using configured byte order.
+func (w *BinaryWriter) WriteInt64s(data []int64) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	tracer := w.traceBegin()
+	err := w.writeBulk(unsafe.Pointer(&data[0]), len(data), Int64size, func(dst []byte, idx int) {
+		w.order.PutUint64(dst, uint64(data[idx]))
+	})
+
+	if tracer != nil {
+		tracer.end("WriteInt64s", append([]int64(nil), data...), err)
+	}
+
+	return err
+}
+
+// WriteFloat32s writes float32 values slice into writer as IEEE 754 bits using configured byte order.
+func (w *BinaryWriter) WriteFloat32s(data []float32) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	tracer := w.traceBegin()
+	err := w.writeBulk(unsafe.Pointer(&data[0]), len(data), Uint32size, func(dst []byte, idx int) {
+		w.order.PutUint32(dst, math.Float32bits(data[idx]))
+	})
+
+	if tracer != nil {
+		tracer.end("WriteFloat32s", append([]float32(nil), data...), err)
+	}
+
+	return err
+}
+
+// WriteFloat64s writes float64 values slice into writer as IEEE 754 bits using configured byte order.
+func (w *BinaryWriter) WriteFloat64s(data []float64) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	tracer := w.traceBegin()
+	err := w.writeBulk(unsafe.Pointer(&data[0]), len(data), Uint64size, func(dst []byte, idx int) {
+		w.order.PutUint64(dst, math.Float64bits(data[idx]))
+	})
+
+	if tracer != nil {
+		tracer.end("WriteFloat64s", append([]float64(nil), data...), err)
+	}
+
+	return err
+}
+
+// ReadUint16s reads len(dst) uint16 values into dst using configured byte order.
+func (r *BinaryReader) ReadUint16s(dst []uint16) error {
+	if len(dst) == 0 {
+		return nil
+	}
+
+	tracer := r.traceBegin()
+	err := r.readBulk(unsafe.Pointer(&dst[0]), len(dst), Uint16size, func(src []byte, idx int) {
+		dst[idx] = r.order.Uint16(src)
+	})
+
+	if tracer != nil {
+		tracer.end("ReadUint16s", append([]uint16(nil), dst...), err)
+	}
+
+	return err
+}
+
+// ReadInt16s reads len(dst) int16 values into dst using configured byte order.
+func (r *BinaryReader) ReadInt16s(dst []int16) error {
+	if len(dst) == 0 {
+		return nil
+	}
+
+	tracer := r.traceBegin()
+	err := r.readBulk(unsafe.Pointer(&dst[0]), len(dst), Int16size, func(src []byte, idx int) {
+		dst[idx] = int16(r.order.Uint16(src))
+	})
+
+	if tracer != nil {
+		tracer.end("ReadInt16s", append([]int16(nil), dst...), err)
+	}
+
+	return err
+}
+
+// ReadUint32s reads len(dst) uint32 values into dst using configured byte order.
+func (r *BinaryReader) ReadUint32s(dst []uint32) error {
+	if len(dst) == 0 {
+		return nil
+	}
+
+	tracer := r.traceBegin()
+	err := r.readBulk(unsafe.Pointer(&dst[0]), len(dst), Uint32size, func(src []byte, idx int) {
+		dst[idx] = r.order.Uint32(src)
+	})
+
+	if tracer != nil {
+		tracer.end("ReadUint32s", append([]uint32(nil), dst...), err)
+	}
+
+	return err
+}
+
+// ReadInt32s reads len(dst) int32 values into dst using configured byte order.
+func (r *BinaryReader) ReadInt32s(dst []int32) error {
+	if len(dst) == 0 {
+		return nil
+	}
+
+	tracer := r.traceBegin()
+	err := r.readBulk(unsafe.Pointer(&dst[0]), len(dst), Int32size, func(src []byte, idx int) {
+		dst[idx] = int32(r.order.Uint32(src))
+	})
+
+	if tracer != nil {
+		tracer.end("ReadInt32s", append([]int32(nil), dst...), err)
+	}
+
+	return err
+}
+
+// ReadUint64s reads len(dst) uint64 values into dst using configured byte order.
+func (r *BinaryReader) ReadUint64s(dst []uint64) error {
+	if len(dst) == 0 {
+		return nil
+	}
+
+	tracer := r.traceBegin()
+	err := r.readBulk(unsafe.Pointer(&dst[0]), len(dst), Uint64size, func(src []byte, idx int) {
+		dst[idx] = r.order.Uint64(src)
+	})
+
+	if tracer != nil {
+		tracer.end("ReadUint64s", append([]uint64(nil), dst...), err)
+	}
+
+	return err
+}
+
+// ReadInt64s reads len(dst) int64 values into dst using configured byte order.
+func (r *BinaryReader) ReadInt64s(dst []int64) error {
+	if len(dst) == 0 {
+		return nil
+	}
+
+	tracer := r.traceBegin()
+	err := r.readBulk(unsafe.Pointer(&dst[0]), len(dst), Int64size, func(src []byte, idx int) {
+		dst[idx] = int64(r.order.Uint64(src))
+	})
+
+	if tracer != nil {
+		tracer.end("ReadInt64s", append([]int64(nil), dst...), err)
+	}
+
+	return err
+}
+
+// ReadFloat32s reads len(dst) float32 values into dst from IEEE 754 bits using configured byte order.
+func (r *BinaryReader) ReadFloat32s(dst []float32) error {
+	if len(dst) == 0 {
+		return nil
+	}
+
+	tracer := r.traceBegin()
+	err := r.readBulk(unsafe.Pointer(&dst[0]), len(dst), Uint32size, func(src []byte, idx int) {
+		dst[idx] = math.Float32frombits(r.order.Uint32(src))
+	})
+
+	if tracer != nil {
+		tracer.end("ReadFloat32s", append([]float32(nil), dst...), err)
+	}
+
+	return err
+}
+
+// ReadFloat64s reads len(dst) float64 values into dst from IEEE 754 bits using configured byte order.
+func (r *BinaryReader) ReadFloat64s(dst []float64) error {
+	if len(dst) == 0 {
+		return nil
+	}
+
+	tracer := r.traceBegin()
+	err := r.readBulk(unsafe.Pointer(&dst[0]), len(dst), Uint64size, func(src []byte, idx int) {
+		dst[idx] = math.Float64frombits(r.order.Uint64(src))
+	})
+
+	if tracer != nil {
+		tracer.end("ReadFloat64s", append([]float64(nil), dst...), err)
+	}
+
+	return err
+}
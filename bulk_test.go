@@ -0,0 +1,156 @@
+package binutils_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/amarin/binutils"
+)
+
+func TestBinaryWriter_WriteSlices(t *testing.T) {
+	values := []interface{}{
+		[]uint16{1, 2, math.MaxUint16},
+		[]int16{-1, 2, math.MinInt16},
+		[]uint32{1, 2, math.MaxUint32},
+		[]int32{-1, 2, math.MinInt32},
+		[]uint64{1, 2, math.MaxUint64},
+		[]int64{-1, 2, math.MinInt64},
+		[]float32{-1.5, 2, math.MaxFloat32},
+		[]float64{-1.5, 2, math.MaxFloat64},
+	}
+
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		for _, value := range values {
+			expected := new(bytes.Buffer)
+			require.NoError(t, binary.Write(expected, order, value))
+
+			buffer := new(bytes.Buffer)
+			writer := NewBinaryWriter(buffer)
+			writer.SetByteOrder(order)
+			require.Equal(t, order, writer.ByteOrder())
+
+			var err error
+			switch typed := value.(type) {
+			case []uint16:
+				err = writer.WriteUint16s(typed)
+			case []int16:
+				err = writer.WriteInt16s(typed)
+			case []uint32:
+				err = writer.WriteUint32s(typed)
+			case []int32:
+				err = writer.WriteInt32s(typed)
+			case []uint64:
+				err = writer.WriteUint64s(typed)
+			case []int64:
+				err = writer.WriteInt64s(typed)
+			case []float32:
+				err = writer.WriteFloat32s(typed)
+			case []float64:
+				err = writer.WriteFloat64s(typed)
+			}
+
+			require.NoError(t, err)
+			require.Equalf(t, expected.Bytes(), buffer.Bytes(), "%v %T", order, value)
+			require.Equal(t, expected.Len(), writer.BytesWritten())
+		}
+	}
+}
+
+func TestBinaryReader_ReadSlices(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		expected := make([]uint32, 3000) // more than single chunk
+		for idx := range expected {
+			expected[idx] = uint32(idx * 0x10001)
+		}
+
+		buffer := new(bytes.Buffer)
+		require.NoError(t, binary.Write(buffer, order, expected))
+		require.NoError(t, binary.Write(buffer, order, []int16{-1, 1}))
+		require.NoError(t, binary.Write(buffer, order, []float64{-1.5, math.Pi}))
+		size := buffer.Len()
+
+		reader := NewBinaryReader(buffer)
+		reader.SetByteOrder(order)
+		require.Equal(t, order, reader.ByteOrder())
+
+		uint32s := make([]uint32, len(expected))
+		require.NoError(t, reader.ReadUint32s(uint32s))
+		require.Equal(t, expected, uint32s)
+
+		int16s := make([]int16, 2)
+		require.NoError(t, reader.ReadInt16s(int16s))
+		require.Equal(t, []int16{-1, 1}, int16s)
+
+		float64s := make([]float64, 2)
+		require.NoError(t, reader.ReadFloat64s(float64s))
+		require.Equal(t, []float64{-1.5, math.Pi}, float64s)
+		require.Equal(t, size, reader.BytesTaken())
+
+		require.Error(t, reader.ReadUint64s(make([]uint64, 1)))
+		require.NoError(t, reader.ReadUint64s(nil))
+	}
+}
+
+func TestBinaryReader_SetByteOrder(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	writer := NewBinaryWriter(buffer)
+	writer.SetByteOrder(binary.LittleEndian)
+	require.NoError(t, writer.WriteUint16(0x0102))
+	require.NoError(t, writer.WriteInt32(-2))
+	require.NoError(t, writer.WriteUint64(0x0102030405060708))
+	require.Equal(t, []byte{2, 1, 0xfe, 0xff, 0xff, 0xff, 8, 7, 6, 5, 4, 3, 2, 1}, buffer.Bytes())
+
+	reader := NewBinaryReader(buffer)
+	reader.SetByteOrder(binary.LittleEndian)
+	uint16Value, err := reader.ReadUint16()
+	require.NoError(t, err)
+	require.Equal(t, uint16(0x0102), uint16Value)
+	int32Value, err := reader.ReadInt32()
+	require.NoError(t, err)
+	require.Equal(t, int32(-2), int32Value)
+	uint64Value, err := reader.ReadUint64()
+	require.NoError(t, err)
+	require.Equal(t, uint64(0x0102030405060708), uint64Value)
+}
+
+func benchmarkWriteUint32s(b *testing.B, order binary.ByteOrder) {
+	data := make([]uint32, 1<<20)
+	writer := NewBinaryWriter(ioutil.Discard)
+	writer.SetByteOrder(order)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data) * Uint32size))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := writer.WriteUint32s(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBinaryWriter_WriteUint32s_BigEndian(b *testing.B) {
+	benchmarkWriteUint32s(b, binary.BigEndian)
+}
+
+func BenchmarkBinaryWriter_WriteUint32s_LittleEndian(b *testing.B) {
+	benchmarkWriteUint32s(b, binary.LittleEndian)
+}
+
+func BenchmarkBinaryReader_ReadUint32s(b *testing.B) {
+	data := make([]uint32, 1<<20)
+	reader := NewBinaryReader(endlessSource{})
+	b.ReportAllocs()
+	b.SetBytes(int64(len(data) * Uint32size))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if err := reader.ReadUint32s(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,649 @@
+package binutils
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// BytesReader implements the same typed reads as BinaryReader directly over in-memory bytes slice.
+// Values are decoded by bounds-checked indexing without io.Reader calls, locking or per-field allocations.
+// Bytes returned by ReadBytes, ReadBytesCount and Bytes reference underlying slice, copy them if required.
+// Unlike BinaryReader BytesReader is not safe for concurrent use.
+type BytesReader struct {
+	data         []byte           // underlying data
+	offset       int              // next unread byte index in data
+	bytesTaken   int              // taken bytes counter
+	encoding     TextEncoding     // strings encoding, nil means strings bytes are taken as is
+	strictUTF8   bool             // return ErrInvalidUTF8 taking invalid UTF-8 sequences
+	strictFinish bool             // return ErrUnconsumed from Finish if bytes left
+	truncated    bool             // data ends before required amount, see Sub
+	order        binary.ByteOrder // multi-byte values bytes order
+	lastRuneSize int              // size of last rune taken by ReadUTF8Rune or ReadRune or -1 if unread is not allowed
+}
+
+// NewBinaryReaderBytes creates BytesReader taking values from data.
+// Reader uses big endian byte order by default, see SetByteOrder.
+func NewBinaryReaderBytes(data []byte) *BytesReader {
+	return &BytesReader{data: data, order: binary.BigEndian, lastRuneSize: -1}
+}
+
+// Bytes returns bytes left unread. Returned slice references underlying data.
+func (r *BytesReader) Bytes() []byte {
+	return r.data[r.offset:]
+}
+
+// Remaining returns bytes count left unread.
+func (r *BytesReader) Remaining() int {
+	return len(r.data) - r.offset
+}
+
+// BytesTaken returns taken bytes counter value.
+// Note counter can be reset to 0 using ResetBytesTaken.
+func (r *BytesReader) BytesTaken() int {
+	return r.bytesTaken
+}
+
+// ResetBytesTaken sets taken bytes counter to zero.
+func (r *BytesReader) ResetBytesTaken() {
+	r.bytesTaken = 0
+}
+
+// SetByteOrder sets bytes order used to decode multi-byte values.
+func (r *BytesReader) SetByteOrder(order binary.ByteOrder) {
+	r.order = order
+}
+
+// ByteOrder returns bytes order used to decode multi-byte values.
+func (r *BytesReader) ByteOrder() binary.ByteOrder {
+	return r.order
+}
+
+// SetEncoding sets text encoding used by ReadStringZ, nil means strings bytes are taken as is.
+func (r *BytesReader) SetEncoding(encoding TextEncoding) {
+	r.encoding = encoding
+}
+
+// Encoding returns text encoding used by ReadStringZ or nil if strings bytes are taken as is.
+func (r *BytesReader) Encoding() TextEncoding {
+	return r.encoding
+}
+
+// SetStrictUTF8 enables or disables strict UTF-8 mode, see BinaryReader.SetStrictUTF8.
+func (r *BytesReader) SetStrictUTF8(strict bool) {
+	r.strictUTF8 = strict
+}
+
+// StrictUTF8 returns true if strict UTF-8 mode enabled.
+func (r *BytesReader) StrictUTF8() bool {
+	return r.strictUTF8
+}
+
+// SetStrictFinish enables or disables strict finish mode.
+// In strict mode Finish returns ErrUnconsumed if reader has unread bytes left.
+func (r *BytesReader) SetStrictFinish(strict bool) {
+	r.strictFinish = strict
+}
+
+// StrictFinish returns true if strict finish mode enabled.
+func (r *BytesReader) StrictFinish() bool {
+	return r.strictFinish
+}
+
+// Close does nothing, there is no underlying resources to release.
+func (r *BytesReader) Close() error {
+	return nil
+}
+
+// eof returns error reported when data exhausted.
+func (r *BytesReader) eof() error {
+	if r.truncated {
+		return io.ErrUnexpectedEOF
+	}
+
+	return io.EOF
+}
+
+// take returns next amount bytes advancing reader.
+// Returns io.EOF if no bytes left or io.ErrUnexpectedEOF if less than amount bytes left, reader is not advanced then.
+func (r *BytesReader) take(amount int) ([]byte, error) {
+	r.lastRuneSize = -1
+
+	switch left := len(r.data) - r.offset; {
+	case amount > left && left == 0:
+		return nil, r.eof()
+	case amount > left:
+		return nil, io.ErrUnexpectedEOF
+	case amount < 0:
+		return nil, fmt.Errorf("%w: negative amount %d", ErrRead, amount)
+	}
+
+	taken := r.data[r.offset : r.offset+amount : r.offset+amount]
+	r.offset += amount
+	r.bytesTaken += amount
+
+	return taken, nil
+}
+
+// Read takes up to len(p) bytes into p. Implements io.Reader.
+func (r *BytesReader) Read(p []byte) (n int, err error) {
+	r.lastRuneSize = -1
+
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	if r.offset >= len(r.data) {
+		return 0, r.eof()
+	}
+
+	n = copy(p, r.data[r.offset:])
+	r.offset += n
+	r.bytesTaken += n
+
+	return n, nil
+}
+
+// ReadBytesCount takes exactly specified amount of bytes.
+// Returned slice references underlying data and has capacity limited to its length.
+func (r *BytesReader) ReadBytesCount(amount int) ([]byte, error) {
+	return r.take(amount)
+}
+
+// ReadBytesInto copies exactly len(dst) bytes into caller supplied dst buffer.
+func (r *BytesReader) ReadBytesInto(dst []byte) error {
+	taken, err := r.take(len(dst))
+	copy(dst, taken)
+
+	return err
+}
+
+// ReadUint8 takes uint8 value.
+func (r *BytesReader) ReadUint8() (uint8, error) {
+	taken, err := r.take(Uint8size)
+	if err != nil {
+		return 0, err
+	}
+
+	return taken[0], nil
+}
+
+// ReadUint16 takes uint16 value using configured byte order.
+func (r *BytesReader) ReadUint16() (uint16, error) {
+	taken, err := r.take(Uint16size)
+	if err != nil {
+		return 0, err
+	}
+
+	return r.order.Uint16(taken), nil
+}
+
+// ReadUint32 takes uint32 value using configured byte order.
+func (r *BytesReader) ReadUint32() (uint32, error) {
+	taken, err := r.take(Uint32size)
+	if err != nil {
+		return 0, err
+	}
+
+	return r.order.Uint32(taken), nil
+}
+
+// ReadUint64 takes uint64 value using configured byte order.
+func (r *BytesReader) ReadUint64() (uint64, error) {
+	taken, err := r.take(Uint64size)
+	if err != nil {
+		return 0, err
+	}
+
+	return r.order.Uint64(taken), nil
+}
+
+// ReadUint takes uint value encoded as uint64.
+func (r *BytesReader) ReadUint() (uint, error) {
+	uint64result, err := r.ReadUint64()
+	return uint(uint64result), err
+}
+
+// ReadInt8 takes int8 value.
+func (r *BytesReader) ReadInt8() (int8, error) {
+	uint8result, err := r.ReadUint8()
+	return int8(uint8result), err
+}
+
+// ReadInt16 takes int16 value using configured byte order.
+func (r *BytesReader) ReadInt16() (int16, error) {
+	uint16result, err := r.ReadUint16()
+	return int16(uint16result), err
+}
+
+// ReadInt32 takes int32 value using configured byte order.
+func (r *BytesReader) ReadInt32() (int32, error) {
+	uint32result, err := r.ReadUint32()
+	return int32(uint32result), err
+}
+
+// ReadInt64 takes int64 value using configured byte order.
+func (r *BytesReader) ReadInt64() (int64, error) {
+	uint64result, err := r.ReadUint64()
+	return int64(uint64result), err
+}
+
+// ReadInt takes int value encoded as int64.
+func (r *BytesReader) ReadInt() (int, error) {
+	int64result, err := r.ReadInt64()
+	return int(int64result), err
+}
+
+// ReadUvarint takes unsigned base 128 varint encoded value as produced by binary.PutUvarint.
+// Returns ErrVarintOverflow if value overflows 64-bit integer.
+func (r *BytesReader) ReadUvarint() (uint64, error) {
+	r.lastRuneSize = -1
+
+	value, size := binary.Uvarint(r.data[r.offset:])
+
+	switch {
+	case size == 0 && r.offset == len(r.data):
+		return 0, r.eof()
+	case size == 0:
+		return 0, io.ErrUnexpectedEOF
+	case size < 0:
+		return 0, ErrVarintOverflow
+	}
+
+	r.offset += size
+	r.bytesTaken += size
+
+	return value, nil
+}
+
+// ReadVarint takes signed zig-zag base 128 varint encoded value as produced by binary.PutVarint.
+// Returns ErrVarintOverflow if value overflows 64-bit integer.
+func (r *BytesReader) ReadVarint() (int64, error) {
+	unsigned, err := r.ReadUvarint()
+	signed := int64(unsigned >> 1)
+
+	if unsigned&1 != 0 {
+		signed = ^signed
+	}
+
+	return signed, err
+}
+
+// ReadUTF32Rune takes rune value as 4 bytes UTF-32 code unit.
+func (r *BytesReader) ReadUTF32Rune() (rune, error) {
+	uint32result, err := r.ReadUint32()
+	return rune(uint32result), err
+}
+
+// ReadUTF8Rune takes single UTF-8 encoded character.
+// Returns character, its size in bytes and any error encountered.
+// Invalid UTF-8 sequence is taken as utf8.RuneError of size 1
+// or returns ErrInvalidUTF8 error if strict UTF-8 mode enabled.
+func (r *BytesReader) ReadUTF8Rune() (char rune, size int, err error) {
+	r.lastRuneSize = -1
+
+	if r.offset >= len(r.data) {
+		return 0, 0, r.eof()
+	}
+
+	offset := r.bytesTaken
+	char, size = utf8.DecodeRune(r.data[r.offset:])
+	r.offset += size
+	r.bytesTaken += size
+
+	if char == utf8.RuneError && size == 1 && r.strictUTF8 {
+		return char, size, fmt.Errorf("%w: at offset %d", ErrInvalidUTF8, offset)
+	}
+
+	r.lastRuneSize = size
+
+	return char, size, nil
+}
+
+// ReadRune takes rune value as 4 bytes UTF-32 code unit, same as BinaryReader.ReadRune.
+//
+// Deprecated: use ReadUTF32Rune or ReadUTF8Rune to state rune encoding explicitly.
+func (r *BytesReader) ReadRune() (rune, int, error) {
+	char, err := r.ReadUTF32Rune()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	r.lastRuneSize = RuneSize
+
+	return char, RuneSize, nil
+}
+
+// UnreadRune returns last rune taken by ReadUTF8Rune or ReadRune back to reader decreasing bytes taken counter.
+// Only allowed immediately after successful ReadUTF8Rune or ReadRune call, returns ErrUnreadRune otherwise.
+// Implements io.RuneScanner.
+func (r *BytesReader) UnreadRune() error {
+	if r.lastRuneSize < 0 {
+		return ErrUnreadRune
+	}
+
+	r.offset -= r.lastRuneSize
+	r.bytesTaken -= r.lastRuneSize
+	r.lastRuneSize = -1
+
+	return nil
+}
+
+// ReadBytes takes bytes until the first occurrence of stop byte including it.
+// If stop byte is not found returns all bytes left and io.EOF.
+// Returned slice references underlying data and has capacity limited to its length.
+func (r *BytesReader) ReadBytes(stop byte) ([]byte, error) {
+	left := r.data[r.offset:]
+	if len(left) == 0 {
+		r.lastRuneSize = -1
+		return nil, r.eof()
+	}
+
+	if idx := bytes.IndexByte(left, stop); idx >= 0 {
+		return r.take(idx + 1)
+	}
+
+	taken, _ := r.take(len(left))
+
+	return taken, r.eof()
+}
+
+// takeUnitsZ takes code units of specified size until zero code unit taken.
+// Returns taken bytes excluding terminating zero code unit, reader is not advanced if terminator not found.
+func (r *BytesReader) takeUnitsZ(unitSize int) ([]byte, error) {
+	left := r.data[r.offset:]
+	end := -1
+
+	if unitSize == 1 {
+		end = bytes.IndexByte(left, 0)
+	} else {
+		for idx := 0; idx+unitSize <= len(left) && end < 0; idx += unitSize {
+			if isZeroUnit(left[idx : idx+unitSize]) {
+				end = idx
+			}
+		}
+	}
+
+	if end < 0 && len(left) == 0 {
+		r.lastRuneSize = -1
+		return nil, r.eof()
+	} else if end < 0 {
+		r.lastRuneSize = -1
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	taken, err := r.take(end + unitSize)
+	if err != nil {
+		return nil, err
+	}
+
+	return taken[:end], nil
+}
+
+// isZeroUnit returns true if all unit bytes are zero.
+func isZeroUnit(unit []byte) bool {
+	for _, unitByte := range unit {
+		if unitByte != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ReadStringZ takes zero-terminated string.
+// If text encoding set using SetEncoding bytes are decoded and terminated by zero code unit of that encoding.
+// Returns ErrInvalidUTF8 if strict UTF-8 mode enabled, no encoding set and taken bytes are not valid UTF-8.
+func (r *BytesReader) ReadStringZ() (string, error) {
+	offset := r.bytesTaken
+	unitSize := 1
+
+	if r.encoding != nil {
+		unitSize = r.encoding.UnitSize()
+	}
+
+	taken, err := r.takeUnitsZ(unitSize)
+	if err != nil {
+		return "", fmt.Errorf("%w: read: %v", ErrRequired0T, err)
+	}
+
+	if r.encoding == nil {
+		if r.strictUTF8 {
+			if err = checkUTF8(taken, offset); err != nil {
+				return "", err
+			}
+		}
+
+		return string(taken), nil
+	}
+
+	line, err := r.encoding.Decode(taken)
+	if err != nil {
+		return "", fmt.Errorf("%v: %v: %w", ErrRead, ErrDecodeTo, err)
+	}
+
+	return line, nil
+}
+
+// ReadStringZInto takes zero-terminated string appending its bytes excluding terminator to dst.
+// Returns extended buffer. Taking strings without text encoding set does not allocate
+// if dst has enough capacity, otherwise decoded string bytes are appended.
+func (r *BytesReader) ReadStringZInto(dst []byte) ([]byte, error) {
+	if r.encoding != nil {
+		line, err := r.ReadStringZ()
+		return append(dst, line...), err
+	}
+
+	offset := r.bytesTaken
+
+	taken, err := r.takeUnitsZ(1)
+	if err != nil {
+		return dst, fmt.Errorf("%w: read: %v", ErrRequired0T, err)
+	}
+
+	if r.strictUTF8 {
+		if err = checkUTF8(taken, offset); err != nil {
+			return dst, err
+		}
+	}
+
+	return append(dst, taken...), nil
+}
+
+// ReadHex takes exactly specified amount of bytes and return hex representation string for received bytes.
+func (r *BytesReader) ReadHex(amount int) (string, error) {
+	taken, err := r.take(amount)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(taken), nil
+}
+
+// ReadObject reads object data the same way BinaryReader.ReadObject does.
+// BinaryDecoder targets decode from r directly, others are decoded by BinaryReader adapter taking bytes from r.
+func (r *BytesReader) ReadObject(target interface{}) error {
+	if decoder, ok := target.(BinaryDecoder); ok {
+		return decoder.DecodeBinary(r)
+	}
+
+	stream := AsBinaryReader(r)
+	stream.strictUTF8 = r.strictUTF8
+
+	err := stream.ReadObject(target)
+
+	// return bytes buffered by stream reader back, they are still unread
+	r.offset -= len(stream.unread)
+	r.bytesTaken -= len(stream.unread)
+
+	return err
+}
+
+// Sub returns BytesReader over next amount bytes and advances r past them immediately.
+// Sub reader inherits byte order, text encoding and strict modes of r.
+// If less than amount bytes left sub reader takes all of them and reports io.ErrUnexpectedEOF at the end.
+func (r *BytesReader) Sub(amount int) *BytesReader {
+	left := len(r.data) - r.offset
+	truncated := amount > left || r.truncated && amount == left
+
+	switch {
+	case amount > left:
+		amount = left
+	case amount < 0:
+		amount = 0
+	}
+
+	sub := NewBinaryReaderBytes(r.data[r.offset : r.offset+amount : r.offset+amount])
+	sub.order, sub.encoding = r.order, r.encoding
+	sub.strictUTF8, sub.strictFinish, sub.truncated = r.strictUTF8, r.strictFinish, truncated
+
+	r.lastRuneSize = -1
+	r.offset += amount
+	r.bytesTaken += amount
+
+	return sub
+}
+
+// Drain skips all bytes left. Returns skipped bytes count and io.ErrUnexpectedEOF if data is truncated.
+func (r *BytesReader) Drain() (skipped int, err error) {
+	skipped = len(r.data) - r.offset
+	r.offset = len(r.data)
+	r.bytesTaken += skipped
+	r.lastRuneSize = -1
+
+	if r.truncated {
+		return skipped, io.ErrUnexpectedEOF
+	}
+
+	return skipped, nil
+}
+
+// Finish skips bytes left unread.
+// Returns ErrUnconsumed if strict finish mode enabled and any bytes skipped.
+func (r *BytesReader) Finish() error {
+	skipped, err := r.Drain()
+
+	switch {
+	case err != nil:
+		return err
+	case skipped > 0 && r.strictFinish:
+		return fmt.Errorf("%w: %d bytes left", ErrUnconsumed, skipped)
+	default:
+		return nil
+	}
+}
+
+// takeBulk fills count elements of specified size starting at data.
+// If configured byte order matches host byte order bytes are copied directly into elements memory,
+// otherwise decoded by get.
+func (r *BytesReader) takeBulk(data unsafe.Pointer, count int, size int, get func(src []byte, idx int)) error {
+	taken, err := r.take(count * size)
+	if err != nil {
+		return err
+	}
+
+	if r.order == hostByteOrder {
+		copy(bytesView(data, count*size), taken)
+
+		return nil
+	}
+
+	for idx, offset := 0, 0; idx < count; idx, offset = idx+1, offset+size {
+		get(taken[offset:], idx)
+	}
+
+	return nil
+}
+
+// ReadUint16s takes len(dst) uint16 values into dst using configured byte order.
+func (r *BytesReader) ReadUint16s(dst []uint16) error {
+	if len(dst) == 0 {
+		return nil
+	}
+
+	return r.takeBulk(unsafe.Pointer(&dst[0]), len(dst), Uint16size, func(src []byte, idx int) {
+		dst[idx] = r.order.Uint16(src)
+	})
+}
+
+// ReadInt16s takes len(dst) int16 values into dst using configured byte order.
+func (r *BytesReader) ReadInt16s(dst []int16) error {
+	if len(dst) == 0 {
+		return nil
+	}
+
+	return r.takeBulk(unsafe.Pointer(&dst[0]), len(dst), Int16size, func(src []byte, idx int) {
+		dst[idx] = int16(r.order.Uint16(src))
+	})
+}
+
+// ReadUint32s takes len(dst) uint32 values into dst using configured byte order.
+func (r *BytesReader) ReadUint32s(dst []uint32) error {
+	if len(dst) == 0 {
+		return nil
+	}
+
+	return r.takeBulk(unsafe.Pointer(&dst[0]), len(dst), Uint32size, func(src []byte, idx int) {
+		dst[idx] = r.order.Uint32(src)
+	})
+}
+
+// ReadInt32s takes len(dst) int32 values into dst using configured byte order.
+func (r *BytesReader) ReadInt32s(dst []int32) error {
+	if len(dst) == 0 {
+		return nil
+	}
+
+	return r.takeBulk(unsafe.Pointer(&dst[0]), len(dst), Int32size, func(src []byte, idx int) {
+		dst[idx] = int32(r.order.Uint32(src))
+	})
+}
+
+// ReadUint64s takes len(dst) uint64 values into dst using configured byte order.
+func (r *BytesReader) ReadUint64s(dst []uint64) error {
+	if len(dst) == 0 {
+		return nil
+	}
+
+	return r.takeBulk(unsafe.Pointer(&dst[0]), len(dst), Uint64size, func(src []byte, idx int) {
+		dst[idx] = r.order.Uint64(src)
+	})
+}
+
+// ReadInt64s takes len(dst) int64 values into dst using configured byte order.
+func (r *BytesReader) ReadInt64s(dst []int64) error {
+	if len(dst) == 0 {
+		return nil
+	}
+
+	return r.takeBulk(unsafe.Pointer(&dst[0]), len(dst), Int64size, func(src []byte, idx int) {
+		dst[idx] = int64(r.order.Uint64(src))
+	})
+}
+
+// ReadFloat32s takes len(dst) float32 values into dst from IEEE 754 bits using configured byte order.
+func (r *BytesReader) ReadFloat32s(dst []float32) error {
+	if len(dst) == 0 {
+		return nil
+	}
+
+	return r.takeBulk(unsafe.Pointer(&dst[0]), len(dst), Uint32size, func(src []byte, idx int) {
+		dst[idx] = math.Float32frombits(r.order.Uint32(src))
+	})
+}
+
+// ReadFloat64s takes len(dst) float64 values into dst from IEEE 754 bits using configured byte order.
+func (r *BytesReader) ReadFloat64s(dst []float64) error {
+	if len(dst) == 0 {
+		return nil
+	}
+
+	return r.takeBulk(unsafe.Pointer(&dst[0]), len(dst), Uint64size, func(src []byte, idx int) {
+		dst[idx] = math.Float64frombits(r.order.Uint64(src))
+	})
+}
@@ -0,0 +1,306 @@
+package binutils_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/amarin/binutils"
+)
+
+// writeSampleRecord returns typed fields sequence written by BinaryWriter using order and encoding.
+func writeSampleRecord(t *testing.T, order binary.ByteOrder, encoding TextEncoding) []byte {
+	buffer := new(bytes.Buffer)
+	writer := NewBinaryWriter(buffer)
+	writer.SetByteOrder(order)
+	writer.SetEncoding(encoding)
+
+	require.NoError(t, writer.WriteUint8(0xfe))
+	require.NoError(t, writer.WriteInt16(math.MinInt16))
+	require.NoError(t, writer.WriteUint32(0xdeadbeef))
+	require.NoError(t, writer.WriteInt64(-2))
+	require.NoError(t, writer.WriteUvarint(300))
+	require.NoError(t, writer.WriteVarint(-300))
+	require.NoError(t, writer.WriteUTF8Rune('ж'))
+	require.NoError(t, writer.WriteRune('€'))
+	require.NoError(t, writer.WriteStringZ("строка"))
+	require.NoError(t, writer.WriteFloat64s([]float64{1.5, -2}))
+	require.NoError(t, writer.WriteHex("cafe"))
+
+	return buffer.Bytes()
+}
+
+func TestBytesReader_TypedReads(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		for _, encoding := range []TextEncoding{nil, UTF16LE, Windows1251} {
+			data := writeSampleRecord(t, order, encoding)
+			reader := NewBinaryReaderBytes(data)
+			reader.SetByteOrder(order)
+			reader.SetEncoding(encoding)
+
+			u8, err := reader.ReadUint8()
+			require.NoError(t, err)
+			require.Equal(t, uint8(0xfe), u8)
+
+			i16, err := reader.ReadInt16()
+			require.NoError(t, err)
+			require.Equal(t, int16(math.MinInt16), i16)
+
+			u32, err := reader.ReadUint32()
+			require.NoError(t, err)
+			require.Equal(t, uint32(0xdeadbeef), u32)
+
+			i64, err := reader.ReadInt64()
+			require.NoError(t, err)
+			require.Equal(t, int64(-2), i64)
+
+			uv, err := reader.ReadUvarint()
+			require.NoError(t, err)
+			require.Equal(t, uint64(300), uv)
+
+			sv, err := reader.ReadVarint()
+			require.NoError(t, err)
+			require.Equal(t, int64(-300), sv)
+
+			char, size, err := reader.ReadUTF8Rune()
+			require.NoError(t, err)
+			require.Equal(t, 'ж', char)
+			require.Equal(t, 2, size)
+
+			char, err = reader.ReadUTF32Rune()
+			require.NoError(t, err)
+			require.Equal(t, '€', char)
+
+			line, err := reader.ReadStringZ()
+			require.NoError(t, err)
+			require.Equal(t, "строка", line)
+
+			floats := make([]float64, 2)
+			require.NoError(t, reader.ReadFloat64s(floats))
+			require.Equal(t, []float64{1.5, -2}, floats)
+
+			hexString, err := reader.ReadHex(2)
+			require.NoError(t, err)
+			require.Equal(t, "cafe", hexString)
+
+			require.Equal(t, len(data), reader.BytesTaken())
+			require.Zero(t, reader.Remaining())
+			require.Empty(t, reader.Bytes())
+
+			_, err = reader.ReadUint8()
+			require.Equal(t, io.EOF, err)
+		}
+	}
+}
+
+func TestBytesReader_Bounds(t *testing.T) {
+	reader := NewBinaryReaderBytes([]byte{1, 2, 3})
+
+	_, err := reader.ReadUint32()
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+	require.Equal(t, 3, reader.Remaining(), "failed read must not advance reader")
+
+	value, err := reader.ReadUint16()
+	require.NoError(t, err)
+	require.Equal(t, uint16(0x0102), value)
+	require.Equal(t, []byte{3}, reader.Bytes())
+
+	_, err = reader.ReadBytesCount(-1)
+	require.True(t, errors.Is(err, ErrRead))
+
+	_, err = reader.ReadUvarint()
+	require.NoError(t, err)
+
+	_, err = NewBinaryReaderBytes([]byte{0x80}).ReadUvarint()
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+
+	_, err = NewBinaryReaderBytes(bytes.Repeat([]byte{0xff}, 11)).ReadUvarint()
+	require.Equal(t, ErrVarintOverflow, err)
+
+	_, err = NewBinaryReaderBytes([]byte("unterminated")).ReadStringZ()
+	require.True(t, errors.Is(err, ErrRequired0T))
+}
+
+func TestBytesReader_ReadBytes(t *testing.T) {
+	data := []byte("first\nsecond")
+	reader := NewBinaryReaderBytes(data)
+
+	line, err := reader.ReadBytes('\n')
+	require.NoError(t, err)
+	require.Equal(t, "first\n", string(line))
+	require.Equal(t, len(line), cap(line), "appending to taken bytes must not overwrite data")
+
+	line, err = reader.ReadBytes('\n')
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, "second", string(line))
+
+	taken, err := NewBinaryReaderBytes(data).ReadBytesCount(5)
+	require.NoError(t, err)
+	require.True(t, &data[0] == &taken[0], "ReadBytesCount must reference underlying data")
+}
+
+func TestBytesReader_UnreadRune(t *testing.T) {
+	reader := NewBinaryReaderBytes([]byte("жx\xff"))
+	scanner := AsRuneScanner(reader)
+	require.Equal(t, ErrUnreadRune, scanner.UnreadRune())
+
+	char, _, err := scanner.ReadRune()
+	require.NoError(t, err)
+	require.Equal(t, 'ж', char)
+	require.NoError(t, scanner.UnreadRune())
+	require.Equal(t, 0, reader.BytesTaken())
+	require.Equal(t, ErrUnreadRune, scanner.UnreadRune())
+
+	line, err := reader.ReadBytesCount(3)
+	require.NoError(t, err)
+	require.Equal(t, "жx", string(line))
+
+	reader.SetStrictUTF8(true)
+	_, _, err = scanner.ReadRune()
+	require.True(t, errors.Is(err, ErrInvalidUTF8))
+	require.Contains(t, err.Error(), "at offset 3")
+}
+
+func TestBytesReader_Sub(t *testing.T) {
+	reader := NewBinaryReaderBytes([]byte{0, 1, 0, 2, 0xff, 9})
+	reader.SetStrictFinish(true)
+
+	sub := reader.Sub(4)
+	require.Equal(t, 4, sub.Remaining())
+	require.Equal(t, 2, reader.Remaining())
+
+	value, err := sub.ReadUint16()
+	require.NoError(t, err)
+	require.Equal(t, uint16(1), value)
+	require.True(t, errors.Is(sub.Finish(), ErrUnconsumed))
+
+	_, err = sub.ReadUint8()
+	require.Equal(t, io.EOF, err)
+
+	truncated := reader.Sub(4)
+	require.Equal(t, 2, truncated.Remaining())
+
+	_, err = truncated.ReadUint16()
+	require.NoError(t, err)
+
+	_, err = truncated.ReadUint8()
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+	require.Equal(t, 6, reader.BytesTaken())
+}
+
+type bytesReaderObject struct {
+	First uint16
+	Lead  rune
+}
+
+func (o *bytesReaderObject) BinaryReadFrom(reader *BinaryReader) (err error) {
+	if o.First, err = reader.ReadUint16(); err != nil {
+		return err
+	}
+
+	o.Lead, _, err = reader.ReadUTF8Rune() // invalid sequence leaves bytes buffered by stream reader
+
+	return err
+}
+
+func TestBytesReader_ReadObject(t *testing.T) {
+	reader := NewBinaryReaderBytes([]byte{0, 0, 0, 1, 0, 7, 0xe0, 'o', 'k', 0, 0xab})
+
+	var value uint32
+	require.NoError(t, reader.ReadObject(&value))
+	require.Equal(t, uint32(1), value)
+
+	object := new(bytesReaderObject)
+	require.NoError(t, reader.ReadObject(object))
+	require.Equal(t, bytesReaderObject{First: 7, Lead: utf8.RuneError}, *object)
+	require.Equal(t, 7, reader.BytesTaken())
+
+	line, err := reader.ReadStringZ()
+	require.NoError(t, err)
+	require.Equal(t, "ok", line)
+
+	last, err := reader.ReadUint8()
+	require.NoError(t, err)
+	require.Equal(t, uint8(0xab), last)
+	require.Equal(t, 11, reader.BytesTaken())
+}
+
+func TestBytesReader_ReadSlices(t *testing.T) {
+	expected := []int32{-1, 2, math.MinInt32, math.MaxInt32}
+
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		buffer := new(bytes.Buffer)
+		require.NoError(t, binary.Write(buffer, order, expected))
+
+		reader := NewBinaryReaderBytes(buffer.Bytes())
+		reader.SetByteOrder(order)
+
+		values := make([]int32, len(expected))
+		require.NoError(t, reader.ReadInt32s(values))
+		require.Equal(t, expected, values)
+		require.Equal(t, io.EOF, reader.ReadInt32s(values))
+	}
+}
+
+func TestBytesReader_ZeroAllocations(t *testing.T) {
+	data := bytes.Repeat([]byte("binutils\x00"), 1024)
+	reader := NewBinaryReaderBytes(data)
+	buffer := make([]byte, 0, 32)
+	values := make([]uint32, 4)
+
+	for name, read := range map[string]func(){
+		"ReadUint8":       func() { _, _ = reader.ReadUint8() },
+		"ReadUint16":      func() { _, _ = reader.ReadUint16() },
+		"ReadUint32":      func() { _, _ = reader.ReadUint32() },
+		"ReadUint64":      func() { _, _ = reader.ReadUint64() },
+		"ReadUvarint":     func() { _, _ = reader.ReadUvarint() },
+		"ReadUTF8Rune":    func() { _, _, _ = reader.ReadUTF8Rune() },
+		"ReadBytesCount":  func() { _, _ = reader.ReadBytesCount(8) },
+		"ReadStringZInto": func() { _, _ = reader.ReadStringZInto(buffer[:0]) },
+		"ReadUint32s":     func() { _ = reader.ReadUint32s(values) },
+	} {
+		read := read // pin read
+		t.Run(name, func(t *testing.T) {
+			require.Zero(t, testing.AllocsPerRun(100, read))
+		})
+	}
+}
+
+func BenchmarkBytesReader_ReadUint32(b *testing.B) {
+	data := make([]byte, 4096)
+	reader := NewBinaryReaderBytes(data)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if reader.Remaining() == 0 {
+			reader = NewBinaryReaderBytes(data)
+		}
+
+		if _, err := reader.ReadUint32(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBytesReader_StreamReadUint32(b *testing.B) {
+	data := make([]byte, 4096)
+	source := bytes.NewReader(data)
+	reader := NewBinaryReader(source)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if source.Len() == 0 {
+			source.Reset(data)
+		}
+
+		if _, err := reader.ReadUint32(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,372 @@
+package binutils
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"unicode/utf8"
+	"unsafe"
+)
+
+// BufferWriter implements the same typed writes as BinaryWriter appending values directly to bytes slice.
+// Values are encoded without io.Writer calls, locking or per-field allocations while buffer capacity suffices.
+// Unlike BinaryWriter BufferWriter is not safe for concurrent use.
+type BufferWriter struct {
+	buffer       []byte           // written data
+	bytesWritten int              // written bytes counter
+	encoding     TextEncoding     // strings encoding, nil means strings bytes are written as is
+	order        binary.ByteOrder // multi-byte values bytes order
+}
+
+// NewBinaryWriterBuffer creates BufferWriter appending values to buffer.
+// Pass buffer[:0] to reuse allocated memory or nil to allocate on demand.
+// Writer uses big endian byte order by default, see SetByteOrder.
+func NewBinaryWriterBuffer(buffer []byte) *BufferWriter {
+	return &BufferWriter{buffer: buffer, order: binary.BigEndian}
+}
+
+// Bytes returns written data. Returned slice references writer buffer until next write.
+func (w *BufferWriter) Bytes() []byte {
+	return w.buffer
+}
+
+// Len returns written data length.
+func (w *BufferWriter) Len() int {
+	return len(w.buffer)
+}
+
+// Remaining returns bytes count could be written without buffer reallocation.
+func (w *BufferWriter) Remaining() int {
+	return cap(w.buffer) - len(w.buffer)
+}
+
+// Reset truncates written data keeping allocated buffer and resets written bytes counter.
+func (w *BufferWriter) Reset() {
+	w.buffer = w.buffer[:0]
+	w.bytesWritten = 0
+}
+
+// BytesWritten returns written bytes counter value.
+// Note counter can be reset to 0 using ResetBytesWritten.
+func (w *BufferWriter) BytesWritten() int {
+	return w.bytesWritten
+}
+
+// ResetBytesWritten sets written bytes counter to zero. Written data is kept, use Reset to drop it.
+func (w *BufferWriter) ResetBytesWritten() {
+	w.bytesWritten = 0
+}
+
+// SetByteOrder sets bytes order used to encode multi-byte values.
+func (w *BufferWriter) SetByteOrder(order binary.ByteOrder) {
+	w.order = order
+}
+
+// ByteOrder returns bytes order used to encode multi-byte values.
+func (w *BufferWriter) ByteOrder() binary.ByteOrder {
+	return w.order
+}
+
+// SetEncoding sets text encoding used by WriteStringZ, nil means strings bytes are written as is.
+func (w *BufferWriter) SetEncoding(encoding TextEncoding) {
+	w.encoding = encoding
+}
+
+// Encoding returns text encoding used by WriteStringZ or nil if strings bytes are written as is.
+func (w *BufferWriter) Encoding() TextEncoding {
+	return w.encoding
+}
+
+// Close does nothing, there is no underlying resources to release.
+func (w *BufferWriter) Close() error {
+	return nil
+}
+
+// Grow ensures at least size bytes could be written without buffer reallocation.
+func (w *BufferWriter) Grow(size int) {
+	if cap(w.buffer)-len(w.buffer) < size {
+		extended := make([]byte, len(w.buffer), len(w.buffer)+size)
+		copy(extended, w.buffer)
+		w.buffer = extended
+	}
+}
+
+// grow extends buffer by size bytes returning extension to fill.
+func (w *BufferWriter) grow(size int) []byte {
+	length := len(w.buffer)
+
+	if cap(w.buffer)-length < size {
+		w.Grow(cap(w.buffer) + size)
+	}
+
+	w.buffer = w.buffer[:length+size]
+	w.bytesWritten += size
+
+	return w.buffer[length:]
+}
+
+// Write appends p to buffer. Implements io.Writer, never returns error.
+func (w *BufferWriter) Write(p []byte) (int, error) {
+	copy(w.grow(len(p)), p)
+
+	return len(p), nil
+}
+
+// WriteUint8 appends uint8 value.
+func (w *BufferWriter) WriteUint8(data uint8) error {
+	w.grow(Uint8size)[0] = data
+
+	return nil
+}
+
+// WriteUint16 appends uint16 value using configured byte order.
+func (w *BufferWriter) WriteUint16(data uint16) error {
+	w.order.PutUint16(w.grow(Uint16size), data)
+
+	return nil
+}
+
+// WriteUint32 appends uint32 value using configured byte order.
+func (w *BufferWriter) WriteUint32(data uint32) error {
+	w.order.PutUint32(w.grow(Uint32size), data)
+
+	return nil
+}
+
+// WriteUint64 appends uint64 value using configured byte order.
+func (w *BufferWriter) WriteUint64(data uint64) error {
+	w.order.PutUint64(w.grow(Uint64size), data)
+
+	return nil
+}
+
+// WriteUint appends uint value as uint64.
+func (w *BufferWriter) WriteUint(data uint) error {
+	return w.WriteUint64(uint64(data))
+}
+
+// WriteInt8 appends int8 value.
+func (w *BufferWriter) WriteInt8(data int8) error {
+	return w.WriteUint8(uint8(data))
+}
+
+// WriteInt16 appends int16 value using configured byte order.
+func (w *BufferWriter) WriteInt16(data int16) error {
+	return w.WriteUint16(uint16(data))
+}
+
+// WriteInt32 appends int32 value using configured byte order.
+func (w *BufferWriter) WriteInt32(data int32) error {
+	return w.WriteUint32(uint32(data))
+}
+
+// WriteInt64 appends int64 value using configured byte order.
+func (w *BufferWriter) WriteInt64(data int64) error {
+	return w.WriteUint64(uint64(data))
+}
+
+// WriteInt appends int value as int64.
+func (w *BufferWriter) WriteInt(data int) error {
+	return w.WriteUint64(uint64(data))
+}
+
+// WriteRune appends rune value as uint32, same as BinaryWriter.WriteRune.
+//
+// Deprecated: use WriteUTF32Rune or WriteUTF8Rune to state rune encoding explicitly.
+func (w *BufferWriter) WriteRune(char rune) error {
+	return w.WriteUint32(uint32(char))
+}
+
+// WriteUTF32Rune appends rune value as 4 bytes UTF-32 code unit. Same as WriteRune.
+func (w *BufferWriter) WriteUTF32Rune(char rune) error {
+	return w.WriteUint32(uint32(char))
+}
+
+// WriteUTF8Rune appends rune value as UTF-8 encoded character.
+func (w *BufferWriter) WriteUTF8Rune(char rune) error {
+	encoded := w.grow(utf8.UTFMax)
+	size := utf8.EncodeRune(encoded, char)
+	w.buffer = w.buffer[:len(w.buffer)-utf8.UTFMax+size]
+	w.bytesWritten -= utf8.UTFMax - size
+
+	return nil
+}
+
+// WriteUvarint appends uint64 value as unsigned base 128 varint like binary.PutUvarint does.
+func (w *BufferWriter) WriteUvarint(data uint64) error {
+	var scratch [binary.MaxVarintLen64]byte
+
+	_, err := w.Write(scratch[:binary.PutUvarint(scratch[:], data)])
+
+	return err
+}
+
+// WriteVarint appends int64 value as signed zig-zag base 128 varint like binary.PutVarint does.
+func (w *BufferWriter) WriteVarint(data int64) error {
+	var scratch [binary.MaxVarintLen64]byte
+
+	_, err := w.Write(scratch[:binary.PutVarint(scratch[:], data)])
+
+	return err
+}
+
+// WriteStringZ appends string bytes as Zero-terminated string.
+// If text encoding set using SetEncoding string is encoded and terminated by zero code unit of that encoding.
+func (w *BufferWriter) WriteStringZ(data string) error {
+	if w.encoding == nil {
+		extension := w.grow(len(data) + 1)
+		extension[copy(extension, data)] = 0
+
+		return nil
+	}
+
+	encoded, err := w.encoding.Encode(data)
+	if err != nil {
+		return fmt.Errorf("%v: %w", ErrWriterWrite, err)
+	}
+
+	extension := w.grow(len(encoded) + w.encoding.UnitSize())
+	for idx := copy(extension, encoded); idx < len(extension); idx++ { // reused buffer memory may be dirty
+		extension[idx] = 0
+	}
+
+	return nil
+}
+
+// WriteBytes appends byte string.
+func (w *BufferWriter) WriteBytes(data []byte) error {
+	_, err := w.Write(data)
+
+	return err
+}
+
+// WriteHex appends byte string defined by hex string.
+func (w *BufferWriter) WriteHex(hexString string) error {
+	data, err := hex.DecodeString(hexString)
+	if err != nil {
+		return fmt.Errorf("%v: %v: hex: %w", ErrWriter, ErrDecodeTo, err)
+	}
+
+	return w.WriteBytes(data)
+}
+
+// WriteObject writes object data the same way BinaryWriter.WriteObject does.
+// BinaryEncoder values encode into w directly, others are encoded by BinaryWriter adapter appending to w.
+// Buffer is extended once beforehand if data implements BinarySizer, but not more than DefaultMaxFrameSize.
+func (w *BufferWriter) WriteObject(data interface{}) error {
+	w.Grow(preallocSize(data))
+
+	if encoder, ok := data.(BinaryEncoder); ok {
+		return encoder.EncodeBinary(w)
+	}
+
+	return AsBinaryWriter(w).WriteObject(data)
+}
+
+// appendBulk appends count elements of specified size starting at data.
+// If configured byte order matches host byte order elements memory is copied as is,
+// otherwise elements are encoded by put.
+func (w *BufferWriter) appendBulk(data unsafe.Pointer, count int, size int, put func(dst []byte, idx int)) error {
+	extension := w.grow(count * size)
+
+	if w.order == hostByteOrder {
+		copy(extension, bytesView(data, count*size))
+
+		return nil
+	}
+
+	for idx, offset := 0, 0; idx < count; idx, offset = idx+1, offset+size {
+		put(extension[offset:], idx)
+	}
+
+	return nil
+}
+
+// WriteUint16s appends uint16 values slice using configured byte order.
+func (w *BufferWriter) WriteUint16s(data []uint16) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return w.appendBulk(unsafe.Pointer(&data[0]), len(data), Uint16size, func(dst []byte, idx int) {
+		w.order.PutUint16(dst, data[idx])
+	})
+}
+
+// WriteInt16s appends int16 values slice using configured byte order.
+func (w *BufferWriter) WriteInt16s(data []int16) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return w.appendBulk(unsafe.Pointer(&data[0]), len(data), Int16size, func(dst []byte, idx int) {
+		w.order.PutUint16(dst, uint16(data[idx]))
+	})
+}
+
+// WriteUint32s appends uint32 values slice using configured byte order.
+func (w *BufferWriter) WriteUint32s(data []uint32) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return w.appendBulk(unsafe.Pointer(&data[0]), len(data), Uint32size, func(dst []byte, idx int) {
+		w.order.PutUint32(dst, data[idx])
+	})
+}
+
+// WriteInt32s appends int32 values slice using configured byte order.
+func (w *BufferWriter) WriteInt32s(data []int32) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return w.appendBulk(unsafe.Pointer(&data[0]), len(data), Int32size, func(dst []byte, idx int) {
+		w.order.PutUint32(dst, uint32(data[idx]))
+	})
+}
+
+// WriteUint64s appends uint64 values slice using configured byte order.
+func (w *BufferWriter) WriteUint64s(data []uint64) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return w.appendBulk(unsafe.Pointer(&data[0]), len(data), Uint64size, func(dst []byte, idx int) {
+		w.order.PutUint64(dst, data[idx])
+	})
+}
+
+// WriteInt64s appends int64 values slice using configured byte order.
+func (w *BufferWriter) WriteInt64s(data []int64) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return w.appendBulk(unsafe.Pointer(&data[0]), len(data), Int64size, func(dst []byte, idx int) {
+		w.order.PutUint64(dst, uint64(data[idx]))
+	})
+}
+
+// WriteFloat32s appends float32 values slice as IEEE 754 bits using configured byte order.
+func (w *BufferWriter) WriteFloat32s(data []float32) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return w.appendBulk(unsafe.Pointer(&data[0]), len(data), Uint32size, func(dst []byte, idx int) {
+		w.order.PutUint32(dst, math.Float32bits(data[idx]))
+	})
+}
+
+// WriteFloat64s appends float64 values slice as IEEE 754 bits using configured byte order.
+func (w *BufferWriter) WriteFloat64s(data []float64) error {
+	if len(data) == 0 {
+		return nil
+	}
+
+	return w.appendBulk(unsafe.Pointer(&data[0]), len(data), Uint64size, func(dst []byte, idx int) {
+		w.order.PutUint64(dst, math.Float64bits(data[idx]))
+	})
+}
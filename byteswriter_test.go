@@ -0,0 +1,129 @@
+package binutils_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/amarin/binutils"
+)
+
+func TestBufferWriter_TypedWrites(t *testing.T) {
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		for _, encoding := range []TextEncoding{nil, UTF16LE, Windows1251} {
+			expected := writeSampleRecord(t, order, encoding)
+
+			writer := NewBinaryWriterBuffer(nil)
+			writer.SetByteOrder(order)
+			writer.SetEncoding(encoding)
+
+			require.NoError(t, writer.WriteUint8(0xfe))
+			require.NoError(t, writer.WriteInt16(math.MinInt16))
+			require.NoError(t, writer.WriteUint32(0xdeadbeef))
+			require.NoError(t, writer.WriteInt64(-2))
+			require.NoError(t, writer.WriteUvarint(300))
+			require.NoError(t, writer.WriteVarint(-300))
+			require.NoError(t, writer.WriteUTF8Rune('ж'))
+			require.NoError(t, writer.WriteRune('€'))
+			require.NoError(t, writer.WriteStringZ("строка"))
+			require.NoError(t, writer.WriteFloat64s([]float64{1.5, -2}))
+			require.NoError(t, writer.WriteHex("cafe"))
+
+			require.Equalf(t, expected, writer.Bytes(), "%v %v", order, encoding)
+			require.Equal(t, len(expected), writer.BytesWritten())
+			require.Equal(t, len(expected), writer.Len())
+		}
+	}
+}
+
+func TestBufferWriter_Reuse(t *testing.T) {
+	buffer := make([]byte, 0, 16)
+	writer := NewBinaryWriterBuffer(buffer)
+	require.Equal(t, 16, writer.Remaining())
+
+	require.NoError(t, writer.WriteStringZ("dirty memory"))
+	require.Equal(t, 3, writer.Remaining())
+	require.True(t, &buffer[:1][0] == &writer.Bytes()[0], "buffer with enough capacity must be reused")
+
+	writer.Reset()
+	require.Zero(t, writer.BytesWritten())
+	require.NoError(t, writer.WriteUint64(1))
+	require.NoError(t, writer.WriteUint8(2))
+
+	writer.SetEncoding(UTF16LE)
+	require.NoError(t, writer.WriteStringZ(""))
+	require.Equal(t, []byte{0, 0, 0, 0, 0, 0, 0, 1, 2, 0, 0}, writer.Bytes())
+
+	require.NoError(t, writer.WriteBytes(make([]byte, 32)))
+	require.Equal(t, 43, writer.Len())
+	require.Equal(t, 43, writer.BytesWritten())
+
+	writer.ResetBytesWritten()
+	require.Zero(t, writer.BytesWritten())
+	require.Equal(t, 43, writer.Len())
+}
+
+func TestBufferWriter_WriteObject(t *testing.T) {
+	writer := NewBinaryWriterBuffer(nil)
+	writer.SetByteOrder(binary.LittleEndian)
+
+	require.NoError(t, writer.WriteObject(uint16(1)))
+	require.NoError(t, writer.WriteObject("ok"))
+	require.NoError(t, writer.WriteObject(bytes.NewBufferString("raw")))
+	require.Error(t, writer.WriteObject(struct{}{}))
+	require.Equal(t, []byte{1, 0, 'o', 'k', 0, 'r', 'a', 'w'}, writer.Bytes())
+	require.Equal(t, 8, writer.BytesWritten())
+}
+
+func TestBufferWriter_WriteSlices(t *testing.T) {
+	values := []int64{-1, 2, math.MinInt64}
+
+	for _, order := range []binary.ByteOrder{binary.BigEndian, binary.LittleEndian} {
+		expected := new(bytes.Buffer)
+		require.NoError(t, binary.Write(expected, order, values))
+
+		writer := NewBinaryWriterBuffer(nil)
+		writer.SetByteOrder(order)
+		require.NoError(t, writer.WriteInt64s(values))
+		require.Equal(t, expected.Bytes(), writer.Bytes())
+	}
+}
+
+func TestBufferWriter_ZeroAllocations(t *testing.T) {
+	writer := NewBinaryWriterBuffer(make([]byte, 0, 1<<20))
+	values := []uint32{1, 2, 3, 4}
+
+	for name, write := range map[string]func(){
+		"WriteUint8":    func() { _ = writer.WriteUint8(1) },
+		"WriteUint16":   func() { _ = writer.WriteUint16(1) },
+		"WriteUint32":   func() { _ = writer.WriteUint32(1) },
+		"WriteUint64":   func() { _ = writer.WriteUint64(1) },
+		"WriteUvarint":  func() { _ = writer.WriteUvarint(math.MaxUint64) },
+		"WriteUTF8Rune": func() { _ = writer.WriteUTF8Rune('ж') },
+		"WriteStringZ":  func() { _ = writer.WriteStringZ("binutils") },
+		"WriteUint32s":  func() { _ = writer.WriteUint32s(values) },
+	} {
+		write := write // pin write
+		t.Run(name, func(t *testing.T) {
+			require.Zero(t, testing.AllocsPerRun(100, write))
+		})
+	}
+}
+
+func BenchmarkBufferWriter_WriteUint32(b *testing.B) {
+	writer := NewBinaryWriterBuffer(make([]byte, 0, 4096))
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if writer.Remaining() == 0 {
+			writer.Reset()
+		}
+
+		if err := writer.WriteUint32(uint32(i)); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,92 @@
+// Package cbor implements Concise Binary Object Representation (RFC 8949) encoding
+// on top of binutils.BinaryReader and binutils.BinaryWriter.
+//
+// Encoder optionally produces Core Deterministic Encoding: shortest form integers, lengths and floats,
+// definite lengths only and map keys sorted by their encoded bytes.
+// Decoder supports indefinite-length items and limits nesting depth and container sizes.
+// Multi-byte values are always big-endian as required by specification regardless of
+// byte order configured for underlying reader or writer.
+package cbor
+
+import (
+	"fmt"
+
+	"github.com/amarin/binutils"
+)
+
+// Major types defined by specification.
+const (
+	majorUnsigned byte = 0
+	majorNegative byte = 1
+	majorBytes    byte = 2
+	majorText     byte = 3
+	majorArray    byte = 4
+	majorMap      byte = 5
+	majorTag      byte = 6
+	majorSimple   byte = 7
+)
+
+// Additional information values having special meaning.
+const (
+	infoUint8      byte = 24
+	infoUint16     byte = 25
+	infoUint32     byte = 26
+	infoUint64     byte = 27
+	infoIndefinite byte = 31
+)
+
+// Simple values and floats additional information.
+const (
+	simpleFalse     byte = 20
+	simpleTrue      byte = 21
+	simpleNull      byte = 22
+	simpleUndefined byte = 23
+	simpleFloat16   byte = 25
+	simpleFloat32   byte = 26
+	simpleFloat64   byte = 27
+)
+
+// breakCode terminates indefinite-length items.
+const breakCode byte = 0xff
+
+// Well known tag numbers.
+const (
+	TagDateTimeString   uint64 = 0 // RFC 3339 date/time string
+	TagEpochTime        uint64 = 1 // epoch-based date/time
+	TagPositiveBignum   uint64 = 2 // unsigned bignum
+	TagNegativeBignum   uint64 = 3 // negative bignum
+	TagSelfDescribeCBOR uint64 = 55799
+)
+
+// Some predefined errors used during processing.
+var (
+	// Error indicates any CBOR errors.
+	Error = fmt.Errorf("%w: cbor", binutils.Error)
+
+	// ErrUnsupportedType returned if value type could not be encoded.
+	ErrUnsupportedType = fmt.Errorf("%w: unsupported type", Error)
+
+	// ErrMalformed returned if encoded data is not well-formed.
+	ErrMalformed = fmt.Errorf("%w: malformed data", Error)
+
+	// ErrLimit returned if decoded data exceeds decoder limits.
+	ErrLimit = fmt.Errorf("%w: limit exceeded", Error)
+
+	// ErrBreak returned if break code encountered outside of indefinite-length item.
+	ErrBreak = fmt.Errorf("%w: unexpected break", Error)
+
+	// ErrOverflow returned if decoded value does not fit into Go type.
+	ErrOverflow = fmt.Errorf("%w: value overflow", Error)
+)
+
+// Tag represents tagged value not translated into Go type by decoder.
+type Tag struct {
+	Number  uint64      // tag number
+	Content interface{} // tagged value
+}
+
+// Simple represents simple value having no predefined meaning.
+type Simple uint8
+
+// Undefined represents undefined simple value.
+type Undefined struct{}
@@ -0,0 +1,372 @@
+package cbor_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amarin/binutils"
+	"github.com/amarin/binutils/cbor"
+)
+
+func encode(t *testing.T, deterministic bool, value interface{}) string {
+	buffer := new(bytes.Buffer)
+	encoder := cbor.NewEncoder(binutils.NewBinaryWriter(buffer))
+	encoder.SetDeterministic(deterministic)
+	require.NoError(t, encoder.Encode(value))
+
+	return hex.EncodeToString(buffer.Bytes())
+}
+
+func decoder(t *testing.T, hexString string) *cbor.Decoder {
+	data, err := hex.DecodeString(hexString)
+	require.NoError(t, err)
+
+	return cbor.NewDecoder(binutils.NewBinaryReader(bytes.NewReader(data)))
+}
+
+func bigInt(t *testing.T, text string) *big.Int {
+	value, ok := new(big.Int).SetString(text, 10)
+	require.True(t, ok)
+
+	return value
+}
+
+func TestEncoder_Encode(t *testing.T) { // RFC 8949 Appendix A
+	for _, tt := range []struct {
+		name  string
+		value interface{}
+		hex   string
+	}{
+		{"zero", 0, "00"},
+		{"uint_23", 23, "17"},
+		{"uint_24", 24, "1818"},
+		{"uint_1000", uint16(1000), "1903e8"},
+		{"uint_1000000", 1000000, "1a000f4240"},
+		{"uint_max", uint64(math.MaxUint64), "1bffffffffffffffff"},
+		{"negative_1", -1, "20"},
+		{"negative_1000", int32(-1000), "3903e7"},
+		{"bignum", bigInt(t, "18446744073709551616"), "c249010000000000000000"},
+		{"negative_bignum", bigInt(t, "-18446744073709551617"), "c349010000000000000000"},
+		{"negative_uint64", bigInt(t, "-18446744073709551616"), "3bffffffffffffffff"},
+		{"float64", 1.1, "fb3ff199999999999a"},
+		{"float32", float32(100000), "fa47c35000"},
+		{"false", false, "f4"},
+		{"true", true, "f5"},
+		{"null", nil, "f6"},
+		{"undefined", cbor.Undefined{}, "f7"},
+		{"simple", cbor.Simple(255), "f8ff"},
+		{"epoch_time", time.Unix(1363896240, 0), "c11a514b67b0"},
+		{"epoch_time_float", time.Unix(1363896240, 500000000), "c1fb41d452d9ec200000"},
+		{"tag", cbor.Tag{Number: 32, Content: "http://www.example.com"},
+			"d82076687474703a2f2f7777772e6578616d706c652e636f6d"},
+		{"bytes", []byte{1, 2, 3, 4}, "4401020304"},
+		{"text", "ü", "62c3bc"},
+		{"array", []int{1, 2, 3}, "83010203"},
+		{"nested", []interface{}{"a", map[string]string{"b": "c"}}, "826161a161626163"},
+		{"struct", struct {
+			A int `cbor:"a"`
+			B int `cbor:"-"`
+		}{A: 1, B: 2}, "a1616101"},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.hex, encode(t, false, tt.value))
+		})
+	}
+}
+
+func TestEncoder_Encode_OmitEmpty(t *testing.T) {
+	type options struct {
+		A int    `cbor:"a,omitempty"`
+		B string `cbor:",omitempty"`
+		C bool   `cbor:"c,omitempty,unknown"`
+		D bool   `cbor:"d"`
+	}
+
+	require.Equal(t, "a16164f4", encode(t, false, options{}))
+	require.Equal(t, "a3616101614261786164f4", encode(t, false, options{A: 1, B: "x"}))
+
+	expected := options{A: -1, B: "text", C: true, D: true}
+	decoded := new(options)
+	require.NoError(t, decoder(t, encode(t, true, expected)).Decode(decoded))
+	require.Equal(t, expected, *decoded)
+}
+
+func TestEncoder_SetDeterministic(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		value interface{}
+		hex   string
+	}{
+		{"float16", 1.5, "f93e00"},
+		{"float16_subnormal", 5.960464477539063e-8, "f90001"},
+		{"float16_infinity", math.Inf(1), "f97c00"},
+		{"float16_nan", math.NaN(), "f97e00"},
+		{"float32", 100000.0, "fa47c35000"},
+		{"float64", 1.1, "fb3ff199999999999a"},
+		{"map_keys", map[interface{}]int{"aa": 4, 100: 2, -1: 3, "z": 5, 10: 1},
+			"a50a011864022003617a05626161" + "04"},
+		{"struct_fields", struct {
+			Long  int
+			B     int
+			Inner map[string]bool
+		}{Long: 1, B: 2, Inner: map[string]bool{"y": true, "x": false}},
+			"a3614202644c6f6e670165496e6e6572a26178f46179f5"},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.hex, encode(t, true, tt.value))
+		})
+	}
+
+	encoder := cbor.NewEncoder(binutils.NewBinaryWriter(new(bytes.Buffer)))
+	encoder.SetDeterministic(true)
+	require.True(t, encoder.Deterministic())
+	require.True(t, errors.Is(encoder.EncodeArrayStart(), cbor.ErrUnsupportedType))
+}
+
+func TestEncoder_Indefinite(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	encoder := cbor.NewEncoder(binutils.NewBinaryWriter(buffer))
+	require.NoError(t, encoder.EncodeMapStart())
+	require.NoError(t, encoder.EncodeString("a"))
+	require.NoError(t, encoder.EncodeArrayStart())
+	require.NoError(t, encoder.EncodeInt(1))
+	require.NoError(t, encoder.EncodeBreak())
+	require.NoError(t, encoder.EncodeString("b"))
+	require.NoError(t, encoder.EncodeStringStart())
+	require.NoError(t, encoder.EncodeString("st"))
+	require.NoError(t, encoder.EncodeString("r"))
+	require.NoError(t, encoder.EncodeBreak())
+	require.NoError(t, encoder.EncodeBreak())
+	require.Equal(t, "bf61619f01ff61627f6273746172ffff",
+		hex.EncodeToString(buffer.Bytes()))
+
+	value, err := decoder(t, hex.EncodeToString(buffer.Bytes())).DecodeInterface()
+	require.NoError(t, err)
+	require.Equal(t, map[interface{}]interface{}{"a": []interface{}{uint64(1)}, "b": "str"}, value)
+}
+
+func TestDecoder_DecodeInterface(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		hex      string
+		expected interface{}
+	}{
+		{"uint", "1903e8", uint64(1000)},
+		{"negative", "3903e7", int64(-1000)},
+		{"negative_uint64", "3bffffffffffffffff", bigInt(t, "-18446744073709551616")},
+		{"bignum", "c249010000000000000000", bigInt(t, "18446744073709551616")},
+		{"negative_bignum", "c349010000000000000000", bigInt(t, "-18446744073709551617")},
+		{"float16", "f93c00", 1.0},
+		{"float16_subnormal", "f90001", 5.960464477539063e-8},
+		{"float32", "fa47c35000", 100000.0},
+		{"null", "f6", nil},
+		{"undefined", "f7", cbor.Undefined{}},
+		{"simple", "f0", cbor.Simple(16)},
+		{"bytes_indefinite", "5f42010243030405ff", []byte{1, 2, 3, 4, 5}},
+		{"text", "6449455446", "IETF"},
+		{"array_indefinite", "9f018202039f0405ffff",
+			[]interface{}{uint64(1), []interface{}{uint64(2), uint64(3)}, []interface{}{uint64(4), uint64(5)}}},
+		{"map", "a201020304", map[interface{}]interface{}{uint64(1): uint64(2), uint64(3): uint64(4)}},
+		{"time_string", "c074323031332d30332d32315432303a30343a30305a", time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC)},
+		{"time_epoch", "c11a514b67b0", time.Unix(1363896240, 0)},
+		{"time_epoch_float", "c1fb41d452d9ec200000", time.Unix(1363896240, 500000000)},
+		{"tag", "d74401020304", cbor.Tag{Number: 23, Content: []byte{1, 2, 3, 4}}},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := decoder(t, tt.hex).DecodeInterface()
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, value)
+		})
+	}
+}
+
+func TestDecoder_Malformed(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		hex      string
+		expected error
+	}{
+		{"reserved_info", "1c", cbor.ErrMalformed},
+		{"indefinite_uint", "1f", cbor.ErrMalformed},
+		{"break", "ff", cbor.ErrBreak},
+		{"wrong_chunk", "5f6161ff", cbor.ErrMalformed},
+		{"short_simple", "f801", cbor.ErrMalformed},
+		{"bad_bignum", "c201", cbor.ErrMalformed},
+		{"unhashable_key", "a14100f6", cbor.ErrUnsupportedType},
+		{"unhashable_tag_key", "a1d8638000", cbor.ErrUnsupportedType},
+		{"unhashable_nested_tag_key", "a1d863d864a000", cbor.ErrUnsupportedType},
+		{"truncated", "1a0000", io.ErrUnexpectedEOF},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decoder(t, tt.hex).DecodeInterface()
+			require.Error(t, err)
+			require.True(t, errors.Is(err, tt.expected), err.Error())
+		})
+	}
+}
+
+func TestDecoder_SetLimits(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		hex    string
+		limits cbor.Limits
+	}{
+		{"depth", "818181818100", cbor.Limits{MaxDepth: 4, MaxContainerLen: 10, MaxStringLen: 10}},
+		{"depth_tags", "c6c6c6c600", cbor.Limits{MaxDepth: 3, MaxContainerLen: 10, MaxStringLen: 10}},
+		{"array_len", "9a7fffffff", cbor.Limits{MaxDepth: 4, MaxContainerLen: 10, MaxStringLen: 10}},
+		{"array_indefinite", "9f010203ff", cbor.Limits{MaxDepth: 4, MaxContainerLen: 2, MaxStringLen: 10}},
+		{"map_len", "a3010203040506", cbor.Limits{MaxDepth: 4, MaxContainerLen: 2, MaxStringLen: 10}},
+		{"string_len", "5affffffff", cbor.Limits{MaxDepth: 4, MaxContainerLen: 10, MaxStringLen: 10}},
+		{"string_chunks", "7f63616161636161616361616161ff", cbor.Limits{MaxDepth: 4, MaxContainerLen: 10, MaxStringLen: 8}},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			target := decoder(t, tt.hex)
+			target.SetLimits(tt.limits)
+			require.Equal(t, tt.limits, target.Limits())
+			_, err := target.DecodeInterface()
+			require.True(t, errors.Is(err, cbor.ErrLimit), err)
+		})
+	}
+}
+
+func TestDecoder_Decode(t *testing.T) {
+	type inner struct {
+		Flags []bool `cbor:"f"`
+	}
+
+	type message struct {
+		Name    string            `cbor:"n"`
+		Count   int16             `cbor:"c"`
+		Ratio   float32           `cbor:"r"`
+		Data    []byte            `cbor:"d"`
+		When    time.Time         `cbor:"w"`
+		Big     *big.Int          `cbor:"b"`
+		Labels  map[string]uint   `cbor:"l"`
+		Inner   *inner            `cbor:"i"`
+		Any     interface{}       `cbor:"a"`
+		Skipped string            `cbor:"-"`
+		Extra   map[string]string `cbor:"x"`
+	}
+
+	source := message{
+		Name:   "device",
+		Count:  -5,
+		Ratio:  0.5,
+		Data:   []byte{1, 2},
+		When:   time.Unix(1600000000, 0),
+		Big:    bigInt(t, "123456789012345678901234567890"),
+		Labels: map[string]uint{"a": 1},
+		Inner:  &inner{Flags: []bool{true, false}},
+		Any:    "value",
+	}
+
+	buffer := new(bytes.Buffer)
+	encoder := cbor.NewEncoder(binutils.NewBinaryWriter(buffer))
+	encoder.SetDeterministic(true)
+	require.NoError(t, encoder.Encode(source))
+
+	var target message
+	require.NoError(t, cbor.NewDecoder(binutils.NewBinaryReader(buffer)).Decode(&target))
+	require.Equal(t, source, target)
+
+	var small int8
+	require.True(t, errors.Is(decoder(t, "190100").Decode(&small), cbor.ErrOverflow))
+
+	var unsigned uint
+	require.True(t, errors.Is(decoder(t, "20").Decode(&unsigned), cbor.ErrOverflow))
+
+	var text string
+	require.True(t, errors.Is(decoder(t, "01").Decode(&text), cbor.ErrUnsupportedType))
+	require.True(t, errors.Is(decoder(t, "01").Decode(text), binutils.ErrNilPointer))
+}
+
+func TestDecoder_Decode_Array(t *testing.T) {
+	type record struct {
+		Hash   [4]byte     `cbor:"h"`
+		Pair   [2]int      `cbor:"p"`
+		Points [2][2]int16 `cbor:"g"`
+	}
+
+	source := record{Hash: [4]byte{1, 2, 3, 4}, Pair: [2]int{-1, 1}, Points: [2][2]int16{{1, 2}, {3, 4}}}
+	encoded := encode(t, true, source)
+	require.Equal(t, "a3616782820102820304616844010203046170822001", encoded)
+
+	var target record
+	require.NoError(t, decoder(t, encoded).Decode(&target))
+	require.Equal(t, source, target)
+
+	short := [4]byte{9, 9, 9, 9}
+	require.NoError(t, decoder(t, "420102").Decode(&short))
+	require.Equal(t, [4]byte{1, 2}, short, "missing elements must be zeroed")
+
+	var pair [2]int
+	require.True(t, errors.Is(decoder(t, "83010203").Decode(&pair), cbor.ErrOverflow))
+	require.True(t, errors.Is(decoder(t, "4401020304").Decode(&pair), cbor.ErrUnsupportedType))
+	require.True(t, errors.Is(decoder(t, "01").Decode(&short), cbor.ErrUnsupportedType))
+}
+
+func TestDecoder_Typed(t *testing.T) {
+	target := decoder(t, "1818"+"3903e7"+"4101"+"7f6161ff"+"9f"+"ff"+"a0"+"c1"+"00"+"f5"+"f6"+"f93e00"+"c249010000000000000000")
+
+	unsigned, err := target.DecodeUint()
+	require.NoError(t, err)
+	require.Equal(t, uint64(24), unsigned)
+
+	signed, err := target.DecodeInt()
+	require.NoError(t, err)
+	require.Equal(t, int64(-1000), signed)
+
+	data, err := target.DecodeBytes()
+	require.NoError(t, err)
+	require.Equal(t, []byte{1}, data)
+
+	text, err := target.DecodeString()
+	require.NoError(t, err)
+	require.Equal(t, "a", text)
+
+	length, err := target.DecodeArrayLen()
+	require.NoError(t, err)
+	require.Equal(t, -1, length)
+
+	done, err := target.DecodeBreak()
+	require.NoError(t, err)
+	require.True(t, done)
+
+	length, err = target.DecodeMapLen()
+	require.NoError(t, err)
+	require.Equal(t, 0, length)
+
+	when, err := target.DecodeTime()
+	require.NoError(t, err)
+	require.Equal(t, time.Unix(0, 0), when)
+
+	flag, err := target.DecodeBool()
+	require.NoError(t, err)
+	require.True(t, flag)
+
+	done, err = target.DecodeBreak()
+	require.NoError(t, err)
+	require.False(t, done)
+	require.NoError(t, target.DecodeNil())
+
+	float, err := target.DecodeFloat()
+	require.NoError(t, err)
+	require.Equal(t, 1.5, float)
+
+	number, err := target.DecodeBigInt()
+	require.NoError(t, err)
+	require.Equal(t, bigInt(t, "18446744073709551616"), number)
+}
@@ -0,0 +1,803 @@
+package cbor
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"time"
+
+	"github.com/amarin/binutils"
+)
+
+// Limits restricts decoded data to protect decoder from malicious input.
+type Limits struct {
+	MaxDepth        int // maximum nesting level of arrays, maps and tags
+	MaxContainerLen int // maximum number of array items or map pairs
+	MaxStringLen    int // maximum byte or text string length, including all indefinite-length chunks
+}
+
+// DefaultLimits used by decoders unless changed by SetLimits.
+var DefaultLimits = Limits{ // nolint:gochecknoglobals
+	MaxDepth:        64,
+	MaxContainerLen: 1 << 16,
+	MaxStringLen:    binutils.DefaultMaxFrameSize,
+}
+
+// Decoder reads CBOR encoded values from binutils.BinaryReader.
+type Decoder struct {
+	reader  *binutils.BinaryReader
+	limits  Limits
+	depth   int
+	peeked  bool // initial byte already read by isBreak
+	initial byte
+	scratch [binutils.Uint64size]byte
+}
+
+// NewDecoder creates Decoder reading from reader using DefaultLimits.
+func NewDecoder(reader *binutils.BinaryReader) *Decoder {
+	return &Decoder{reader: reader, limits: DefaultLimits}
+}
+
+// SetLimits sets decoder limits.
+func (d *Decoder) SetLimits(limits Limits) {
+	d.limits = limits
+}
+
+// Limits returns current decoder limits.
+func (d *Decoder) Limits() Limits {
+	return d.limits
+}
+
+// head is a decoded initial byte with its argument.
+type head struct {
+	major    byte
+	info     byte
+	argument uint64
+}
+
+// indefinite returns true if head starts indefinite-length item.
+func (h head) indefinite() bool {
+	return h.info == infoIndefinite
+}
+
+// readHead reads initial byte and following argument bytes.
+func (d *Decoder) readHead() (h head, err error) {
+	initial, err := d.readInitial()
+	if err != nil {
+		return h, err
+	}
+
+	h.major, h.info = initial>>5, initial&0x1f
+
+	switch {
+	case h.info < infoUint8:
+		h.argument = uint64(h.info)
+	case h.info <= infoUint64:
+		size := 1 << (h.info - infoUint8)
+		buffer := d.scratch[:size]
+
+		if err = d.reader.ReadBytesInto(buffer); err != nil {
+			return h, err
+		}
+
+		switch size {
+		case binutils.Uint8size:
+			h.argument = uint64(buffer[0])
+		case binutils.Uint16size:
+			h.argument = uint64(binary.BigEndian.Uint16(buffer))
+		case binutils.Uint32size:
+			h.argument = uint64(binary.BigEndian.Uint32(buffer))
+		default:
+			h.argument = binary.BigEndian.Uint64(buffer)
+		}
+	case h.info == infoIndefinite:
+		switch h.major {
+		case majorUnsigned, majorNegative, majorTag:
+			return h, fmt.Errorf("%w: indefinite length for major type %d", ErrMalformed, h.major)
+		case majorSimple:
+			return h, ErrBreak
+		}
+	default:
+		return h, fmt.Errorf("%w: reserved additional information %d", ErrMalformed, h.info)
+	}
+
+	return h, nil
+}
+
+// readExpected reads head and checks its major type.
+func (d *Decoder) readExpected(major byte) (head, error) {
+	h, err := d.readHead()
+	if err == nil && h.major != major {
+		return h, fmt.Errorf("%w: major type %d, expected %d", ErrMalformed, h.major, major)
+	}
+
+	return h, err
+}
+
+// readInitial reads item initial byte, taking byte peeked by isBreak first.
+func (d *Decoder) readInitial() (byte, error) {
+	if d.peeked {
+		d.peeked = false
+
+		return d.initial, nil
+	}
+
+	return d.reader.ReadUint8()
+}
+
+// isBreak checks if next byte is a break code and consumes it if so.
+// Otherwise byte is kept for following readHead.
+func (d *Decoder) isBreak() (bool, error) {
+	code, err := d.readInitial()
+	if err != nil {
+		return false, err
+	}
+
+	if code == breakCode {
+		return true, nil
+	}
+
+	d.peeked, d.initial = true, code
+
+	return false, nil
+}
+
+// enter increments nesting depth checking limit.
+func (d *Decoder) enter() error {
+	if d.depth >= d.limits.MaxDepth {
+		return fmt.Errorf("%w: depth %d", ErrLimit, d.limits.MaxDepth)
+	}
+
+	d.depth++
+
+	return nil
+}
+
+// leave decrements nesting depth.
+func (d *Decoder) leave() {
+	d.depth--
+}
+
+// checkContainerLen checks container length limit.
+func (d *Decoder) checkContainerLen(length uint64) error {
+	if length > uint64(d.limits.MaxContainerLen) {
+		return fmt.Errorf("%w: container length %d", ErrLimit, length)
+	}
+
+	return nil
+}
+
+// DecodeUint reads unsigned integer.
+func (d *Decoder) DecodeUint() (uint64, error) {
+	h, err := d.readExpected(majorUnsigned)
+
+	return h.argument, err
+}
+
+// DecodeInt reads signed integer. Returns ErrOverflow if value does not fit int64.
+func (d *Decoder) DecodeInt() (int64, error) {
+	h, err := d.readHead()
+
+	switch {
+	case err != nil:
+		return 0, err
+	case h.major != majorUnsigned && h.major != majorNegative:
+		return 0, fmt.Errorf("%w: major type %d, expected integer", ErrMalformed, h.major)
+	case h.argument > math.MaxInt64:
+		return 0, fmt.Errorf("%w: int64", ErrOverflow)
+	case h.major == majorNegative:
+		return -1 - int64(h.argument), nil
+	default:
+		return int64(h.argument), nil
+	}
+}
+
+// readString reads byte or text string content following head, concatenating indefinite-length chunks.
+func (d *Decoder) readString(h head) ([]byte, error) {
+	if !h.indefinite() {
+		if h.argument > uint64(d.limits.MaxStringLen) {
+			return nil, fmt.Errorf("%w: string length %d", ErrLimit, h.argument)
+		}
+
+		data := make([]byte, h.argument)
+
+		return data, d.reader.ReadBytesInto(data)
+	}
+
+	var result []byte
+
+	for {
+		done, err := d.isBreak()
+		if err != nil || done {
+			return result, err
+		}
+
+		chunk, err := d.readHead()
+
+		switch {
+		case err != nil:
+			return nil, err
+		case chunk.major != h.major || chunk.indefinite():
+			return nil, fmt.Errorf("%w: invalid indefinite-length string chunk", ErrMalformed)
+		case uint64(len(result))+chunk.argument > uint64(d.limits.MaxStringLen):
+			return nil, fmt.Errorf("%w: string length exceeds %d", ErrLimit, d.limits.MaxStringLen)
+		}
+
+		offset := len(result)
+		result = append(result, make([]byte, chunk.argument)...)
+
+		if err = d.reader.ReadBytesInto(result[offset:]); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// DecodeBytes reads byte string.
+func (d *Decoder) DecodeBytes() ([]byte, error) {
+	h, err := d.readExpected(majorBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.readString(h)
+}
+
+// DecodeString reads text string.
+func (d *Decoder) DecodeString() (string, error) {
+	h, err := d.readExpected(majorText)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := d.readString(h)
+
+	return string(data), err
+}
+
+// DecodeArrayLen reads array head. Returns -1 for indefinite-length array terminated by break code.
+func (d *Decoder) DecodeArrayLen() (int, error) {
+	return d.decodeContainerLen(majorArray)
+}
+
+// DecodeMapLen reads map head. Returns -1 for indefinite-length map terminated by break code.
+func (d *Decoder) DecodeMapLen() (int, error) {
+	return d.decodeContainerLen(majorMap)
+}
+
+// decodeContainerLen reads array or map head.
+func (d *Decoder) decodeContainerLen(major byte) (int, error) {
+	h, err := d.readExpected(major)
+
+	switch {
+	case err != nil:
+		return 0, err
+	case h.indefinite():
+		return -1, nil
+	default:
+		if err = d.checkContainerLen(h.argument); err != nil {
+			return 0, err
+		}
+
+		return int(h.argument), nil
+	}
+}
+
+// DecodeBreak reads break code terminating indefinite-length item.
+// Returns false without consuming anything if next item is not a break code.
+func (d *Decoder) DecodeBreak() (bool, error) {
+	return d.isBreak()
+}
+
+// DecodeTag reads tag number. Tagged item follows.
+func (d *Decoder) DecodeTag() (uint64, error) {
+	h, err := d.readExpected(majorTag)
+
+	return h.argument, err
+}
+
+// DecodeBool reads bool value.
+func (d *Decoder) DecodeBool() (bool, error) {
+	h, err := d.readExpected(majorSimple)
+
+	switch {
+	case err != nil:
+		return false, err
+	case h.info == simpleTrue:
+		return true, nil
+	case h.info == simpleFalse:
+		return false, nil
+	default:
+		return false, fmt.Errorf("%w: simple value %d, expected bool", ErrMalformed, h.info)
+	}
+}
+
+// DecodeNil reads null value.
+func (d *Decoder) DecodeNil() error {
+	h, err := d.readExpected(majorSimple)
+	if err == nil && h.info != simpleNull {
+		return fmt.Errorf("%w: simple value %d, expected null", ErrMalformed, h.info)
+	}
+
+	return err
+}
+
+// DecodeFloat reads half, single or double precision float value.
+func (d *Decoder) DecodeFloat() (float64, error) {
+	h, err := d.readExpected(majorSimple)
+	if err != nil {
+		return 0, err
+	}
+
+	return floatValue(h)
+}
+
+// floatValue converts float head argument to float64.
+func floatValue(h head) (float64, error) {
+	switch h.info {
+	case simpleFloat16:
+		return float16Value(uint16(h.argument)), nil
+	case simpleFloat32:
+		return float64(math.Float32frombits(uint32(h.argument))), nil
+	case simpleFloat64:
+		return math.Float64frombits(h.argument), nil
+	default:
+		return 0, fmt.Errorf("%w: simple value %d, expected float", ErrMalformed, h.info)
+	}
+}
+
+// DecodeTime reads date/time tagged either by TagDateTimeString or TagEpochTime.
+func (d *Decoder) DecodeTime() (time.Time, error) {
+	value, err := d.DecodeInterface()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	result, ok := value.(time.Time)
+	if !ok {
+		return time.Time{}, fmt.Errorf("%w: %T, expected date/time", ErrMalformed, value)
+	}
+
+	return result, nil
+}
+
+// DecodeBigInt reads integer or bignum.
+func (d *Decoder) DecodeBigInt() (*big.Int, error) {
+	value, err := d.DecodeInterface()
+	if err != nil {
+		return nil, err
+	}
+
+	switch typed := value.(type) {
+	case uint64:
+		return new(big.Int).SetUint64(typed), nil
+	case int64:
+		return big.NewInt(typed), nil
+	case *big.Int:
+		return typed, nil
+	default:
+		return nil, fmt.Errorf("%w: %T, expected integer", ErrMalformed, value)
+	}
+}
+
+// DecodeInterface reads any value. Values are decoded as following:
+// unsigned integers as uint64, negative integers as int64 or *big.Int if overflows int64,
+// byte strings as []byte, text strings as string, arrays as []interface{},
+// maps as map[interface{}]interface{}, date/time tags as time.Time, bignums as *big.Int,
+// other tags as Tag, floats as float64, null as nil, undefined as Undefined
+// and unassigned simple values as Simple.
+func (d *Decoder) DecodeInterface() (interface{}, error) {
+	h, err := d.readHead()
+	if err != nil {
+		return nil, err
+	}
+
+	return d.decodeInterface(h)
+}
+
+// decodeInterface reads value following head.
+func (d *Decoder) decodeInterface(h head) (interface{}, error) { // nolint:gocyclo
+	switch h.major {
+	case majorUnsigned:
+		return h.argument, nil
+	case majorNegative:
+		if h.argument > math.MaxInt64 {
+			value := new(big.Int).SetUint64(h.argument)
+
+			return value.Neg(value).Sub(value, big.NewInt(1)), nil
+		}
+
+		return -1 - int64(h.argument), nil
+	case majorBytes:
+		return d.readString(h)
+	case majorText:
+		data, err := d.readString(h)
+
+		return string(data), err
+	case majorArray:
+		return d.decodeArray(h)
+	case majorMap:
+		return d.decodeMap(h)
+	case majorTag:
+		return d.decodeTagged(h.argument)
+	default:
+		return d.decodeSimple(h)
+	}
+}
+
+// decodeArray reads array items following head.
+func (d *Decoder) decodeArray(h head) (interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer d.leave()
+
+	if !h.indefinite() {
+		if err := d.checkContainerLen(h.argument); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make([]interface{}, 0, capacity(h))
+
+	for idx := uint64(0); h.indefinite() || idx < h.argument; idx++ {
+		if h.indefinite() {
+			if done, err := d.isBreak(); err != nil || done {
+				return result, err
+			}
+
+			if err := d.checkContainerLen(idx + 1); err != nil {
+				return nil, err
+			}
+		}
+
+		item, err := d.DecodeInterface()
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, item)
+	}
+
+	return result, nil
+}
+
+// decodeMap reads map pairs following head.
+func (d *Decoder) decodeMap(h head) (interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer d.leave()
+
+	if !h.indefinite() {
+		if err := d.checkContainerLen(h.argument); err != nil {
+			return nil, err
+		}
+	}
+
+	result := make(map[interface{}]interface{}, capacity(h))
+
+	for idx := uint64(0); h.indefinite() || idx < h.argument; idx++ {
+		if h.indefinite() {
+			if done, err := d.isBreak(); err != nil || done {
+				return result, err
+			}
+
+			if err := d.checkContainerLen(idx + 1); err != nil {
+				return nil, err
+			}
+		}
+
+		key, err := d.DecodeInterface()
+		if err != nil {
+			return nil, err
+		}
+
+		if !hashable(key) {
+			return nil, fmt.Errorf("%w: map key %T", ErrUnsupportedType, key)
+		}
+
+		value, err := d.DecodeInterface()
+		if err != nil {
+			return nil, err
+		}
+
+		result[key] = value
+	}
+
+	return result, nil
+}
+
+// hashable returns true if decoded value could be used as map key.
+// Tag is comparable itself, so its content is checked too.
+func hashable(value interface{}) bool {
+	if tag, ok := value.(Tag); ok {
+		return hashable(tag.Content)
+	}
+
+	return value == nil || reflect.TypeOf(value).Comparable()
+}
+
+// decodeTagged reads tagged item translating well known tags into Go types.
+func (d *Decoder) decodeTagged(number uint64) (interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer d.leave()
+
+	content, err := d.DecodeInterface()
+	if err != nil {
+		return nil, err
+	}
+
+	switch number {
+	case TagDateTimeString:
+		if text, ok := content.(string); ok {
+			return time.Parse(time.RFC3339Nano, text)
+		}
+	case TagEpochTime:
+		switch typed := content.(type) {
+		case uint64:
+			if typed <= math.MaxInt64 {
+				return time.Unix(int64(typed), 0), nil
+			}
+		case int64:
+			return time.Unix(typed, 0), nil
+		case float64:
+			if !math.IsNaN(typed) && !math.IsInf(typed, 0) {
+				seconds, fraction := math.Modf(typed)
+
+				return time.Unix(int64(seconds), int64(math.Round(fraction*float64(time.Second)))), nil
+			}
+		}
+	case TagPositiveBignum, TagNegativeBignum:
+		if data, ok := content.([]byte); ok {
+			value := new(big.Int).SetBytes(data)
+			if number == TagNegativeBignum {
+				value.Neg(value).Sub(value, big.NewInt(1))
+			}
+
+			return value, nil
+		}
+	default:
+		return Tag{Number: number, Content: content}, nil
+	}
+
+	return nil, fmt.Errorf("%w: tag %d content %T", ErrMalformed, number, content)
+}
+
+// decodeSimple reads simple or float value following head.
+func (d *Decoder) decodeSimple(h head) (interface{}, error) {
+	switch h.info {
+	case simpleFalse:
+		return false, nil
+	case simpleTrue:
+		return true, nil
+	case simpleNull:
+		return nil, nil
+	case simpleUndefined:
+		return Undefined{}, nil
+	case simpleFloat16, simpleFloat32, simpleFloat64:
+		return floatValue(h)
+	case infoUint8:
+		if h.argument < 32 {
+			return nil, fmt.Errorf("%w: two-byte simple value %d", ErrMalformed, h.argument)
+		}
+
+		return Simple(h.argument), nil
+	default:
+		return Simple(h.info), nil
+	}
+}
+
+// capacity returns safe preallocation capacity for container head.
+func capacity(h head) int {
+	const maxPreallocate = 1024
+
+	if h.indefinite() || h.argument > maxPreallocate {
+		return 0
+	}
+
+	return int(h.argument)
+}
+
+// Decode reads value into target pointer.
+// Target could be any type supported by Encoder, values are converted from DecodeInterface result.
+func (d *Decoder) Decode(target interface{}) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return fmt.Errorf("%w: %T", binutils.ErrNilPointer, target)
+	}
+
+	decoded, err := d.DecodeInterface()
+	if err != nil {
+		return err
+	}
+
+	return assign(decoded, value.Elem())
+}
+
+// assign sets target from decoded value.
+func assign(decoded interface{}, target reflect.Value) error { // nolint:gocyclo
+	if decoded == nil {
+		target.Set(reflect.Zero(target.Type()))
+		return nil
+	}
+
+	source := reflect.ValueOf(decoded)
+
+	switch target.Kind() {
+	case reflect.Ptr:
+		if source.Type().AssignableTo(target.Type()) { // *big.Int
+			target.Set(source)
+			return nil
+		}
+
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+
+		return assign(decoded, target.Elem())
+	case reflect.Interface:
+		if !source.Type().AssignableTo(target.Type()) {
+			return mismatch(decoded, target)
+		}
+
+		target.Set(source)
+
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		switch typed := decoded.(type) {
+		case int64:
+			if !target.OverflowInt(typed) {
+				target.SetInt(typed)
+				return nil
+			}
+		case uint64:
+			if typed <= math.MaxInt64 && !target.OverflowInt(int64(typed)) {
+				target.SetInt(int64(typed))
+				return nil
+			}
+		default:
+			return mismatch(decoded, target)
+		}
+
+		return fmt.Errorf("%w: %v", ErrOverflow, target.Type())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		switch typed := decoded.(type) {
+		case uint64:
+			if !target.OverflowUint(typed) {
+				target.SetUint(typed)
+				return nil
+			}
+		case int64:
+		default:
+			return mismatch(decoded, target)
+		}
+
+		return fmt.Errorf("%w: %v", ErrOverflow, target.Type())
+	case reflect.Float32, reflect.Float64:
+		typed, ok := decoded.(float64)
+		if !ok {
+			return mismatch(decoded, target)
+		}
+
+		target.SetFloat(typed)
+
+		return nil
+	case reflect.Slice:
+		if data, ok := decoded.([]byte); ok && target.Type().Elem().Kind() == reflect.Uint8 {
+			target.SetBytes(data)
+			return nil
+		}
+
+		items, ok := decoded.([]interface{})
+		if !ok {
+			return mismatch(decoded, target)
+		}
+
+		target.Set(reflect.MakeSlice(target.Type(), len(items), len(items)))
+
+		for idx, item := range items {
+			if err := assign(item, target.Index(idx)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case reflect.Array:
+		return assignArray(decoded, target)
+	case reflect.Map:
+		pairs, ok := decoded.(map[interface{}]interface{})
+		if !ok {
+			return mismatch(decoded, target)
+		}
+
+		target.Set(reflect.MakeMapWithSize(target.Type(), len(pairs)))
+
+		for key, value := range pairs {
+			mapKey := reflect.New(target.Type().Key()).Elem()
+			if err := assign(key, mapKey); err != nil {
+				return err
+			}
+
+			mapValue := reflect.New(target.Type().Elem()).Elem()
+			if err := assign(value, mapValue); err != nil {
+				return err
+			}
+
+			target.SetMapIndex(mapKey, mapValue)
+		}
+
+		return nil
+	case reflect.Struct:
+		if source.Type().AssignableTo(target.Type()) { // time.Time
+			target.Set(source)
+			return nil
+		}
+
+		pairs, ok := decoded.(map[interface{}]interface{})
+		if !ok {
+			return mismatch(decoded, target)
+		}
+
+		for _, field := range structFields(target.Type()) {
+			if value, found := pairs[field.name]; found {
+				if err := assign(value, target.Field(field.index)); err != nil {
+					return fmt.Errorf("field %v: %w", field.name, err)
+				}
+			}
+		}
+
+		return nil
+	default:
+		if !source.Type().ConvertibleTo(target.Type()) || source.Kind() != target.Kind() {
+			return mismatch(decoded, target)
+		}
+
+		target.Set(source.Convert(target.Type()))
+
+		return nil
+	}
+}
+
+// assignArray sets array target from decoded byte string or array, elements missing in decoded value are zeroed.
+// Returns ErrOverflow if decoded value has more elements than target array.
+func assignArray(decoded interface{}, target reflect.Value) error {
+	source := reflect.ValueOf(decoded)
+
+	switch decoded.(type) {
+	case []byte:
+		if target.Type().Elem().Kind() != reflect.Uint8 {
+			return mismatch(decoded, target)
+		}
+	case []interface{}:
+	default:
+		return mismatch(decoded, target)
+	}
+
+	if source.Len() > target.Len() {
+		return fmt.Errorf("%w: %d elements into %v", ErrOverflow, source.Len(), target.Type())
+	}
+
+	target.Set(reflect.Zero(target.Type()))
+
+	if data, ok := decoded.([]byte); ok {
+		reflect.Copy(target, reflect.ValueOf(data))
+		return nil
+	}
+
+	for idx := 0; idx < source.Len(); idx++ {
+		if err := assign(source.Index(idx).Interface(), target.Index(idx)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mismatch returns error describing incompatible decoded value and target.
+func mismatch(decoded interface{}, target reflect.Value) error {
+	return fmt.Errorf("%w: cannot decode %T into %v", ErrUnsupportedType, decoded, target.Type())
+}
@@ -0,0 +1,458 @@
+package cbor
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/amarin/binutils"
+)
+
+// Encoder writes CBOR encoded values into binutils.BinaryWriter.
+type Encoder struct {
+	writer        *binutils.BinaryWriter
+	deterministic bool
+	buffer        []byte // head encoding buffer
+}
+
+// NewEncoder creates Encoder writing into writer.
+func NewEncoder(writer *binutils.BinaryWriter) *Encoder {
+	return &Encoder{writer: writer, buffer: make([]byte, 0, 1+binutils.Uint64size)}
+}
+
+// SetDeterministic enables or disables Core Deterministic Encoding mode.
+// In deterministic mode floats are written in the shortest form preserving value,
+// map keys are sorted by their encoded bytes and indefinite-length items are rejected.
+// Integers and lengths are always written in the shortest form.
+func (e *Encoder) SetDeterministic(deterministic bool) {
+	e.deterministic = deterministic
+}
+
+// Deterministic returns true if Core Deterministic Encoding mode enabled.
+func (e *Encoder) Deterministic() bool {
+	return e.deterministic
+}
+
+// encodeHead writes major type and argument using the shortest form.
+func (e *Encoder) encodeHead(major byte, argument uint64) error {
+	major <<= 5
+
+	switch {
+	case argument < uint64(infoUint8):
+		e.buffer = append(e.buffer[:0], major|byte(argument))
+	case argument <= math.MaxUint8:
+		e.buffer = append(e.buffer[:0], major|infoUint8, byte(argument))
+	case argument <= math.MaxUint16:
+		e.buffer = binutils.AppendUint16(append(e.buffer[:0], major|infoUint16), uint16(argument))
+	case argument <= math.MaxUint32:
+		e.buffer = binutils.AppendUint32(append(e.buffer[:0], major|infoUint32), uint32(argument))
+	default:
+		e.buffer = binutils.AppendUint64(append(e.buffer[:0], major|infoUint64), argument)
+	}
+
+	return e.writer.WriteBytes(e.buffer)
+}
+
+// EncodeUint writes unsigned integer.
+func (e *Encoder) EncodeUint(value uint64) error {
+	return e.encodeHead(majorUnsigned, value)
+}
+
+// EncodeInt writes signed integer.
+func (e *Encoder) EncodeInt(value int64) error {
+	if value < 0 {
+		return e.encodeHead(majorNegative, uint64(-(value + 1)))
+	}
+
+	return e.encodeHead(majorUnsigned, uint64(value))
+}
+
+// EncodeBigInt writes big integer as integer if it fits 64 bits or as bignum otherwise.
+func (e *Encoder) EncodeBigInt(value *big.Int) error {
+	if value.Sign() >= 0 {
+		if value.IsUint64() {
+			return e.EncodeUint(value.Uint64())
+		}
+
+		if err := e.EncodeTag(TagPositiveBignum); err != nil {
+			return err
+		}
+
+		return e.EncodeBytes(value.Bytes())
+	}
+
+	magnitude := new(big.Int).Neg(value) // -1 - value
+	magnitude.Sub(magnitude, big.NewInt(1))
+
+	if magnitude.IsUint64() {
+		return e.encodeHead(majorNegative, magnitude.Uint64())
+	}
+
+	if err := e.EncodeTag(TagNegativeBignum); err != nil {
+		return err
+	}
+
+	return e.EncodeBytes(magnitude.Bytes())
+}
+
+// EncodeBytes writes byte string.
+func (e *Encoder) EncodeBytes(value []byte) error {
+	if err := e.encodeHead(majorBytes, uint64(len(value))); err != nil {
+		return err
+	}
+
+	return e.writer.WriteBytes(value)
+}
+
+// EncodeString writes text string.
+func (e *Encoder) EncodeString(value string) error {
+	if err := e.encodeHead(majorText, uint64(len(value))); err != nil {
+		return err
+	}
+
+	return e.writer.WriteBytes([]byte(value))
+}
+
+// EncodeArrayLen writes array head. Caller must encode exactly length items next.
+func (e *Encoder) EncodeArrayLen(length int) error {
+	return e.encodeHead(majorArray, uint64(length))
+}
+
+// EncodeMapLen writes map head. Caller must encode exactly length key and value pairs next.
+// Note keys order is caller responsibility when map is encoded item by item.
+func (e *Encoder) EncodeMapLen(length int) error {
+	return e.encodeHead(majorMap, uint64(length))
+}
+
+// encodeIndefinite writes indefinite-length item head.
+func (e *Encoder) encodeIndefinite(major byte) error {
+	if e.deterministic {
+		return fmt.Errorf("%w: indefinite length in deterministic mode", ErrUnsupportedType)
+	}
+
+	return e.writer.WriteUint8(major<<5 | infoIndefinite)
+}
+
+// EncodeArrayStart writes indefinite-length array head. Finish items using EncodeBreak.
+func (e *Encoder) EncodeArrayStart() error { return e.encodeIndefinite(majorArray) }
+
+// EncodeMapStart writes indefinite-length map head. Finish pairs using EncodeBreak.
+func (e *Encoder) EncodeMapStart() error { return e.encodeIndefinite(majorMap) }
+
+// EncodeBytesStart writes indefinite-length byte string head.
+// Write chunks using EncodeBytes and finish using EncodeBreak.
+func (e *Encoder) EncodeBytesStart() error { return e.encodeIndefinite(majorBytes) }
+
+// EncodeStringStart writes indefinite-length text string head.
+// Write chunks using EncodeString and finish using EncodeBreak.
+func (e *Encoder) EncodeStringStart() error { return e.encodeIndefinite(majorText) }
+
+// EncodeBreak terminates indefinite-length item.
+func (e *Encoder) EncodeBreak() error {
+	return e.writer.WriteUint8(breakCode)
+}
+
+// EncodeTag writes tag head. Caller must encode tagged item next.
+func (e *Encoder) EncodeTag(number uint64) error {
+	return e.encodeHead(majorTag, number)
+}
+
+// EncodeBool writes bool value.
+func (e *Encoder) EncodeBool(value bool) error {
+	if value {
+		return e.writer.WriteUint8(majorSimple<<5 | simpleTrue)
+	}
+
+	return e.writer.WriteUint8(majorSimple<<5 | simpleFalse)
+}
+
+// EncodeNil writes null value.
+func (e *Encoder) EncodeNil() error {
+	return e.writer.WriteUint8(majorSimple<<5 | simpleNull)
+}
+
+// EncodeUndefined writes undefined value.
+func (e *Encoder) EncodeUndefined() error {
+	return e.writer.WriteUint8(majorSimple<<5 | simpleUndefined)
+}
+
+// EncodeSimple writes simple value. Values 24..31 are reserved and rejected.
+func (e *Encoder) EncodeSimple(value Simple) error {
+	if value >= 24 && value < 32 {
+		return fmt.Errorf("%w: reserved simple value %d", ErrUnsupportedType, value)
+	}
+
+	return e.encodeHead(majorSimple, uint64(value))
+}
+
+// EncodeFloat32 writes float32 value, using half precision form in deterministic mode if value allows.
+func (e *Encoder) EncodeFloat32(value float32) error {
+	if e.deterministic {
+		if half, ok := float16Bits(value); ok {
+			e.buffer = binutils.AppendUint16(append(e.buffer[:0], majorSimple<<5|simpleFloat16), half)
+
+			return e.writer.WriteBytes(e.buffer)
+		}
+	}
+
+	e.buffer = binutils.AppendUint32(append(e.buffer[:0], majorSimple<<5|simpleFloat32), math.Float32bits(value))
+
+	return e.writer.WriteBytes(e.buffer)
+}
+
+// EncodeFloat64 writes float64 value, using the shortest form preserving value in deterministic mode.
+func (e *Encoder) EncodeFloat64(value float64) error {
+	if e.deterministic && (float64(float32(value)) == value || math.IsNaN(value)) {
+		return e.EncodeFloat32(float32(value))
+	}
+
+	e.buffer = binutils.AppendUint64(append(e.buffer[:0], majorSimple<<5|simpleFloat64), math.Float64bits(value))
+
+	return e.writer.WriteBytes(e.buffer)
+}
+
+// EncodeTime writes time as epoch-based date/time tag.
+// Integer seconds used if time has no fractional part, float otherwise.
+func (e *Encoder) EncodeTime(value time.Time) error {
+	if err := e.EncodeTag(TagEpochTime); err != nil {
+		return err
+	}
+
+	if value.Nanosecond() == 0 {
+		return e.EncodeInt(value.Unix())
+	}
+
+	return e.EncodeFloat64(float64(value.UnixNano()) / float64(time.Second))
+}
+
+// Encode writes any supported value.
+// Supported values are nil, bool, integers, floats, strings, []byte, *big.Int, time.Time, Tag, Simple, Undefined
+// and arrays, slices, maps, structs or pointers of supported values.
+// Structs are encoded as maps keyed by field names, use `cbor:"name"` field tag
+// to override name, `cbor:"-"` to skip field or `cbor:",omitempty"` to skip field having zero value.
+func (e *Encoder) Encode(value interface{}) error {
+	switch typed := value.(type) {
+	case nil:
+		return e.EncodeNil()
+	case bool:
+		return e.EncodeBool(typed)
+	case int:
+		return e.EncodeInt(int64(typed))
+	case int64:
+		return e.EncodeInt(typed)
+	case uint64:
+		return e.EncodeUint(typed)
+	case float32:
+		return e.EncodeFloat32(typed)
+	case float64:
+		return e.EncodeFloat64(typed)
+	case string:
+		return e.EncodeString(typed)
+	case []byte:
+		return e.EncodeBytes(typed)
+	case *big.Int:
+		if typed == nil {
+			return e.EncodeNil()
+		}
+
+		return e.EncodeBigInt(typed)
+	case big.Int:
+		return e.EncodeBigInt(&typed)
+	case time.Time:
+		return e.EncodeTime(typed)
+	case Tag:
+		if err := e.EncodeTag(typed.Number); err != nil {
+			return err
+		}
+
+		return e.Encode(typed.Content)
+	case Simple:
+		return e.EncodeSimple(typed)
+	case Undefined:
+		return e.EncodeUndefined()
+	default:
+		return e.encodeValue(reflect.ValueOf(value))
+	}
+}
+
+// encodeValue writes value using reflection.
+func (e *Encoder) encodeValue(value reflect.Value) error {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return e.EncodeNil()
+		}
+
+		return e.Encode(value.Elem().Interface())
+	case reflect.Bool:
+		return e.EncodeBool(value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return e.EncodeInt(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return e.EncodeUint(value.Uint())
+	case reflect.Float32:
+		return e.EncodeFloat32(float32(value.Float()))
+	case reflect.Float64:
+		return e.EncodeFloat64(value.Float())
+	case reflect.String:
+		return e.EncodeString(value.String())
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			data := make([]byte, value.Len())
+			reflect.Copy(reflect.ValueOf(data), value)
+
+			return e.EncodeBytes(data)
+		}
+
+		if value.Kind() == reflect.Slice && value.IsNil() {
+			return e.EncodeNil()
+		}
+
+		if err := e.EncodeArrayLen(value.Len()); err != nil {
+			return err
+		}
+
+		for idx := 0; idx < value.Len(); idx++ {
+			if err := e.encodeValue(value.Index(idx)); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	case reflect.Map:
+		if value.IsNil() {
+			return e.EncodeNil()
+		}
+
+		pairs := make([]reflect.Value, 0, value.Len()*2)
+		iterator := value.MapRange()
+
+		for iterator.Next() {
+			pairs = append(pairs, iterator.Key(), iterator.Value())
+		}
+
+		return e.encodePairs(pairs)
+	case reflect.Struct:
+		if value.Type() == reflect.TypeOf(time.Time{}) || value.Type() == reflect.TypeOf(big.Int{}) {
+			return e.Encode(value.Interface())
+		}
+
+		fields := structFields(value.Type())
+		pairs := make([]reflect.Value, 0, len(fields)*2)
+
+		for _, field := range fields {
+			if fieldValue := value.Field(field.index); !field.omitEmpty || !fieldValue.IsZero() {
+				pairs = append(pairs, reflect.ValueOf(field.name), fieldValue)
+			}
+		}
+
+		return e.encodePairs(pairs)
+	default:
+		return fmt.Errorf("%w: %v", ErrUnsupportedType, value.Type())
+	}
+}
+
+// encodedPair holds encoded map key and value.
+type encodedPair struct {
+	key   []byte
+	value []byte
+}
+
+// encodePairs writes map of flattened key and value pairs.
+// In deterministic mode pairs are sorted by encoded keys bytes.
+func (e *Encoder) encodePairs(pairs []reflect.Value) error {
+	if err := e.EncodeMapLen(len(pairs) / 2); err != nil {
+		return err
+	}
+
+	if !e.deterministic {
+		for _, item := range pairs {
+			if err := e.encodeValue(item); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	encoded := make([]encodedPair, len(pairs)/2)
+
+	for idx := range encoded {
+		buffer := new(bytes.Buffer)
+		nested := NewEncoder(binutils.NewBinaryWriter(buffer))
+		nested.deterministic = true
+
+		if err := nested.encodeValue(pairs[idx*2]); err != nil {
+			return err
+		}
+
+		keySize := buffer.Len()
+
+		if err := nested.encodeValue(pairs[idx*2+1]); err != nil {
+			return err
+		}
+
+		encoded[idx] = encodedPair{key: buffer.Bytes()[:keySize], value: buffer.Bytes()[keySize:]}
+	}
+
+	sort.Slice(encoded, func(i, j int) bool { return bytes.Compare(encoded[i].key, encoded[j].key) < 0 })
+
+	for idx, pair := range encoded {
+		if idx > 0 && bytes.Equal(encoded[idx-1].key, pair.key) {
+			return fmt.Errorf("%w: duplicate map key", ErrUnsupportedType)
+		}
+
+		if err := e.writer.WriteBytes(pair.key); err != nil {
+			return err
+		}
+
+		if err := e.writer.WriteBytes(pair.value); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// structField describes encoded struct field.
+type structField struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+// structFields returns exported struct fields not skipped by `cbor:"-"` tag.
+// Tag value is a field name optionally followed by comma separated options,
+// omitempty option skips field having zero value, other options are ignored.
+func structFields(structType reflect.Type) (fields []structField) {
+	for idx := 0; idx < structType.NumField(); idx++ {
+		field := structType.Field(idx)
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+
+		options := strings.Split(field.Tag.Get("cbor"), ",")
+		name := options[0]
+
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+
+		result := structField{name: name, index: idx}
+		for _, option := range options[1:] {
+			result.omitEmpty = result.omitEmpty || option == "omitempty"
+		}
+
+		fields = append(fields, result)
+	}
+
+	return fields
+}
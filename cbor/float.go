@@ -0,0 +1,74 @@
+package cbor
+
+import (
+	"math"
+)
+
+// float16NaN is canonical half precision NaN.
+const float16NaN uint16 = 0x7e00
+
+// float16Bits returns half precision bits of value if value could be represented exactly.
+func float16Bits(value float32) (uint16, bool) {
+	bits := math.Float32bits(value)
+	sign := uint16(bits>>16) & 0x8000
+	exponent := int(bits>>23) & 0xff
+	mantissa := bits & 0x7fffff
+
+	switch {
+	case exponent == 0xff && mantissa != 0:
+		return float16NaN, true
+	case exponent == 0xff:
+		return sign | 0x7c00, true
+	case exponent == 0 && mantissa == 0:
+		return sign, true
+	case exponent == 0: // float32 subnormals are too small for half precision
+		return 0, false
+	}
+
+	halfExponent := exponent - 127 + 15
+
+	switch {
+	case halfExponent >= 0x1f:
+		return 0, false
+	case halfExponent <= 0: // half precision subnormal
+		full := mantissa | 0x800000
+		shift := uint(14 - halfExponent)
+
+		if shift > 24 || full&(1<<shift-1) != 0 {
+			return 0, false
+		}
+
+		return sign | uint16(full>>shift), true
+	case mantissa&0x1fff != 0:
+		return 0, false
+	default:
+		return sign | uint16(halfExponent)<<10 | uint16(mantissa>>13), true
+	}
+}
+
+// float16Value converts half precision bits into float64.
+func float16Value(bits uint16) float64 {
+	exponent := int(bits>>10) & 0x1f
+	mantissa := float64(bits & 0x3ff)
+
+	var value float64
+
+	switch exponent {
+	case 0:
+		value = math.Ldexp(mantissa, -24)
+	case 0x1f:
+		if mantissa == 0 {
+			value = math.Inf(1)
+		} else {
+			value = math.NaN()
+		}
+	default:
+		value = math.Ldexp(mantissa+1024, exponent-25)
+	}
+
+	if bits&0x8000 != 0 {
+		return -value
+	}
+
+	return value
+}
@@ -0,0 +1,72 @@
+// Package chunk implements chunk based containers such as RIFF, IFF and PNG
+// on top of binutils.BinaryReader and binutils.BinaryWriter.
+//
+// Every chunk consists of four character code identifier, 32-bit payload size, payload,
+// and optionally CRC-32 and pad byte depending on Format.
+// Writer back-fills chunk sizes automatically, so payload could be written without knowing its size in advance.
+// Reader iterates over chunks providing bounded payload readers and supports nested LIST-like chunks.
+package chunk
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/amarin/binutils"
+)
+
+// Format defines chunk layout conventions.
+type Format struct {
+	ByteOrder binary.ByteOrder // size field byte order
+	SizeFirst bool             // size field precedes identifier, as in PNG
+	CRC       bool             // CRC-32 of identifier and payload follows payload, as in PNG
+	Pad       bool             // odd sized payload is followed by pad byte, as in RIFF and IFF
+}
+
+// Predefined formats.
+var (
+	// RIFF is a little-endian Resource Interchange File Format used by WAV, AVI and WebP.
+	RIFF = Format{ByteOrder: binary.LittleEndian, Pad: true} // nolint:gochecknoglobals
+
+	// IFF is a big-endian Interchange File Format used by AIFF and ILBM.
+	IFF = Format{ByteOrder: binary.BigEndian, Pad: true} // nolint:gochecknoglobals
+
+	// PNG is a big-endian chunks layout having length first and CRC-32 after payload.
+	PNG = Format{ByteOrder: binary.BigEndian, SizeFirst: true, CRC: true} // nolint:gochecknoglobals
+)
+
+// FourCC is a four character code chunk identifier.
+type FourCC [4]byte
+
+// ID makes FourCC from text. Text is truncated or padded with spaces to 4 bytes.
+func ID(text string) FourCC {
+	id := FourCC{' ', ' ', ' ', ' '}
+	copy(id[:], text)
+
+	return id
+}
+
+// String returns identifier text.
+func (id FourCC) String() string {
+	return string(id[:])
+}
+
+// headerSize is identifier and size fields length.
+const headerSize = 8
+
+// Some predefined errors used during processing.
+var (
+	// Error indicates any chunk errors.
+	Error = fmt.Errorf("%w: chunk", binutils.Error)
+
+	// ErrCRC returned if chunk CRC-32 mismatch.
+	ErrCRC = fmt.Errorf("%w: crc mismatch", Error)
+
+	// ErrOverflow returned if chunk payload size does not fit size field or exceeds limit.
+	ErrOverflow = fmt.Errorf("%w: size overflow", Error)
+
+	// ErrMalformed returned if chunk size exceeds its parent chunk.
+	ErrMalformed = fmt.Errorf("%w: malformed chunk", Error)
+
+	// ErrNesting returned if chunk writing is not properly nested.
+	ErrNesting = fmt.Errorf("%w: invalid nesting", Error)
+)
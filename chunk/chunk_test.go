@@ -0,0 +1,224 @@
+package chunk_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"image"
+	"image/png"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amarin/binutils"
+	"github.com/amarin/binutils/chunk"
+)
+
+// seekBuffer is an in-memory io.WriteSeeker.
+type seekBuffer struct {
+	data     []byte
+	position int
+}
+
+func (s *seekBuffer) Write(p []byte) (int, error) {
+	if missing := s.position + len(p) - len(s.data); missing > 0 {
+		s.data = append(s.data, make([]byte, missing)...)
+	}
+
+	copy(s.data[s.position:], p)
+	s.position += len(p)
+
+	return len(p), nil
+}
+
+func (s *seekBuffer) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+		s.position = int(offset)
+	case io.SeekCurrent:
+		s.position += int(offset)
+	default:
+		s.position = len(s.data) + int(offset)
+	}
+
+	return int64(s.position), nil
+}
+
+func writeWave(t *testing.T, writer *chunk.Writer) {
+	require.NoError(t, writer.BeginList(chunk.ID("RIFF"), chunk.ID("WAVE")))
+	require.NoError(t, writer.WriteChunk(chunk.ID("fmt"), []byte{1, 0}))
+
+	data, err := writer.Begin(chunk.ID("data"))
+	require.NoError(t, err)
+	require.NoError(t, data.WriteUint16(0x0102)) // format byte order
+	require.NoError(t, data.WriteUint8(3))
+	require.Equal(t, 2, writer.Depth())
+	require.NoError(t, writer.End())
+	require.NoError(t, writer.End())
+	require.Equal(t, 0, writer.Depth())
+}
+
+func TestWriter_RIFF(t *testing.T) {
+	expected := "52494646" + "1a000000" + "57415645" +
+		"666d7420" + "02000000" + "0100" +
+		"64617461" + "03000000" + "020103" + "00"
+
+	buffer := new(bytes.Buffer)
+	writeWave(t, chunk.NewWriter(buffer, chunk.RIFF))
+	require.Equal(t, expected, hex.EncodeToString(buffer.Bytes()))
+
+	seekable := new(seekBuffer)
+	writeWave(t, chunk.NewWriter(seekable, chunk.RIFF))
+	require.Equal(t, expected, hex.EncodeToString(seekable.data))
+}
+
+func TestWriter_IFF(t *testing.T) {
+	seekable := new(seekBuffer)
+	writer := chunk.NewWriter(seekable, chunk.IFF)
+	require.NoError(t, writer.BeginList(chunk.ID("FORM"), chunk.ID("AIFF")))
+	require.NoError(t, writer.WriteChunk(chunk.ID("NAME"), []byte("abc")))
+	require.NoError(t, writer.End())
+	require.Equal(t, "464f524d"+"00000010"+"41494646"+"4e414d45"+"00000003"+"616263"+"00",
+		hex.EncodeToString(seekable.data))
+}
+
+func TestReader_RIFF(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	writeWave(t, chunk.NewWriter(buffer, chunk.RIFF))
+	require.NoError(t, chunk.NewWriter(buffer, chunk.RIFF).WriteChunk(chunk.ID("JUNK"), []byte{9}))
+
+	reader := chunk.NewReader(binutils.NewBinaryReader(buffer), chunk.RIFF)
+
+	riff, err := reader.Next()
+	require.NoError(t, err)
+	require.Equal(t, "RIFF", riff.ID.String())
+	require.Equal(t, 26, riff.Size)
+
+	formType, nested, err := riff.List()
+	require.NoError(t, err)
+	require.Equal(t, chunk.ID("WAVE"), formType)
+
+	format, err := nested.Next()
+	require.NoError(t, err)
+	require.Equal(t, chunk.ID("fmt "), format.ID)
+	require.Equal(t, 4, format.Offset) // relative to RIFF payload start
+
+	data, err := nested.Next() // fmt payload skipped
+	require.NoError(t, err)
+	require.Equal(t, chunk.ID("data"), data.ID)
+
+	value, err := data.Value.ReadUint16()
+	require.NoError(t, err)
+	require.Equal(t, uint16(0x0102), value)
+
+	_, err = nested.Next() // pad byte skipped
+	require.True(t, errors.Is(err, io.EOF))
+
+	junk, err := reader.Next()
+	require.NoError(t, err)
+	require.Equal(t, chunk.ID("JUNK"), junk.ID)
+	require.Equal(t, 34, junk.Offset)
+
+	payload, err := junk.Bytes()
+	require.NoError(t, err)
+	require.Equal(t, []byte{9}, payload)
+
+	_, err = reader.Next() // missing last pad byte tolerated
+	require.True(t, errors.Is(err, io.EOF))
+}
+
+func TestReader_PNG(t *testing.T) {
+	encoded := new(bytes.Buffer)
+	require.NoError(t, png.Encode(encoded, image.NewGray(image.Rect(0, 0, 2, 2))))
+
+	source := binutils.NewBinaryReader(bytes.NewReader(encoded.Bytes()))
+	signature, err := source.ReadBytesCount(8)
+	require.NoError(t, err)
+
+	rewritten := bytes.NewBuffer(signature)
+	writer := chunk.NewWriter(rewritten, chunk.PNG)
+	reader := chunk.NewReader(source, chunk.PNG)
+
+	var ids []string
+
+	for {
+		item, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		require.NoError(t, err)
+
+		payload, err := item.Bytes()
+		require.NoError(t, err)
+
+		if item.ID == chunk.ID("IHDR") {
+			require.Equal(t, []byte{0, 0, 0, 2}, payload[:4]) // width
+		}
+		require.NoError(t, writer.WriteChunk(item.ID, payload))
+
+		ids = append(ids, item.ID.String())
+	}
+
+	require.Equal(t, []string{"IHDR", "IDAT", "IEND"}, ids)
+	require.Equal(t, encoded.Bytes(), rewritten.Bytes())
+
+	seekable := new(seekBuffer)
+	require.NoError(t, chunk.NewWriter(seekable, chunk.PNG).WriteChunk(chunk.ID("IEND"), nil))
+	require.Equal(t, encoded.Bytes()[encoded.Len()-12:], seekable.data)
+}
+
+func TestReader_Errors(t *testing.T) {
+	corrupted := new(bytes.Buffer)
+	require.NoError(t, chunk.NewWriter(corrupted, chunk.PNG).WriteChunk(chunk.ID("tEXt"), []byte("abc")))
+	corrupted.Bytes()[9] = 'x'
+
+	_, err := chunk.NewReader(binutils.NewBinaryReader(corrupted), chunk.PNG).Next()
+	require.True(t, errors.Is(err, chunk.ErrCRC), err)
+
+	large := chunk.NewReader(binutils.NewBinaryReader(bytes.NewReader([]byte{0, 0, 1, 0, 'I', 'D', 'A', 'T'})), chunk.PNG)
+	large.SetMaxBufferedSize(16)
+	_, err = large.Next()
+	require.True(t, errors.Is(err, chunk.ErrOverflow), err)
+
+	truncated := chunk.NewReader(binutils.NewBinaryReader(bytes.NewReader([]byte{0, 0, 0, 5, 'I', 'D', 'A', 'T', 1})), chunk.PNG)
+	_, err = truncated.Next()
+	require.True(t, errors.Is(err, io.ErrUnexpectedEOF), err)
+
+	oversized := chunk.NewReader(binutils.NewBinaryReader(bytes.NewReader([]byte{
+		'd', 'a', 't', 'a', 0xff, 0xff, 0xff, 0xff, 1, 2,
+	})), chunk.RIFF)
+	data, err := oversized.Next()
+	require.NoError(t, err)
+	_, err = data.Bytes()
+	require.True(t, errors.Is(err, io.ErrUnexpectedEOF), err)
+
+	outer := chunk.NewReader(binutils.NewBinaryReader(bytes.NewReader([]byte{
+		'L', 'I', 'S', 'T', 12, 0, 0, 0, 'I', 'N', 'F', 'O', 'I', 'N', 'A', 'M', 9, 0, 0, 0,
+	})), chunk.RIFF)
+	list, err := outer.Next()
+	require.NoError(t, err)
+
+	_, nested, err := list.List()
+	require.NoError(t, err)
+
+	_, err = nested.Next()
+	require.True(t, errors.Is(err, chunk.ErrMalformed), err)
+}
+
+func TestWriter_Nesting(t *testing.T) {
+	writer := chunk.NewWriter(new(bytes.Buffer), chunk.RIFF)
+	require.True(t, errors.Is(writer.End(), chunk.ErrNesting))
+
+	outer, err := writer.Begin(chunk.ID("LIST"))
+	require.NoError(t, err)
+
+	_, err = writer.Begin(chunk.ID("data"))
+	require.NoError(t, err)
+	require.True(t, errors.Is(outer.WriteUint8(1), chunk.ErrNesting))
+	require.NoError(t, writer.End())
+	require.NoError(t, outer.WriteUint8(1))
+	require.NoError(t, writer.End())
+	require.True(t, errors.Is(outer.WriteUint8(1), chunk.ErrNesting))
+}
@@ -0,0 +1,161 @@
+package chunk
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/amarin/binutils"
+)
+
+// DefaultMaxBufferedSize is a default maximum payload size of chunks buffered to verify CRC.
+const DefaultMaxBufferedSize = binutils.DefaultMaxFrameSize
+
+// Chunk represents single chunk taken by Reader.
+type Chunk struct {
+	ID    FourCC                 // chunk identifier
+	Size  int                    // payload size
+	Value *binutils.BinaryReader // payload reader using format byte order
+	// Offset is an offset of chunk header in the reader chunks taken from.
+	// For nested chunks it is relative to parent payload start.
+	Offset int
+	format Format
+}
+
+// Bytes reads chunk payload bytes not taken yet.
+// Payload buffer grows while data is read, so size field of truncated input fails on read, not on allocation.
+func (c *Chunk) Bytes() ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	if _, err := buffer.ReadFrom(c.Value); err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+// List reads form type from the first payload bytes and returns Reader over nested chunks.
+// Use it for LIST-like chunks such as RIFF, LIST or FORM.
+func (c *Chunk) List() (formType FourCC, nested *Reader, err error) {
+	if err = c.Value.ReadBytesInto(formType[:]); err != nil {
+		return formType, nil, err
+	}
+
+	return formType, NewReader(c.Value, c.format), nil
+}
+
+// Reader iterates over chunks taken from binutils.BinaryReader.
+type Reader struct {
+	reader          *binutils.BinaryReader
+	format          Format
+	maxBufferedSize int
+	current         *Chunk
+	buffer          [headerSize]byte
+}
+
+// NewReader creates Reader taking chunks of specified format from reader.
+func NewReader(reader *binutils.BinaryReader, format Format) *Reader {
+	return &Reader{reader: reader, format: format, maxBufferedSize: DefaultMaxBufferedSize}
+}
+
+// SetMaxBufferedSize sets maximum payload size of chunks buffered to verify CRC.
+func (r *Reader) SetMaxBufferedSize(size int) {
+	r.maxBufferedSize = size
+}
+
+// Next skips unread payload and pad byte of previous chunk if any and reads next chunk header.
+// If format requires CRC, chunk payload is read into memory and verified before return.
+// Returns io.EOF if no more chunks available.
+func (r *Reader) Next() (*Chunk, error) {
+	if r.current != nil {
+		if err := r.skip(r.current); err != nil {
+			return nil, err
+		}
+
+		r.current = nil
+	}
+
+	offset := r.reader.BytesTaken()
+
+	header := r.buffer[:]
+	if err := r.reader.ReadBytesInto(header); err != nil {
+		return nil, err
+	}
+
+	idField, sizeField := header[:4], header[4:]
+	if r.format.SizeFirst {
+		sizeField, idField = header[:4], header[4:]
+	}
+
+	chunk := &Chunk{Size: int(r.format.ByteOrder.Uint32(sizeField)), Offset: offset, format: r.format}
+	copy(chunk.ID[:], idField)
+
+	if remaining := r.reader.Remaining(); remaining >= 0 && chunk.Size > remaining {
+		return nil, fmt.Errorf("%w: chunk %v size %d exceeds %d bytes left", ErrMalformed, chunk.ID, chunk.Size, remaining)
+	}
+
+	if r.format.CRC {
+		if err := r.verify(chunk); err != nil {
+			return nil, err
+		}
+	} else {
+		chunk.Value = r.reader.Sub(chunk.Size)
+	}
+
+	chunk.Value.SetByteOrder(r.format.ByteOrder)
+	r.current = chunk
+
+	return chunk, nil
+}
+
+// verify reads chunk payload and CRC-32, checks CRC and sets chunk value reader over payload read.
+func (r *Reader) verify(chunk *Chunk) error {
+	if chunk.Size > r.maxBufferedSize {
+		return fmt.Errorf("%w: chunk %v size %d exceeds %d", ErrOverflow, chunk.ID, chunk.Size, r.maxBufferedSize)
+	}
+
+	payload := make([]byte, chunk.Size+4)
+	if err := r.reader.ReadBytesInto(payload); err != nil {
+		return noEOF(err)
+	}
+
+	payload, sum := payload[:chunk.Size], r.format.ByteOrder.Uint32(payload[chunk.Size:])
+
+	hash := crc32.NewIEEE()
+	_, _ = hash.Write(chunk.ID[:]) // never returns error
+	_, _ = hash.Write(payload)     // never returns error
+
+	if hash.Sum32() != sum {
+		return fmt.Errorf("%w: chunk %v at offset %d", ErrCRC, chunk.ID, chunk.Offset)
+	}
+
+	chunk.Value = binutils.NewBinaryReader(bytes.NewReader(payload)).Sub(chunk.Size)
+
+	return nil
+}
+
+// skip skips unread chunk payload and pad byte.
+func (r *Reader) skip(chunk *Chunk) error {
+	if _, err := chunk.Value.Drain(); err != nil {
+		return err
+	}
+
+	if r.format.Pad && chunk.Size%2 == 1 {
+		// tolerate missing pad byte of the last chunk
+		if _, err := r.reader.ReadUint8(); err != nil && !errors.Is(err, io.EOF) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// noEOF converts io.EOF into io.ErrUnexpectedEOF.
+func noEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+
+	return err
+}
@@ -0,0 +1,235 @@
+package chunk
+
+import (
+	"bytes"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"math"
+
+	"github.com/amarin/binutils"
+)
+
+// openChunk describes chunk being written.
+type openChunk struct {
+	id     FourCC
+	size   int64         // payload bytes written
+	crc    hash.Hash32   // CRC-32 of identifier and payload if required by format
+	buffer *bytes.Buffer // payload buffer, nil if payload written directly into seekable output
+	offset int64         // size field offset in seekable output if payload written directly
+}
+
+// Writer writes chunks into underlying writer.
+// If underlying writer implements io.WriteSeeker, payloads are written directly and sizes are back-filled,
+// otherwise every chunk payload is buffered until End.
+type Writer struct {
+	out    io.Writer
+	seeker io.WriteSeeker
+	format Format
+	stack  []*openChunk
+	buffer [headerSize]byte
+}
+
+// NewWriter creates Writer writing chunks of specified format into writer.
+func NewWriter(writer io.Writer, format Format) *Writer {
+	seeker, _ := writer.(io.WriteSeeker)
+
+	return &Writer{out: writer, seeker: seeker, format: format}
+}
+
+// Depth returns number of chunks started but not ended yet.
+func (w *Writer) Depth() int {
+	return len(w.stack)
+}
+
+// emit writes bytes into innermost open chunk payload or underlying writer if no chunks open.
+func (w *Writer) emit(data []byte) error {
+	if len(w.stack) == 0 {
+		_, err := w.out.Write(data)
+		return err
+	}
+
+	top := w.stack[len(w.stack)-1]
+	top.size += int64(len(data))
+
+	if top.crc != nil {
+		_, _ = top.crc.Write(data) // never returns error
+	}
+
+	if top.buffer != nil {
+		_, err := top.buffer.Write(data)
+		return err
+	}
+
+	_, err := w.out.Write(data)
+
+	return err
+}
+
+// header returns identifier and size fields.
+func (w *Writer) header(id FourCC, size uint32) []byte {
+	idField, sizeField := w.buffer[:4], w.buffer[4:]
+	if w.format.SizeFirst {
+		sizeField, idField = w.buffer[:4], w.buffer[4:]
+	}
+
+	copy(idField, id[:])
+	w.format.ByteOrder.PutUint32(sizeField, size)
+
+	return w.buffer[:]
+}
+
+// Begin starts chunk having specified identifier and returns writer of its payload.
+// Payload writer uses format byte order and becomes invalid once chunk ended.
+// Nested chunks could be started before End to build LIST-like chunks.
+func (w *Writer) Begin(id FourCC) (*binutils.BinaryWriter, error) {
+	chunk := &openChunk{id: id}
+	if w.format.CRC {
+		chunk.crc = crc32.NewIEEE()
+		_, _ = chunk.crc.Write(id[:]) // never returns error
+	}
+
+	// chunk is written directly if its size could be back-filled and parent does not need whole payload
+	direct := w.seeker != nil &&
+		(len(w.stack) == 0 || (w.stack[len(w.stack)-1].buffer == nil && !w.format.CRC))
+
+	if !direct {
+		chunk.buffer = new(bytes.Buffer)
+	} else {
+		position, err := w.seeker.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, err
+		}
+
+		chunk.offset = position
+		if !w.format.SizeFirst {
+			chunk.offset += 4
+		}
+
+		if err = w.emit(w.header(id, 0)); err != nil {
+			return nil, err
+		}
+	}
+
+	w.stack = append(w.stack, chunk)
+
+	writer := binutils.NewBinaryWriter(&payloadWriter{writer: w, chunk: chunk})
+	writer.SetByteOrder(w.format.ByteOrder)
+
+	return writer, nil
+}
+
+// BeginList starts chunk having specified identifier, such as RIFF, LIST or FORM,
+// and writes form type as the first payload bytes. Nested chunks should be written next.
+func (w *Writer) BeginList(id FourCC, formType FourCC) error {
+	if _, err := w.Begin(id); err != nil {
+		return err
+	}
+
+	return w.emit(formType[:])
+}
+
+// End finishes innermost open chunk writing its size, CRC and pad byte if required by format.
+func (w *Writer) End() error {
+	if len(w.stack) == 0 {
+		return fmt.Errorf("%w: no open chunk", ErrNesting)
+	}
+
+	chunk := w.stack[len(w.stack)-1]
+	if chunk.size > math.MaxUint32 {
+		return fmt.Errorf("%w: chunk %v size %d", ErrOverflow, chunk.id, chunk.size)
+	}
+
+	w.stack = w.stack[:len(w.stack)-1]
+
+	if chunk.buffer != nil {
+		if err := w.emit(w.header(chunk.id, uint32(chunk.size))); err != nil {
+			return err
+		}
+
+		if err := w.emit(chunk.buffer.Bytes()); err != nil {
+			return err
+		}
+	} else if err := w.backFill(chunk); err != nil {
+		return err
+	}
+
+	if chunk.crc != nil {
+		sum := w.buffer[:4]
+		w.format.ByteOrder.PutUint32(sum, chunk.crc.Sum32())
+
+		if err := w.emit(sum); err != nil {
+			return err
+		}
+	}
+
+	if w.format.Pad && chunk.size%2 == 1 {
+		return w.emit([]byte{0})
+	}
+
+	return nil
+}
+
+// backFill writes size of chunk written directly into seekable output and accounts its payload in parent.
+func (w *Writer) backFill(chunk *openChunk) error {
+	position, err := w.seeker.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return err
+	}
+
+	if _, err = w.seeker.Seek(chunk.offset, io.SeekStart); err != nil {
+		return err
+	}
+
+	size := w.buffer[:4]
+	w.format.ByteOrder.PutUint32(size, uint32(chunk.size))
+
+	if _, err = w.seeker.Write(size); err != nil {
+		return err
+	}
+
+	if _, err = w.seeker.Seek(position, io.SeekStart); err != nil {
+		return err
+	}
+
+	if len(w.stack) > 0 { // parent is written directly too, so payload already there
+		w.stack[len(w.stack)-1].size += chunk.size
+	}
+
+	return nil
+}
+
+// WriteChunk writes whole chunk having specified identifier and payload.
+func (w *Writer) WriteChunk(id FourCC, payload []byte) error {
+	writer, err := w.Begin(id)
+	if err != nil {
+		return err
+	}
+
+	if err = writer.WriteBytes(payload); err != nil {
+		return err
+	}
+
+	return w.End()
+}
+
+// payloadWriter writes chunk payload.
+type payloadWriter struct {
+	writer *Writer
+	chunk  *openChunk
+}
+
+// Write writes payload bytes if chunk is the innermost open one. Implements io.Writer.
+func (p *payloadWriter) Write(data []byte) (int, error) {
+	stack := p.writer.stack
+	if len(stack) == 0 || stack[len(stack)-1] != p.chunk {
+		return 0, fmt.Errorf("%w: chunk %v is not the innermost open one", ErrNesting, p.chunk.id)
+	}
+
+	if err := p.writer.emit(data); err != nil {
+		return 0, err
+	}
+
+	return len(data), nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// errDiffer returned by diff command if files differ.
+var errDiffer = errors.New("files differ")
+
+// diffBytesPerLine defines bytes count rendered per diff line.
+const diffBytesPerLine = 16
+
+// diffHunk defines differing bytes range extended by context.
+type diffHunk struct {
+	start int // hunk start offset
+	end   int // hunk end offset, exclusive
+}
+
+// runDiff implements diff command rendering byte-level differences of two files.
+func runDiff(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("diff", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	context := flags.Int("context", diffBytesPerLine, "equal bytes count shown around differences")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	if flags.NArg() != 2 || *context < 0 {
+		return fmt.Errorf("%w: diff requires non-negative -context and two FILE arguments", errUsage)
+	}
+
+	first, err := ioutil.ReadFile(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	second, err := ioutil.ReadFile(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+
+	hunks := diffHunks(first, second, *context)
+	if len(hunks) == 0 {
+		return nil
+	}
+
+	output := bufio.NewWriter(stdout)
+	fmt.Fprintf(output, "--- %s (%d bytes)\n+++ %s (%d bytes)\n", flags.Arg(0), len(first), flags.Arg(1), len(second))
+
+	for _, hunk := range hunks {
+		writeDiffHunk(output, first, second, hunk)
+	}
+
+	if err = output.Flush(); err != nil {
+		return err
+	}
+
+	return errDiffer
+}
+
+// diffHunks returns ranges of differing bytes extended by context bytes around.
+// Bytes beyond the end of shorter data are differing. Hunks closer than two contexts are merged.
+func diffHunks(first, second []byte, context int) (hunks []diffHunk) {
+	size := len(first)
+	if len(second) > size {
+		size = len(second)
+	}
+
+	for offset := 0; offset < size; offset++ {
+		if offset < len(first) && offset < len(second) && first[offset] == second[offset] {
+			continue
+		}
+
+		start, end := offset-context, offset+1+context
+		if start < 0 {
+			start = 0
+		}
+
+		if end > size {
+			end = size
+		}
+
+		if last := len(hunks) - 1; last >= 0 && start <= hunks[last].end {
+			hunks[last].end = end
+			continue
+		}
+
+		hunks = append(hunks, diffHunk{start: start, end: end})
+	}
+
+	return hunks
+}
+
+// writeDiffHunk renders hunk lines of diffBytesPerLine bytes each.
+// Equal lines are prefixed by space, differing ones rendered as first data line prefixed by minus
+// followed by second data line prefixed by plus. Lines beyond the end of data are omitted.
+func writeDiffHunk(output io.Writer, first, second []byte, hunk diffHunk) {
+	fmt.Fprintf(output, "@@ 0x%08x,0x%08x @@\n", hunk.start, hunk.end)
+
+	for offset := hunk.start; offset < hunk.end; offset += diffBytesPerLine {
+		end := offset + diffBytesPerLine
+		if end > hunk.end {
+			end = hunk.end
+		}
+
+		firstLine, secondLine := diffLine(first, offset, end), diffLine(second, offset, end)
+		if len(firstLine) == end-offset && bytes.Equal(firstLine, secondLine) {
+			fmt.Fprintf(output, " %08x  %s\n", offset, diffHex(firstLine))
+			continue
+		}
+
+		if len(firstLine) > 0 {
+			fmt.Fprintf(output, "-%08x  %s\n", offset, diffHex(firstLine))
+		}
+
+		if len(secondLine) > 0 {
+			fmt.Fprintf(output, "+%08x  %s\n", offset, diffHex(secondLine))
+		}
+	}
+}
+
+// diffLine returns data[start:end] clipped by data length.
+func diffLine(data []byte, start, end int) []byte {
+	if start >= len(data) {
+		return nil
+	}
+
+	if end > len(data) {
+		end = len(data)
+	}
+
+	return data[start:end]
+}
+
+// diffHex returns data as space separated hex bytes.
+func diffHex(data []byte) string {
+	hexBytes := make([]string, len(data))
+	for idx, char := range data {
+		hexBytes[idx] = fmt.Sprintf("%02x", char)
+	}
+
+	return strings.Join(hexBytes, " ")
+}
@@ -0,0 +1,239 @@
+package main
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/amarin/binutils"
+)
+
+// fieldKind defines field value type.
+type fieldKind int
+
+// Supported field kinds.
+const (
+	kindUint fieldKind = iota
+	kindInt
+	kindFloat
+	kindUvarint
+	kindVarint
+	kindStringZ
+	kindHex
+)
+
+// field describes single typed value of field spec.
+type field struct {
+	name  string           // field type name as specified
+	kind  fieldKind        // value type
+	size  int              // fixed value size in bytes, 0 for variable size kinds
+	order binary.ByteOrder // multi-byte values byte order
+}
+
+// fixedKinds maps fixed size type names without byte order suffix to kind and size.
+var fixedKinds = map[string]struct {
+	kind fieldKind
+	size int
+}{
+	"u8":  {kindUint, binutils.Uint8size},
+	"i8":  {kindInt, binutils.Int8size},
+	"u16": {kindUint, binutils.Uint16size},
+	"i16": {kindInt, binutils.Int16size},
+	"u32": {kindUint, binutils.Uint32size},
+	"i32": {kindInt, binutils.Int32size},
+	"u64": {kindUint, binutils.Uint64size},
+	"i64": {kindInt, binutils.Int64size},
+	"f32": {kindFloat, binutils.Uint32size},
+	"f64": {kindFloat, binutils.Uint64size},
+}
+
+// parseFields parses comma separated field spec like u32be,strz,u16le*3 expanding repeated fields.
+func parseFields(spec string) (fields []field, err error) {
+	for _, item := range strings.Split(spec, ",") {
+		name, count := strings.TrimSpace(item), 1
+
+		if idx := strings.IndexByte(name, '*'); idx >= 0 {
+			if count, err = strconv.Atoi(name[idx+1:]); err != nil || count < 1 {
+				return nil, fmt.Errorf("%w: invalid repeat count in %q", errUsage, item)
+			}
+
+			name = name[:idx]
+		}
+
+		parsed, err := parseField(name)
+		if err != nil {
+			return nil, err
+		}
+
+		for ; count > 0; count-- {
+			fields = append(fields, parsed)
+		}
+	}
+
+	return fields, nil
+}
+
+// parseField parses single field type name.
+func parseField(name string) (field, error) {
+	parsed := field{name: name, order: binary.BigEndian}
+
+	switch {
+	case name == "uvarint":
+		parsed.kind = kindUvarint
+	case name == "varint":
+		parsed.kind = kindVarint
+	case name == "strz":
+		parsed.kind = kindStringZ
+	case strings.HasPrefix(name, "hex"):
+		size, err := strconv.Atoi(name[len("hex"):])
+		if err != nil || size < 1 {
+			return parsed, fmt.Errorf("%w: invalid hex field size in %q", errUsage, name)
+		}
+
+		parsed.kind, parsed.size = kindHex, size
+	default:
+		base := name
+
+		switch {
+		case strings.HasSuffix(name, "be"):
+			base = strings.TrimSuffix(name, "be")
+		case strings.HasSuffix(name, "le"):
+			base, parsed.order = strings.TrimSuffix(name, "le"), binary.LittleEndian
+		}
+
+		fixed, ok := fixedKinds[base]
+		if !ok || (base != name && fixed.size == 1) {
+			return parsed, fmt.Errorf("%w: unknown field type %q", errUsage, name)
+		}
+
+		parsed.kind, parsed.size = fixed.kind, fixed.size
+	}
+
+	return parsed, nil
+}
+
+// read takes field value from reader returning its text representation.
+func (f field) read(reader *binutils.BinaryReader) (string, error) {
+	reader.SetByteOrder(f.order)
+
+	switch f.kind {
+	case kindUvarint:
+		value, err := reader.ReadUvarint()
+		return strconv.FormatUint(value, 10), err
+	case kindVarint:
+		value, err := reader.ReadVarint()
+		return strconv.FormatInt(value, 10), err
+	case kindStringZ:
+		value, err := reader.ReadStringZ()
+		return strconv.Quote(value), err
+	case kindHex:
+		return reader.ReadHex(f.size)
+	}
+
+	bits, err := f.readBits(reader)
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case f.kind == kindInt:
+		shift := uint(64 - 8*f.size) // sign extend
+		return strconv.FormatInt(int64(bits<<shift)>>shift, 10), nil
+	case f.kind == kindFloat && f.size == binutils.Uint32size:
+		return strconv.FormatFloat(float64(math.Float32frombits(uint32(bits))), 'g', -1, 32), nil
+	case f.kind == kindFloat:
+		return strconv.FormatFloat(math.Float64frombits(bits), 'g', -1, 64), nil
+	default:
+		return strconv.FormatUint(bits, 10), nil
+	}
+}
+
+// readBits takes fixed size field value as unsigned integer.
+func (f field) readBits(reader *binutils.BinaryReader) (uint64, error) {
+	switch f.size {
+	case binutils.Uint8size:
+		value, err := reader.ReadUint8()
+		return uint64(value), err
+	case binutils.Uint16size:
+		value, err := reader.ReadUint16()
+		return uint64(value), err
+	case binutils.Uint32size:
+		value, err := reader.ReadUint32()
+		return uint64(value), err
+	default:
+		return reader.ReadUint64()
+	}
+}
+
+// write puts field value parsed from text into writer.
+func (f field) write(writer *binutils.BinaryWriter, text string) error {
+	writer.SetByteOrder(f.order)
+
+	switch f.kind {
+	case kindUvarint:
+		value, err := strconv.ParseUint(text, 0, 64)
+		if err != nil {
+			return f.valueError(text, err)
+		}
+
+		return writer.WriteUvarint(value)
+	case kindVarint:
+		value, err := strconv.ParseInt(text, 0, 64)
+		if err != nil {
+			return f.valueError(text, err)
+		}
+
+		return writer.WriteVarint(value)
+	case kindStringZ:
+		return writer.WriteStringZ(text)
+	case kindHex:
+		if len(text) != 2*f.size {
+			return f.valueError(text, fmt.Errorf("expected %d hex digits", 2*f.size))
+		}
+
+		return writer.WriteHex(text)
+	}
+
+	bits, err := f.parseBits(text)
+	if err != nil {
+		return f.valueError(text, err)
+	}
+
+	switch f.size {
+	case binutils.Uint8size:
+		return writer.WriteUint8(uint8(bits))
+	case binutils.Uint16size:
+		return writer.WriteUint16(uint16(bits))
+	case binutils.Uint32size:
+		return writer.WriteUint32(uint32(bits))
+	default:
+		return writer.WriteUint64(bits)
+	}
+}
+
+// parseBits parses fixed size field value text into its unsigned integer bits.
+func (f field) parseBits(text string) (uint64, error) {
+	bitSize := 8 * f.size
+
+	switch f.kind {
+	case kindInt:
+		value, err := strconv.ParseInt(text, 0, bitSize)
+		return uint64(value), err
+	case kindFloat:
+		value, err := strconv.ParseFloat(text, bitSize)
+		if f.size == binutils.Uint32size {
+			return uint64(math.Float32bits(float32(value))), err
+		}
+
+		return math.Float64bits(value), err
+	default:
+		return strconv.ParseUint(text, 0, bitSize)
+	}
+}
+
+// valueError returns error describing invalid field value.
+func (f field) valueError(text string, err error) error {
+	return fmt.Errorf("%w: invalid %s value %q: %v", errUsage, f.name, text, err)
+}
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/amarin/binutils"
+)
+
+// hexdumpBytesPerLine defines bytes count rendered per hexdump line.
+const hexdumpBytesPerLine = 16
+
+// runHexdump implements hexdump command rendering file range as canonical hex+ASCII dump.
+func runHexdump(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("hexdump", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	offset := flags.Int64("offset", 0, "dump start offset")
+	length := flags.Int64("length", -1, "dump bytes limit, negative to dump until end of file")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	if flags.NArg() != 1 {
+		return fmt.Errorf("%w: hexdump requires single FILE argument", errUsage)
+	}
+
+	file, err := openAt(flags.Arg(0), *offset)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var source io.Reader = file
+	if *length >= 0 {
+		source = io.LimitReader(file, *length)
+	}
+
+	return hexdump(binutils.NewBinaryReader(source), *offset, stdout)
+}
+
+// openAt opens file for reading positioned at specified offset.
+func openAt(filename string, offset int64) (*os.File, error) {
+	if offset < 0 {
+		return nil, fmt.Errorf("%w: negative offset %d", errUsage, offset)
+	}
+
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = file.Seek(offset, io.SeekStart); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// hexdump renders reader data into stdout the same way as hexdump -C does.
+// Lines are prefixed by offset counted from start, repeated lines are collapsed into single asterisk line.
+func hexdump(reader *binutils.BinaryReader, start int64, stdout io.Writer) error {
+	output := bufio.NewWriter(stdout)
+	line := make([]byte, hexdumpBytesPerLine)
+	previous := make([]byte, 0, hexdumpBytesPerLine)
+	offset, collapsed := start, false
+
+	for {
+		count, err := io.ReadFull(reader, line)
+		if count == 0 {
+			if err != nil && !errors.Is(err, io.EOF) {
+				return err
+			}
+
+			break
+		}
+
+		if count == hexdumpBytesPerLine && bytes.Equal(line, previous) {
+			if !collapsed {
+				fmt.Fprintln(output, "*")
+				collapsed = true
+			}
+		} else {
+			fmt.Fprintln(output, hexdumpLine(offset, line[:count]))
+			collapsed = false
+		}
+
+		previous = append(previous[:0], line[:count]...)
+		offset += int64(count)
+
+		if err != nil {
+			if !errors.Is(err, io.ErrUnexpectedEOF) {
+				return err
+			}
+
+			break
+		}
+	}
+
+	if offset > start {
+		fmt.Fprintf(output, "%08x\n", offset)
+	}
+
+	return output.Flush()
+}
+
+// hexdumpLine renders single hexdump line, data must not exceed hexdumpBytesPerLine bytes.
+func hexdumpLine(offset int64, data []byte) string {
+	var builder strings.Builder
+
+	fmt.Fprintf(&builder, "%08x ", offset)
+
+	for idx := 0; idx < hexdumpBytesPerLine; idx++ {
+		if idx == hexdumpBytesPerLine/2 {
+			builder.WriteByte(' ')
+		}
+
+		if idx < len(data) {
+			fmt.Fprintf(&builder, " %02x", data[idx])
+		} else {
+			builder.WriteString("   ")
+		}
+	}
+
+	builder.WriteString("  |")
+
+	for _, char := range data {
+		if char < 0x20 || char > 0x7e {
+			char = '.'
+		}
+
+		builder.WriteByte(char)
+	}
+
+	builder.WriteByte('|')
+
+	return builder.String()
+}
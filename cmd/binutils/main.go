@@ -0,0 +1,58 @@
+// Command binutils inspects and produces binary files using binutils readers and writers.
+//
+// Usage:
+//
+//	binutils hexdump [-offset N] [-length N] FILE
+//	binutils read [-offset N] -fields SPEC FILE
+//	binutils write [-append] -fields SPEC FILE VALUE...
+//	binutils diff [-context N] FILE1 FILE2
+//
+// Field SPEC is comma separated list of field types, each optionally repeated by *COUNT suffix,
+// e.g. u32be,strz,u16le*3. Supported types are u8, i8, u16, i16, u32, i32, u64, i64, f32 and f64
+// with optional be (default) or le byte order suffix, uvarint, varint, strz and hexN for N raw bytes.
+//
+// Command diff exits with status 1 if files differ, any command exits with status 2 on error.
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// errUsage returned if command line arguments are invalid.
+var errUsage = errors.New("usage: binutils hexdump|read|write|diff [flags] args")
+
+// commands maps subcommand names to implementations.
+var commands = map[string]func(args []string, stdout io.Writer) error{
+	"hexdump": runHexdump,
+	"read":    runRead,
+	"write":   runWrite,
+	"diff":    runDiff,
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		if errors.Is(err, errDiffer) {
+			os.Exit(1)
+		}
+
+		fmt.Fprintln(os.Stderr, "binutils:", err)
+		os.Exit(2)
+	}
+}
+
+// run executes subcommand specified by the first argument writing its output into stdout.
+func run(args []string, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errUsage
+	}
+
+	command, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("%w: unknown command %q", errUsage, args[0])
+	}
+
+	return command(args[1:], stdout)
+}
@@ -0,0 +1,224 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// tempFile creates file with data in the temporary directory returning its path.
+func tempFile(t *testing.T, dir string, name string, data []byte) string {
+	filename := filepath.Join(dir, name)
+	require.NoError(t, ioutil.WriteFile(filename, data, 0o644))
+
+	return filename
+}
+
+// tempDir creates temporary directory returning its path and cleanup function.
+func tempDir(t *testing.T) (string, func()) {
+	dir, err := ioutil.TempDir("", "binutils")
+	require.NoError(t, err)
+
+	return dir, func() { require.NoError(t, os.RemoveAll(dir)) }
+}
+
+// runOutput runs command returning its output.
+func runOutput(args ...string) (string, error) {
+	output := new(strings.Builder)
+	err := run(args, output)
+
+	return output.String(), err
+}
+
+func TestRun_Usage(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		args []string
+	}{
+		{name: "no_command", args: nil},
+		{name: "unknown_command", args: []string{"dump"}},
+		{name: "unknown_flag", args: []string{"hexdump", "-width", "8", "file"}},
+		{name: "hexdump_no_file", args: []string{"hexdump"}},
+		{name: "read_no_fields", args: []string{"read", "file"}},
+		{name: "read_bad_spec", args: []string{"read", "-fields", "u24", "file"}},
+		{name: "read_bad_count", args: []string{"read", "-fields", "u8*0", "file"}},
+		{name: "read_u8_order", args: []string{"read", "-fields", "u8le", "file"}},
+		{name: "read_double_order", args: []string{"read", "-fields", "u32lebe", "file"}},
+		{name: "read_repeated_order", args: []string{"read", "-fields", "u32bebe", "file"}},
+		{name: "write_values_count", args: []string{"write", "-fields", "u8*2", "file", "1"}},
+		{name: "diff_single_file", args: []string{"diff", "file"}},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := runOutput(tt.args...)
+			require.True(t, errors.Is(err, errUsage), "unexpected error %v", err)
+		})
+	}
+}
+
+func TestHexdump(t *testing.T) {
+	data := []byte("Hello, binary world!\x00\x01\x02")
+	data = append(data, make([]byte, 48)...)
+	data = append(data, "end"...)
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	filename := tempFile(t, dir, "data.bin", data)
+
+	output, err := runOutput("hexdump", filename)
+	require.NoError(t, err)
+	require.Equal(t, strings.Join([]string{
+		`00000000  48 65 6c 6c 6f 2c 20 62  69 6e 61 72 79 20 77 6f  |Hello, binary wo|`,
+		`00000010  72 6c 64 21 00 01 02 00  00 00 00 00 00 00 00 00  |rld!............|`,
+		`00000020  00 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|`,
+		`*`,
+		`00000040  00 00 00 00 00 00 00 65  6e 64                    |.......end|`,
+		`0000004a`,
+		``,
+	}, "\n"), output)
+
+	output, err = runOutput("hexdump", "-offset", "0x7", "-length", "6", filename)
+	require.NoError(t, err)
+	require.Equal(t, "00000007  62 69 6e 61 72 79                                 |binary|\n0000000d\n", output)
+
+	output, err = runOutput("hexdump", "-offset", "1000", filename)
+	require.NoError(t, err)
+	require.Empty(t, output)
+
+	_, err = runOutput("hexdump", filepath.Join(filepath.Dir(filename), "missing.bin"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestWriteRead(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	filename := filepath.Join(dir, "record.bin")
+	spec := "u32be,strz,u16le*3,i8,i64le,f32,f64le,uvarint,varint,hex2"
+
+	output, err := runOutput("write", "-fields", spec, filename,
+		"0xcafebabe", "name", "1", "2", "0x0300", "-1", "-2", "1.5", "-0.25", "300", "-300", "beef")
+	require.NoError(t, err)
+	require.Equal(t, "42 bytes written\n", output)
+
+	data, err := ioutil.ReadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xca, 0xfe, 0xba, 0xbe, 'n', 'a', 'm', 'e', 0, 1, 0, 2, 0, 0, 3}, data[:15])
+
+	output, err = runOutput("read", "-fields", spec, filename)
+	require.NoError(t, err)
+	require.Equal(t, strings.Join([]string{
+		`00000000  u32be     3405691582`,
+		`00000004  strz      "name"`,
+		`00000009  u16le     1`,
+		`0000000b  u16le     2`,
+		`0000000d  u16le     768`,
+		`0000000f  i8        -1`,
+		`00000010  i64le     -2`,
+		`00000018  f32       1.5`,
+		`0000001c  f64le     -0.25`,
+		`00000024  uvarint   300`,
+		`00000026  varint    -300`,
+		`00000028  hex2      beef`,
+		``,
+	}, "\n"), output)
+
+	output, err = runOutput("write", "-append", "-fields", "u8", filename, "255")
+	require.NoError(t, err)
+	require.Equal(t, "1 bytes written\n", output)
+
+	output, err = runOutput("read", "-offset", "42", "-fields", "u8", filename)
+	require.NoError(t, err)
+	require.Equal(t, "0000002a  u8        255\n", output)
+
+	output, err = runOutput("read", "-offset", "42", "-fields", "u8,u32", filename)
+	require.True(t, errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF), "unexpected error %v", err)
+	require.Equal(t, "0000002a  u8        255\n", output, "fields decoded before error must be printed")
+}
+
+func TestWrite_InvalidValue(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	filename := tempFile(t, dir, "keep.bin", []byte("keep"))
+
+	for _, tt := range []struct {
+		spec  string
+		value string
+	}{
+		{spec: "u8", value: "256"},
+		{spec: "i16", value: "-32769"},
+		{spec: "f32", value: "one"},
+		{spec: "uvarint", value: "-1"},
+		{spec: "hex2", value: "beefed"},
+		{spec: "hex2", value: "zzzz"},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.spec+"="+tt.value, func(t *testing.T) {
+			_, err := runOutput("write", "-fields", tt.spec, filename, tt.value)
+			require.Error(t, err)
+
+			data, err := ioutil.ReadFile(filename)
+			require.NoError(t, err)
+			require.Equal(t, "keep", string(data), "invalid value must not truncate file")
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	dir, cleanup := tempDir(t)
+	defer cleanup()
+
+	base := make([]byte, 64)
+	for idx := range base {
+		base[idx] = byte(idx)
+	}
+
+	changed := append([]byte(nil), base...)
+	changed[2] = 0xff
+	changed[5] = 0xfe
+	changed[60] = 0xfd
+	changed = append(changed, 0x40)
+
+	first := tempFile(t, dir, "first.bin", base)
+	second := tempFile(t, dir, "second.bin", changed)
+
+	output, err := runOutput("diff", "-context", "2", first, second)
+	require.True(t, errors.Is(err, errDiffer))
+	require.Equal(t, strings.Join([]string{
+		`--- ` + first + ` (64 bytes)`,
+		`+++ ` + second + ` (65 bytes)`,
+		`@@ 0x00000000,0x00000008 @@`,
+		`-00000000  00 01 02 03 04 05 06 07`,
+		`+00000000  00 01 ff 03 04 fe 06 07`,
+		`@@ 0x0000003a,0x00000041 @@`,
+		`-0000003a  3a 3b 3c 3d 3e 3f`,
+		`+0000003a  3a 3b fd 3d 3e 3f 40`,
+		``,
+	}, "\n"), output)
+
+	output, err = runOutput("diff", "-context", "0", first, first)
+	require.NoError(t, err)
+	require.Empty(t, output)
+
+	third := tempFile(t, dir, "third.bin", base[:32])
+	output, err = runOutput("diff", "-context", "20", first, third)
+	require.True(t, errors.Is(err, errDiffer))
+	require.Equal(t, strings.Join([]string{
+		`--- ` + first + ` (64 bytes)`,
+		`+++ ` + third + ` (32 bytes)`,
+		`@@ 0x0000000c,0x00000040 @@`,
+		` 0000000c  0c 0d 0e 0f 10 11 12 13 14 15 16 17 18 19 1a 1b`,
+		`-0000001c  1c 1d 1e 1f 20 21 22 23 24 25 26 27 28 29 2a 2b`,
+		`+0000001c  1c 1d 1e 1f`,
+		`-0000002c  2c 2d 2e 2f 30 31 32 33 34 35 36 37 38 39 3a 3b`,
+		`-0000003c  3c 3d 3e 3f`,
+		``,
+	}, "\n"), output, "lines beyond the end of shorter file must be omitted")
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/amarin/binutils"
+)
+
+// runRead implements read command decoding typed fields sequence from file.
+func runRead(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("read", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	offset := flags.Int64("offset", 0, "first field offset")
+	spec := flags.String("fields", "", "fields spec, e.g. u32be,strz,u16le*3")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	if flags.NArg() != 1 || *spec == "" {
+		return fmt.Errorf("%w: read requires -fields SPEC and single FILE argument", errUsage)
+	}
+
+	fields, err := parseFields(*spec)
+	if err != nil {
+		return err
+	}
+
+	file, err := openAt(flags.Arg(0), *offset)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return readFields(binutils.NewBinaryReader(file), fields, *offset, stdout)
+}
+
+// readFields decodes fields from reader writing lines of field offset, type and value into stdout.
+func readFields(reader *binutils.BinaryReader, fields []field, start int64, stdout io.Writer) error {
+	output := bufio.NewWriter(stdout)
+
+	for _, item := range fields {
+		offset := start + int64(reader.BytesTaken())
+
+		value, err := item.read(reader)
+		if err != nil {
+			output.Flush()
+			return fmt.Errorf("read %s at %#x: %w", item.name, offset, err)
+		}
+
+		fmt.Fprintf(output, "%08x  %-8s  %s\n", offset, item.name, value)
+	}
+
+	return output.Flush()
+}
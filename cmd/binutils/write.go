@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/amarin/binutils"
+)
+
+// runWrite implements write command encoding values as typed fields sequence into file.
+func runWrite(args []string, stdout io.Writer) error {
+	flags := flag.NewFlagSet("write", flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	appendMode := flags.Bool("append", false, "append to existing file instead of truncating it")
+	spec := flags.String("fields", "", "fields spec, e.g. u32be,strz,u16le*3")
+
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf("%w: %v", errUsage, err)
+	}
+
+	if flags.NArg() < 1 || *spec == "" {
+		return fmt.Errorf("%w: write requires -fields SPEC and FILE argument", errUsage)
+	}
+
+	fields, err := parseFields(*spec)
+	if err != nil {
+		return err
+	}
+
+	values := flags.Args()[1:]
+	if len(values) != len(fields) {
+		return fmt.Errorf("%w: fields spec expects %d values, got %d", errUsage, len(fields), len(values))
+	}
+
+	data, err := encodeFields(fields, values)
+	if err != nil {
+		return err
+	}
+
+	mode := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if *appendMode {
+		mode = os.O_WRONLY | os.O_CREATE | os.O_APPEND
+	}
+
+	file, err := os.OpenFile(flags.Arg(0), mode, 0o644)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.Write(data)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(stdout, "%d bytes written\n", len(data))
+
+	return err
+}
+
+// encodeFields encodes values as fields sequence.
+// Values are encoded before file opened, so invalid value does not leave file truncated or partially written.
+func encodeFields(fields []field, values []string) ([]byte, error) {
+	buffer := new(bytes.Buffer)
+	writer := binutils.NewBinaryWriter(buffer)
+
+	for idx, item := range fields {
+		if err := item.write(writer, values[idx]); err != nil {
+			return nil, fmt.Errorf("field %d: %w", idx+1, err)
+		}
+	}
+
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// binutilsImport is an import path of binutils package used by generated code.
+const binutilsImport = "github.com/amarin/binutils"
+
+// lengthMethods maps len tag prefix values to typed Read/Write method suffixes.
+var lengthMethods = map[string]string{
+	"uint8":   "Uint8",
+	"uint16":  "Uint16",
+	"uint32":  "Uint32",
+	"uvarint": "Uvarint",
+}
+
+// errorPath defines generated error message prefix as format string and its arguments, e.g. Header.Items[%d] and i0.
+type errorPath struct {
+	format string
+	args   []string
+}
+
+// index returns path of element indexed by variable.
+func (p errorPath) index(variable string) errorPath {
+	return errorPath{format: p.format + "[%d]", args: append(append([]string(nil), p.args...), variable)}
+}
+
+// wrap returns error wrapping expression.
+func (p errorPath) wrap(err string) string {
+	return p.errorf(": %w", err)
+}
+
+// errorf returns fmt.Errorf expression of message prefixed by path.
+func (p errorPath) errorf(format string, args ...string) string {
+	args = append(append([]string(nil), p.args...), args...)
+
+	return fmt.Sprintf("fmt.Errorf(%q, %s)", p.format+format, strings.Join(args, ", "))
+}
+
+// generator accumulates generated source.
+type generator struct {
+	pkg     *parsedPackage  // source package declarations
+	buffer  bytes.Buffer    // generated code
+	imports map[string]bool // used import paths
+}
+
+// generate returns formatted source of BinaryReadFrom and BinaryWriteTo methods for structs.
+func generate(pkg *parsedPackage, structs []*structType) ([]byte, error) {
+	g := &generator{pkg: pkg, imports: map[string]bool{binutilsImport: true}}
+
+	for _, typ := range structs {
+		g.structMethods(typ)
+	}
+
+	var source bytes.Buffer
+
+	source.WriteString("// Code generated by binutilsgen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&source, "package %s\n\n", pkg.name)
+
+	paths := make([]string, 0, len(g.imports))
+	for path := range g.imports {
+		paths = append(paths, path)
+	}
+
+	sort.Strings(paths)
+	source.WriteString("import (\n")
+
+	for _, path := range paths { // standard library imports first
+		if !strings.Contains(path, ".") {
+			fmt.Fprintf(&source, "%q\n", path)
+		}
+	}
+
+	source.WriteString("\n")
+
+	for _, path := range paths {
+		if strings.Contains(path, ".") {
+			fmt.Fprintf(&source, "%q\n", path)
+		}
+	}
+
+	source.WriteString(")\n")
+	source.Write(g.buffer.Bytes())
+
+	formatted, err := format.Source(source.Bytes())
+	if err != nil {
+		return source.Bytes(), fmt.Errorf("format generated code: %w", err)
+	}
+
+	return formatted, nil
+}
+
+// printf appends formatted line to generated code.
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(&g.buffer, format+"\n", args...)
+}
+
+// structMethods generates methods of single struct.
+func (g *generator) structMethods(typ *structType) {
+	receiver := string(unicode.ToLower([]rune(typ.name)[0]))
+
+	g.printf("")
+	g.printf("// BinaryReadFrom decodes %s fields from reader in declaration order.", typ.name)
+	g.printf("func (%s *%s) BinaryReadFrom(reader *binutils.BinaryReader) (err error) {", receiver, typ.name)
+
+	for _, field := range typ.fields {
+		g.read(receiver+"."+field.name, field.typ, errorPath{format: typ.name + "." + field.name}, receiver, 0)
+		g.printf("")
+	}
+
+	g.printf("return nil")
+	g.printf("}")
+	g.printf("")
+	g.printf("// BinaryWriteTo encodes %s fields into writer in declaration order.", typ.name)
+	g.printf("func (%s *%s) BinaryWriteTo(writer *binutils.BinaryWriter) (err error) {", receiver, typ.name)
+
+	for _, field := range typ.fields {
+		g.write(receiver+"."+field.name, field.typ, errorPath{format: typ.name + "." + field.name}, receiver, 0)
+		g.printf("")
+	}
+
+	g.printf("return nil")
+	g.printf("}")
+}
+
+// read generates code decoding target value.
+func (g *generator) read(target string, typ *valueType, path errorPath, receiver string, depth int) {
+	g.imports["fmt"] = true
+
+	switch typ.kind {
+	case kindScalar:
+		g.readScalar(target, typ, path, depth > 0)
+	case kindStruct:
+		g.printf("if err = %s.BinaryReadFrom(reader); err != nil {", target)
+		g.printf("return %s", path.wrap("err"))
+		g.printf("}")
+	case kindArray:
+		g.readElements(target+"[:]", target, typ.elem, path, receiver, depth)
+	case kindSlice:
+		g.readLength(target, typ, path, receiver)
+		g.readElements(target, target, typ.elem, path, receiver, depth)
+	}
+}
+
+// readScalar generates code decoding scalar value using typed Read call.
+// Temporary value is declared in its own block unless code is already scoped, e.g. by loop body.
+func (g *generator) readScalar(target string, typ *valueType, path errorPath, scoped bool) {
+	method, value := typ.method, "value"
+
+	switch typ.basic {
+	case "float32":
+		method, value = "Uint32", "math.Float32frombits(value)"
+		g.imports["math"] = true
+	case "float64":
+		method, value = "Uint64", "math.Float64frombits(value)"
+		g.imports["math"] = true
+	default:
+		if typ.goType == typ.basic {
+			g.printf("if %s, err = reader.Read%s(); err != nil {", target, method)
+			g.printf("return %s", path.wrap("err"))
+			g.printf("}")
+
+			return
+		}
+	}
+
+	if typ.goType != typ.basic {
+		value = typ.goType + "(" + value + ")"
+	}
+
+	if !scoped {
+		g.printf("{")
+	}
+
+	g.printf("value, err := reader.Read%s()", method)
+	g.printf("if err != nil {")
+	g.printf("return %s", path.wrap("err"))
+	g.printf("}")
+	g.printf("%s = %s", target, value)
+
+	if !scoped {
+		g.printf("}")
+	}
+}
+
+// readLength generates code allocating slice using length field or decoded length prefix.
+func (g *generator) readLength(target string, typ *valueType, path errorPath, receiver string) {
+	if typ.lenField != "" {
+		length := receiver + "." + typ.lenField
+		if !strings.HasPrefix(typ.lenBasic, "uint") {
+			g.printf("if %s < 0 {", length)
+			g.printf("return %s", path.errorf(": negative length %d", length))
+			g.printf("}")
+		}
+
+		g.checkLength(length, typ.lenBasic, typ.elem, path)
+		g.printf("%s = make(%s, %s)", target, g.sliceType(typ), length)
+
+		return
+	}
+
+	g.printf("{")
+	g.printf("length, err := reader.Read%s()", lengthMethods[typ.lenType])
+	g.printf("if err != nil {")
+	g.printf("return %s", path.wrap("err"))
+	g.printf("}")
+	g.checkLength("length", lengthPrefixes[typ.lenType], typ.elem, path)
+	g.printf("%s = make(%s, length)", target, g.sliceType(typ))
+	g.printf("}")
+}
+
+// checkLength generates code rejecting decoded slice length before allocation.
+// Lengths of 32 and 64 bits types must not exceed binutils.DefaultMaxFrameSize.
+// Scalar elements take at least one byte each, so their count must not exceed bytes left in limited reader.
+func (g *generator) checkLength(length, basic string, elem *valueType, path errorPath) {
+	if !strings.HasSuffix(basic, "8") && !strings.HasSuffix(basic, "16") {
+		g.printf("if %s > binutils.DefaultMaxFrameSize {", length)
+		g.printf("return %s", path.errorf(": length %d: %w", length, "binutils.ErrFrameSize"))
+		g.printf("}")
+	}
+
+	if elem.kind != kindScalar {
+		return
+	}
+
+	g.imports["io"] = true
+	g.printf("if remaining := reader.Remaining(); remaining >= 0 && %s > uint64(remaining) {",
+		convert(length, basic, "uint64"))
+	g.printf("return %s", path.errorf(": length %d exceeds %d bytes left: %w", length, "remaining", "io.ErrUnexpectedEOF"))
+	g.printf("}")
+}
+
+// readElements generates code decoding array or slice elements using bulk call if possible.
+func (g *generator) readElements(all, target string, elem *valueType, path errorPath, receiver string, depth int) {
+	if method := bulkMethod(elem); method != "" {
+		if method == "Bytes" {
+			method = "BytesInto"
+		}
+
+		g.printf("if err = reader.Read%s(%s); err != nil {", method, all)
+		g.printf("return %s", path.wrap("err"))
+		g.printf("}")
+
+		return
+	}
+
+	index := fmt.Sprintf("i%d", depth)
+	g.printf("for %s := range %s {", index, target)
+	g.read(target+"["+index+"]", elem, path.index(index), receiver, depth+1)
+	g.printf("}")
+}
+
+// write generates code encoding target value.
+func (g *generator) write(target string, typ *valueType, path errorPath, receiver string, depth int) {
+	g.imports["fmt"] = true
+
+	switch typ.kind {
+	case kindScalar:
+		g.printf("if err = writer.Write%s; err != nil {", g.writeScalarCall(target, typ))
+		g.printf("return %s", path.wrap("err"))
+		g.printf("}")
+	case kindStruct:
+		g.printf("if err = %s.BinaryWriteTo(writer); err != nil {", target)
+		g.printf("return %s", path.wrap("err"))
+		g.printf("}")
+	case kindArray:
+		g.writeElements(target+"[:]", target, typ.elem, path, receiver, depth)
+	case kindSlice:
+		g.writeLength(target, typ, path, receiver)
+		g.writeElements(target, target, typ.elem, path, receiver, depth)
+	}
+}
+
+// writeScalarCall returns typed Write call encoding scalar value without Write prefix, e.g. Uint16(value).
+func (g *generator) writeScalarCall(target string, typ *valueType) string {
+	switch typ.basic {
+	case "float32":
+		g.imports["math"] = true
+		return "Uint32(math.Float32bits(" + convert(target, typ.goType, "float32") + "))"
+	case "float64":
+		g.imports["math"] = true
+		return "Uint64(math.Float64bits(" + convert(target, typ.goType, "float64") + "))"
+	default:
+		return typ.method + "(" + convert(target, typ.goType, typ.basic) + ")"
+	}
+}
+
+// writeLength generates code checking slice length matches length field or encoding length prefix.
+func (g *generator) writeLength(target string, typ *valueType, path errorPath, receiver string) {
+	if typ.lenField != "" {
+		length := receiver + "." + typ.lenField
+		g.printf("if len(%s) != int(%s) {", target, length)
+		g.printf("return %s", path.errorf(": length %d does not match "+typ.lenField+" %d", "len("+target+")", length))
+		g.printf("}")
+
+		return
+	}
+
+	basic := lengthPrefixes[typ.lenType]
+	if basic != "uint64" {
+		g.imports["math"] = true
+		g.printf("if uint64(len(%s)) > math.Max%s {", target, lengthMethods[typ.lenType])
+		g.printf("return %s", path.errorf(": length %d overflows "+basic, "len("+target+")"))
+		g.printf("}")
+	}
+
+	g.printf("if err = writer.Write%s(%s(len(%s))); err != nil {", lengthMethods[typ.lenType], basic, target)
+	g.printf("return %s", path.wrap("err"))
+	g.printf("}")
+}
+
+// writeElements generates code encoding array or slice elements using bulk call if possible.
+func (g *generator) writeElements(all, target string, elem *valueType, path errorPath, receiver string, depth int) {
+	if method := bulkMethod(elem); method != "" {
+		g.printf("if err = writer.Write%s(%s); err != nil {", method, all)
+		g.printf("return %s", path.wrap("err"))
+		g.printf("}")
+
+		return
+	}
+
+	index := fmt.Sprintf("i%d", depth)
+	g.printf("for %s := range %s {", index, target)
+	g.write(target+"["+index+"]", elem, path.index(index), receiver, depth+1)
+	g.printf("}")
+}
+
+// sliceType returns slice type expression registering import of element type package if any.
+func (g *generator) sliceType(typ *valueType) string {
+	if idx := strings.IndexByte(typ.goType, '.'); idx >= 0 {
+		name := strings.TrimLeft(typ.goType[:idx], "[]")
+		if path, ok := g.pkg.importPath(name); ok {
+			g.imports[path] = true
+		}
+	}
+
+	return typ.goType
+}
+
+// bulkMethod returns bulk Read/Write method suffix for elements, e.g. Uint16s, or empty string if absent.
+func bulkMethod(elem *valueType) string {
+	switch {
+	case elem.kind != kindScalar || elem.goType != elem.basic || elem.method != scalarMethods[elem.basic]:
+		return ""
+	case elem.basic == "uint8":
+		return "Bytes"
+	case bulkMethods[elem.basic]:
+		return elem.method + "s"
+	default:
+		return ""
+	}
+}
+
+// convert returns value expression converted to basic type if its type differs.
+func convert(value, goType, basic string) string {
+	if goType == basic {
+		return value
+	}
+
+	return basic + "(" + value + ")"
+}
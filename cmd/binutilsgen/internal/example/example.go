@@ -0,0 +1,32 @@
+// Package example holds structs encoded by methods generated by binutilsgen.
+package example
+
+//go:generate go run github.com/amarin/binutils/cmd/binutilsgen
+
+// Kind is a record kind.
+type Kind uint16
+
+// Header starts every record.
+type Header struct {
+	Magic   [2]byte `bin:""`
+	Kind    Kind
+	Version uint64 `bin:"varint"`
+	Ratio   float32
+	Name    string
+}
+
+// Point is a nested struct.
+type Point struct {
+	X, Y int16
+}
+
+// Record holds nested structs, fixed arrays and slices.
+type Record struct {
+	Header Header `bin:""`
+	Count  uint8
+	Points []Point  `bin:"len=Count"`
+	Values []uint32 `bin:"len=uvarint"`
+	Tags   []string `bin:"len=uint8"`
+	Grid   [2][2]int16
+	Cache  []byte `bin:"-"`
+}
@@ -0,0 +1,66 @@
+package example_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amarin/binutils"
+	"github.com/amarin/binutils/cmd/binutilsgen/internal/example"
+)
+
+func TestRecord_RoundTrip(t *testing.T) {
+	record := example.Record{
+		Header: example.Header{Magic: [2]byte{'E', 'X'}, Kind: 7, Version: 300, Ratio: 0.5, Name: "rec"},
+		Count:  2,
+		Points: []example.Point{{X: -1, Y: 1}, {X: 2, Y: -2}},
+		Values: []uint32{0xdeadbeef},
+		Tags:   []string{"a", "bc"},
+		Grid:   [2][2]int16{{1, 2}, {3, 4}},
+		Cache:  []byte("skipped"),
+	}
+
+	buffer := new(bytes.Buffer)
+	require.NoError(t, binutils.NewBinaryWriter(buffer).WriteObject(&record))
+	require.Equal(t, []byte{
+		'E', 'X', 0, 7, 0xac, 0x02, 0x3f, 0, 0, 0, 'r', 'e', 'c', 0,
+		2, 0xff, 0xff, 0, 1, 0, 2, 0xff, 0xfe,
+		1, 0xde, 0xad, 0xbe, 0xef,
+		2, 'a', 0, 'b', 'c', 0,
+		0, 1, 0, 2, 0, 3, 0, 4,
+	}, buffer.Bytes())
+
+	decoded := example.Record{}
+	require.NoError(t, binutils.NewBinaryReader(bytes.NewReader(buffer.Bytes())).ReadObject(&decoded))
+
+	record.Cache = nil
+	require.Equal(t, record, decoded)
+}
+
+func TestRecord_Errors(t *testing.T) {
+	record := example.Record{Count: 1}
+	err := binutils.NewBinaryWriter(new(bytes.Buffer)).WriteObject(&record)
+	require.EqualError(t, err, "Record.Points: length 0 does not match Count 1")
+
+	data := []byte{'E', 'X', 0, 7, 1, 0, 0, 0, 0, 0, 1, 0, 0, 0}
+	err = binutils.NewBinaryReader(bytes.NewReader(data)).ReadObject(&record)
+	require.True(t, errors.Is(err, io.ErrUnexpectedEOF), "unexpected error %v", err)
+	require.EqualError(t, err, "Record.Points[0]: Point.Y: unexpected EOF")
+}
+
+func TestRecord_OversizedLength(t *testing.T) {
+	header := []byte{'E', 'X', 0, 7, 1, 0, 0, 0, 0, 0, 0}
+
+	data := append(append([]byte{}, header...), 0xff, 0xff, 0xff, 0xff, 0x0f) // Values length 1<<32-1
+	err := binutils.NewBinaryReader(bytes.NewReader(data)).ReadObject(new(example.Record))
+	require.True(t, errors.Is(err, binutils.ErrFrameSize), "unexpected error %v", err)
+
+	data = append(append([]byte{}, header...), 0x80, 0x80, 0x40) // Values length 1<<20
+	reader := binutils.NewBinaryReader(bytes.NewReader(data)).Sub(len(data))
+	err = reader.ReadObject(new(example.Record))
+	require.True(t, errors.Is(err, io.ErrUnexpectedEOF), "unexpected error %v", err)
+	require.EqualError(t, err, "Record.Values: length 1048576 exceeds 0 bytes left: unexpected EOF")
+}
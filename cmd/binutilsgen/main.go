@@ -0,0 +1,119 @@
+// Command binutilsgen generates reflection-free BinaryReadFrom and BinaryWriteTo methods for Go structs.
+//
+// Usage:
+//
+//	binutilsgen [-type T1,T2] [-output FILE] [DIR]
+//
+// Command parses non-test Go files of DIR (current directory by default) and generates methods
+// for specified struct types or for every struct having at least one field with bin tag,
+// and for nested struct types declared in DIR having no handwritten BinaryReadFrom method.
+// Generated file is written into DIR named after first type, e.g. header_binutils.go,
+// or binutils_gen.go if types are not specified. Typical use is a go:generate directive:
+//
+//	//go:generate binutilsgen -type Header
+//
+// Fields are encoded in declaration order using typed BinaryReader and BinaryWriter calls
+// and byte order of reader or writer. Supported field types are fixed size integers, int, uint, float32, float64,
+// strings encoded as zero-terminated, named types of these, nested structs implementing generated methods,
+// fixed arrays and slices of supported types. Errors are wrapped with struct and field names, e.g. Header.Items[2].
+//
+// Field bin tag holds comma separated options:
+//
+//	bin:"-"            skip field
+//	bin:"varint"       encode int, int64, uint or uint64 value or elements as base 128 varint
+//	bin:"len=Count"    slice length is taken from integer field Count declared earlier
+//	bin:"len=uvarint"  slice length is encoded before elements as uint8, uint16, uint32 or uvarint
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stderr); err != nil {
+		fmt.Fprintln(os.Stderr, "binutilsgen:", err)
+		os.Exit(2)
+	}
+}
+
+// run generates methods using command line arguments, usage is written into stderr on invalid flags.
+func run(args []string, stderr io.Writer) error {
+	flags := flag.NewFlagSet("binutilsgen", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	typeNames := flags.String("type", "", "comma separated struct type names, all structs having bin tags by default")
+	output := flags.String("output", "", "output file name, default DIR/<type>_binutils.go or DIR/binutils_gen.go")
+
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	dir := "."
+	switch flags.NArg() {
+	case 0:
+	case 1:
+		dir = flags.Arg(0)
+	default:
+		return fmt.Errorf("expected single DIR argument, got %d", flags.NArg())
+	}
+
+	pkg, err := parsePackage(dir)
+	if err != nil {
+		return err
+	}
+
+	names := pkg.tagged()
+	if *typeNames != "" {
+		names = strings.Split(*typeNames, ",")
+	}
+
+	if len(names) == 0 {
+		return fmt.Errorf("%s: no struct types having bin tags found", dir)
+	}
+
+	source, err := generateTypes(pkg, names)
+	if err != nil {
+		return err
+	}
+
+	filename := *output
+	switch {
+	case filename != "":
+	case *typeNames != "":
+		filename = filepath.Join(dir, strings.ToLower(names[0])+"_binutils.go")
+	default:
+		filename = filepath.Join(dir, "binutils_gen.go")
+	}
+
+	return ioutil.WriteFile(filename, source, 0o644)
+}
+
+// generateTypes returns generated source of methods for named struct types of package.
+// Nested struct types declared in package are generated too unless they have handwritten BinaryReadFrom method.
+func generateTypes(pkg *parsedPackage, names []string) ([]byte, error) {
+	structs := make([]*structType, 0, len(names))
+	queued := make(map[string]bool, len(names))
+
+	for idx := 0; idx < len(names); idx++ {
+		name := strings.TrimSpace(names[idx])
+		if queued[name] {
+			continue
+		}
+
+		typ, err := pkg.structType(name)
+		if err != nil {
+			return nil, err
+		}
+
+		queued[name] = true
+		structs = append(structs, typ)
+		names = append(names, typ.nested(pkg)...)
+	}
+
+	return generate(pkg, structs)
+}
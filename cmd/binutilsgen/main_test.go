@@ -0,0 +1,145 @@
+package main
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// update rewrites golden files by generated code, run go test -run Golden -update after generator changes.
+var update = flag.Bool("update", false, "update golden files")
+
+func TestGenerate_Golden(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		dir    string
+		types  string
+		golden string
+	}{
+		{name: "records", dir: "testdata/records", golden: "testdata/records/binutils_gen.golden"},
+		{name: "records_header", dir: "testdata/records", types: "Header", golden: "testdata/records/header.golden"},
+		{name: "example", dir: "internal/example", golden: "internal/example/binutils_gen.go"},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			output := filepath.Join(tt.dir, "generated.tmp")
+			defer os.Remove(output) // nolint:errcheck
+
+			args := []string{"-output", output, tt.dir}
+			if tt.types != "" {
+				args = append([]string{"-type", tt.types}, args...)
+			}
+
+			require.NoError(t, run(args, ioutil.Discard))
+
+			generated, err := ioutil.ReadFile(output)
+			require.NoError(t, err)
+
+			if *update {
+				require.NoError(t, ioutil.WriteFile(tt.golden, generated, 0o644))
+			}
+
+			expected, err := ioutil.ReadFile(tt.golden)
+			require.NoError(t, err)
+			require.Equal(t, string(expected), string(generated))
+		})
+	}
+}
+
+func TestRun_DefaultOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "binutilsgen")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir) // nolint:errcheck
+
+	source := "package sample\n\ntype Sample struct {\n\tValue uint8 `bin:\"\"`\n}\n"
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sample.go"), []byte(source), 0o644))
+
+	require.NoError(t, run([]string{dir}, ioutil.Discard))
+	require.FileExists(t, filepath.Join(dir, "binutils_gen.go"))
+
+	require.NoError(t, run([]string{"-type", "Sample", dir}, ioutil.Discard))
+	require.FileExists(t, filepath.Join(dir, "sample_binutils.go"))
+
+	// generated files must not be taken as handwritten methods or as input
+	require.NoError(t, run([]string{dir}, ioutil.Discard))
+}
+
+func TestRun_Errors(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		source   string
+		expected string
+	}{
+		{
+			name:     "no_tags",
+			source:   "type T struct {\n\tA uint8\n}",
+			expected: "no struct types having bin tags found",
+		},
+		{
+			name:     "slice_without_length",
+			source:   "type T struct {\n\tA []uint8 `bin:\"\"`\n}",
+			expected: "T.A: slice requires len=FIELD",
+		},
+		{
+			name:     "length_declared_after",
+			source:   "type T struct {\n\tA []uint8 `bin:\"len=N\"`\n\tN uint8\n}",
+			expected: "T.A: length field N must be declared before slice",
+		},
+		{
+			name:     "length_not_integer",
+			source:   "type T struct {\n\tN string\n\tA []uint8 `bin:\"len=N\"`\n}",
+			expected: "T.A: length field N is not an integer",
+		},
+		{
+			name:     "narrow_varint",
+			source:   "type T struct {\n\tA uint16 `bin:\"varint\"`\n}",
+			expected: "T.A: varint tag option requires 64-bit or int integer type, got uint16",
+		},
+		{
+			name:     "unknown_option",
+			source:   "type T struct {\n\tA uint8 `bin:\"le\"`\n}",
+			expected: `T.A: unsupported tag option "le"`,
+		},
+		{
+			name:     "bool",
+			source:   "type T struct {\n\tA bool `bin:\"\"`\n}",
+			expected: "T.A: unsupported type bool",
+		},
+		{
+			name:     "pointer",
+			source:   "type T struct {\n\tA *T `bin:\"\"`\n}",
+			expected: "T.A: unsupported type *T",
+		},
+		{
+			name:     "nested_slice",
+			source:   "type T struct {\n\tA [][]uint8 `bin:\"len=uint8\"`\n}",
+			expected: "T.A: unsupported nested slice []uint8",
+		},
+		{
+			name:     "embedded",
+			source:   "type E struct{}\n\ntype T struct {\n\tE `bin:\"\"`\n}",
+			expected: "unsupported embedded field type",
+		},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "binutilsgen")
+			require.NoError(t, err)
+
+			defer os.RemoveAll(dir) // nolint:errcheck
+
+			source := "package sample\n\n" + tt.source + "\n"
+			require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "sample.go"), []byte(source), 0o644))
+
+			err = run([]string{dir}, ioutil.Discard)
+			require.Error(t, err)
+			require.True(t, strings.Contains(err.Error(), tt.expected), "unexpected error %v", err)
+		})
+	}
+}
@@ -0,0 +1,463 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// errUnsupported returned if struct field could not be encoded.
+var errUnsupported = errors.New("unsupported")
+
+// kind defines field value encoding.
+type kind int
+
+// Field value encodings.
+const (
+	kindScalar kind = iota // single typed Read/Write call
+	kindStruct             // nested BinaryReadFrom/BinaryWriteTo call
+	kindArray              // fixed array of elements
+	kindSlice              // slice of elements with length field or prefix
+)
+
+// valueType describes how field value is encoded.
+type valueType struct {
+	kind     kind
+	goType   string     // Go type as declared, e.g. Kind or uint16
+	method   string     // scalar Read/Write method suffix, e.g. Uint16
+	basic    string     // scalar basic type returned by Read method, e.g. uint16
+	length   string     // array length expression
+	elem     *valueType // array or slice element
+	lenField string     // slice length field name
+	lenBasic string     // slice length field basic type
+	lenType  string     // slice length prefix type if no length field, e.g. uvarint
+}
+
+// structField describes single encoded struct field.
+type structField struct {
+	name string     // field name
+	typ  *valueType // field encoding
+}
+
+// structType describes struct to generate methods for.
+type structType struct {
+	name   string        // struct type name
+	fields []structField // encoded fields in declaration order
+}
+
+// scalarMethods maps basic types to typed Read/Write method suffixes.
+var scalarMethods = map[string]string{
+	"uint8":   "Uint8",
+	"byte":    "Uint8",
+	"uint16":  "Uint16",
+	"uint32":  "Uint32",
+	"uint64":  "Uint64",
+	"uint":    "Uint",
+	"int8":    "Int8",
+	"int16":   "Int16",
+	"int32":   "Int32",
+	"int64":   "Int64",
+	"int":     "Int",
+	"float32": "Float32",
+	"float64": "Float64",
+	"string":  "StringZ",
+}
+
+// bulkMethods lists element basic types having bulk ReadTs/WriteTs methods.
+var bulkMethods = map[string]bool{
+	"uint16": true, "int16": true, "uint32": true, "int32": true,
+	"uint64": true, "int64": true, "float32": true, "float64": true,
+}
+
+// lengthPrefixes maps len tag values to slice length prefix basic types.
+var lengthPrefixes = map[string]string{
+	"uint8":   "uint8",
+	"uint16":  "uint16",
+	"uint32":  "uint32",
+	"uvarint": "uint64",
+}
+
+// parsedPackage holds declarations of parsed package.
+type parsedPackage struct {
+	name  string               // package name
+	types map[string]ast.Expr  // declared types
+	tags  map[string]bool      // struct types having bin tags
+	impl  map[string]bool      // types having handwritten BinaryReadFrom method
+	order []string             // struct types in declaration order
+	fset  *token.FileSet       // positions of parsed files
+	files map[string]*ast.File // parsed files by name
+}
+
+// parsePackage parses non-test Go files of directory.
+func parsePackage(dir string) (*parsedPackage, error) {
+	fset := token.NewFileSet()
+	filenames, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Strings(filenames)
+
+	parsed := &parsedPackage{
+		types: make(map[string]ast.Expr),
+		tags:  make(map[string]bool),
+		impl:  make(map[string]bool),
+		fset:  fset,
+		files: make(map[string]*ast.File),
+	}
+
+	for _, filename := range filenames {
+		if strings.HasSuffix(filename, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filename, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+
+		if parsed.name == "" {
+			parsed.name = file.Name.Name
+		} else if parsed.name != file.Name.Name {
+			return nil, fmt.Errorf("%s: multiple packages %s and %s", dir, parsed.name, file.Name.Name)
+		}
+
+		if isGenerated(file) { // methods of previous run must not be taken as handwritten ones
+			continue
+		}
+
+		parsed.files[filename] = file
+		parsed.collect(file)
+	}
+
+	if parsed.name == "" {
+		return nil, fmt.Errorf("%s: no Go files", dir)
+	}
+
+	return parsed, nil
+}
+
+// isGenerated reports whether file is generated by binutilsgen.
+func isGenerated(file *ast.File) bool {
+	return len(file.Comments) > 0 && file.Comments[0].Pos() < file.Package &&
+		strings.HasPrefix(file.Comments[0].Text(), "Code generated by binutilsgen.")
+}
+
+// collect registers type declarations and BinaryReadFrom methods of file.
+func (p *parsedPackage) collect(file *ast.File) {
+	for _, decl := range file.Decls {
+		if funcDecl, ok := decl.(*ast.FuncDecl); ok {
+			if funcDecl.Recv != nil && funcDecl.Name.Name == "BinaryReadFrom" {
+				p.impl[strings.TrimPrefix(exprString(funcDecl.Recv.List[0].Type), "*")] = true
+			}
+
+			continue
+		}
+
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec)
+			p.types[typeSpec.Name.Name] = typeSpec.Type
+
+			structExpr, ok := typeSpec.Type.(*ast.StructType)
+			if !ok {
+				continue
+			}
+
+			p.order = append(p.order, typeSpec.Name.Name)
+
+			for _, field := range structExpr.Fields.List {
+				if _, ok := fieldTag(field); ok {
+					p.tags[typeSpec.Name.Name] = true
+				}
+			}
+		}
+	}
+}
+
+// tagged returns names of struct types having bin tags in declaration order.
+func (p *parsedPackage) tagged() (names []string) {
+	for _, name := range p.order {
+		if p.tags[name] {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// structType returns encoding description of named struct type.
+func (p *parsedPackage) structType(name string) (*structType, error) {
+	expr, ok := p.types[name]
+	if !ok {
+		return nil, fmt.Errorf("type %s not found", name)
+	}
+
+	structExpr, ok := expr.(*ast.StructType)
+	if !ok {
+		return nil, fmt.Errorf("type %s is not a struct", name)
+	}
+
+	result := &structType{name: name}
+
+	for _, field := range structExpr.Fields.List {
+		tag, _ := fieldTag(field)
+		if tag == "-" {
+			continue
+		}
+
+		names := fieldNames(field)
+		if len(names) == 0 {
+			return nil, fmt.Errorf("%s: %w embedded field type", p.fset.Position(field.Pos()), errUnsupported)
+		}
+
+		options, err := parseTag(tag)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s.%s: %w", p.fset.Position(field.Pos()), name, names[0], err)
+		}
+
+		typ, err := p.valueType(field.Type, options, true)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s.%s: %w", p.fset.Position(field.Pos()), name, names[0], err)
+		}
+
+		if err = result.checkLength(typ); err != nil {
+			return nil, fmt.Errorf("%s: %s.%s: %w", p.fset.Position(field.Pos()), name, names[0], err)
+		}
+
+		for _, fieldName := range names {
+			result.fields = append(result.fields, structField{name: fieldName, typ: typ})
+		}
+	}
+
+	return result, nil
+}
+
+// nested returns package declared struct types of fields lacking handwritten methods.
+func (s *structType) nested(pkg *parsedPackage) (names []string) {
+	for _, field := range s.fields {
+		typ := field.typ
+		for typ.elem != nil {
+			typ = typ.elem
+		}
+
+		if _, declared := pkg.types[typ.goType]; declared && typ.kind == kindStruct && !pkg.impl[typ.goType] {
+			names = append(names, typ.goType)
+		}
+	}
+
+	return names
+}
+
+// checkLength ensures slice length field is declared before slice and is an integer scalar.
+func (s *structType) checkLength(typ *valueType) error {
+	if typ.kind != kindSlice || typ.lenField == "" {
+		return nil
+	}
+
+	for _, field := range s.fields {
+		if field.name != typ.lenField {
+			continue
+		}
+
+		if field.typ.kind != kindScalar || strings.HasPrefix(field.typ.basic, "float") || field.typ.basic == "string" {
+			return fmt.Errorf("length field %s is not an integer", typ.lenField)
+		}
+
+		typ.lenBasic = field.typ.basic
+
+		return nil
+	}
+
+	return fmt.Errorf("length field %s must be declared before slice", typ.lenField)
+}
+
+// tagOptions holds parsed bin tag.
+type tagOptions struct {
+	varint bool   // encode integers as varints
+	length string // slice length field name or prefix type
+}
+
+// fieldTag returns bin tag value of field.
+func fieldTag(field *ast.Field) (string, bool) {
+	if field.Tag == nil {
+		return "", false
+	}
+
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return "", false
+	}
+
+	return reflect.StructTag(tag).Lookup("bin")
+}
+
+// parseTag parses comma separated bin tag options: varint and len=FIELD or len=PREFIX.
+func parseTag(tag string) (options tagOptions, err error) {
+	for _, option := range strings.Split(tag, ",") {
+		switch {
+		case option == "":
+		case option == "varint":
+			options.varint = true
+		case strings.HasPrefix(option, "len="):
+			options.length = strings.TrimPrefix(option, "len=")
+		default:
+			return options, fmt.Errorf("%w tag option %q", errUnsupported, option)
+		}
+	}
+
+	return options, nil
+}
+
+// fieldNames returns declared field names.
+func fieldNames(field *ast.Field) (names []string) {
+	for _, ident := range field.Names {
+		if ident.Name != "_" {
+			names = append(names, ident.Name)
+		}
+	}
+
+	return names
+}
+
+// valueType returns encoding description of type expression.
+// Length option is applied to top level slice only, varint option is applied to integer elements too.
+func (p *parsedPackage) valueType(expr ast.Expr, options tagOptions, top bool) (*valueType, error) {
+	switch typed := expr.(type) {
+	case *ast.Ident:
+		return p.namedType(typed.Name, options)
+	case *ast.SelectorExpr:
+		return &valueType{kind: kindStruct, goType: exprString(typed)}, nil
+	case *ast.ArrayType:
+		elem, err := p.valueType(typed.Elt, tagOptions{varint: options.varint}, false)
+		if err != nil {
+			return nil, err
+		}
+
+		if typed.Len != nil {
+			return &valueType{kind: kindArray, goType: exprString(typed), length: exprString(typed.Len), elem: elem}, nil
+		}
+
+		return sliceType(typed, elem, options, top)
+	default:
+		return nil, fmt.Errorf("%w type %s", errUnsupported, exprString(expr))
+	}
+}
+
+// sliceType returns encoding description of slice using length option.
+func sliceType(expr *ast.ArrayType, elem *valueType, options tagOptions, top bool) (*valueType, error) {
+	result := &valueType{kind: kindSlice, goType: exprString(expr), elem: elem}
+
+	switch {
+	case !top:
+		return nil, fmt.Errorf("%w nested slice %s", errUnsupported, result.goType)
+	case options.length == "":
+		return nil, fmt.Errorf("slice requires len=FIELD or len=uint8|uint16|uint32|uvarint tag option")
+	case lengthPrefixes[options.length] != "":
+		result.lenType = options.length
+	default:
+		result.lenField = options.length
+	}
+
+	return result, nil
+}
+
+// namedType returns encoding description of basic or package declared type.
+func (p *parsedPackage) namedType(name string, options tagOptions) (*valueType, error) {
+	if _, ok := scalarMethods[name]; ok {
+		return scalarType(name, name, options)
+	}
+
+	declared, ok := p.types[name]
+	if !ok {
+		return nil, fmt.Errorf("%w type %s", errUnsupported, name)
+	}
+
+	switch underlying := declared.(type) {
+	case *ast.StructType:
+		return &valueType{kind: kindStruct, goType: name}, nil
+	case *ast.Ident:
+		if _, ok := scalarMethods[underlying.Name]; ok {
+			return scalarType(name, underlying.Name, options)
+		}
+	}
+
+	return nil, fmt.Errorf("%w type %s", errUnsupported, name)
+}
+
+// scalarType returns encoding description of scalar of basic underlying type.
+func scalarType(goType string, basic string, options tagOptions) (*valueType, error) {
+	if basic == "byte" {
+		basic = "uint8"
+	}
+
+	if goType == "byte" {
+		goType = "uint8"
+	}
+
+	result := &valueType{kind: kindScalar, goType: goType, method: scalarMethods[basic], basic: basic}
+
+	if options.varint {
+		switch basic { // narrower types are not allowed as decoded varint could overflow them
+		case "uint64", "uint":
+			result.method, result.basic = "Uvarint", "uint64"
+		case "int64", "int":
+			result.method, result.basic = "Varint", "int64"
+		default:
+			return nil, fmt.Errorf("varint tag option requires 64-bit or int integer type, got %s", goType)
+		}
+	}
+
+	return result, nil
+}
+
+// exprString returns source text of type expression.
+func exprString(expr ast.Expr) string {
+	switch typed := expr.(type) {
+	case *ast.Ident:
+		return typed.Name
+	case *ast.BasicLit:
+		return typed.Value
+	case *ast.SelectorExpr:
+		return exprString(typed.X) + "." + typed.Sel.Name
+	case *ast.ArrayType:
+		if typed.Len == nil {
+			return "[]" + exprString(typed.Elt)
+		}
+
+		return "[" + exprString(typed.Len) + "]" + exprString(typed.Elt)
+	case *ast.StarExpr:
+		return "*" + exprString(typed.X)
+	default:
+		return fmt.Sprintf("%T", expr)
+	}
+}
+
+// importPath returns import path of package name used in generated file, taken from parsed files imports.
+func (p *parsedPackage) importPath(name string) (string, bool) {
+	for _, file := range p.files {
+		for _, spec := range file.Imports {
+			path, _ := strconv.Unquote(spec.Path.Value)
+
+			switch {
+			case spec.Name != nil && spec.Name.Name == name:
+				return path, true
+			case spec.Name == nil && filepath.Base(path) == name:
+				return path, true
+			}
+		}
+	}
+
+	return "", false
+}
@@ -0,0 +1,62 @@
+package records
+
+import (
+	"time"
+
+	"example.com/geo"
+	"github.com/amarin/binutils"
+)
+
+// Kind is a named scalar type.
+type Kind uint16
+
+// Header uses scalars, named types, varints, floats and fixed arrays.
+type Header struct {
+	Magic       [4]byte `bin:""`
+	Kind        Kind
+	Flags, Mask uint8
+	Size        uint64 `bin:"varint"`
+	Delta       int    `bin:"varint"`
+	Ratio       float32
+	Scale       float64
+	Name        string
+	Matrix      [2][2]float32
+	Created     time.Time `bin:"-"`
+	internal    int16
+}
+
+// Record uses nested structs, slices with length fields and prefixes.
+type Record struct {
+	Header   Header `bin:""`
+	Count    int32
+	Points   []Point  `bin:"len=Count"`
+	Payload  []byte   `bin:"len=uvarint"`
+	Values   []uint32 `bin:"len=uint16"`
+	Tags     []string `bin:"len=uint8"`
+	Kinds    [3]Kind
+	Sizes    []int64 `bin:"varint,len=Count"`
+	Location geo.Location
+	Route    []geo.Location `bin:"len=uint32"`
+	Extra    Extra
+}
+
+// Point is nested struct without tags generated as Record dependency.
+type Point struct {
+	X, Y int16
+}
+
+// Extra has handwritten methods.
+type Extra struct {
+	Value uint8
+}
+
+// BinaryReadFrom implements binutils.BinaryReaderFrom.
+func (e *Extra) BinaryReadFrom(reader *binutils.BinaryReader) (err error) {
+	e.Value, err = reader.ReadUint8()
+	return err
+}
+
+// Ignored has no bin tags.
+type Ignored struct {
+	Value uint8
+}
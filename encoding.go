@@ -0,0 +1,331 @@
+package binutils
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// TextEncoding translates Go strings into byte sequences of specific character set and back.
+// Set it using BinaryReader.SetEncoding or BinaryWriter.SetEncoding to apply to all string methods.
+type TextEncoding interface {
+	// Name returns encoding name.
+	Name() string
+	// UnitSize returns encoding code unit size in bytes.
+	// Zero terminator of encoded string takes exactly one code unit.
+	UnitSize() int
+	// Encode translates UTF-8 string into encoded bytes sequence.
+	Encode(s string) ([]byte, error)
+	// Decode translates encoded bytes sequence into UTF-8 string.
+	Decode(data []byte) (string, error)
+}
+
+// UnmappableMode defines how encodings handle characters having no representation in target character set.
+type UnmappableMode int
+
+const (
+	// UnmappableStrict makes encoding return ErrUnmappable error on unmappable characters.
+	UnmappableStrict UnmappableMode = iota
+	// UnmappableReplace makes encoding replace unmappable characters
+	// with '?' when encoding and with unicode.ReplacementChar when decoding.
+	UnmappableReplace
+)
+
+const (
+	encodeReplacement = '?'            // used to replace unmappable characters while encoding
+	undefinedCode     = utf8.RuneError // marks undefined code page position
+)
+
+// Predefined text encodings. All of them are strict, use WithMode to get replacing version.
+var (
+	// UTF8 validates strings are correct UTF-8 sequences both on encoding and decoding.
+	UTF8 = &UTF8Encoding{mode: UnmappableStrict}
+
+	// UTF16 writes big-endian UTF-16 with byte order mark, reads UTF-16 detecting byte order by BOM.
+	// Big-endian byte order used if BOM is absent.
+	UTF16 = &UTF16Encoding{name: "UTF-16", order: binary.BigEndian, bom: true, mode: UnmappableStrict}
+
+	// UTF16LE reads and writes little-endian UTF-16 without byte order mark.
+	UTF16LE = &UTF16Encoding{name: "UTF-16LE", order: binary.LittleEndian, mode: UnmappableStrict}
+
+	// UTF16BE reads and writes big-endian UTF-16 without byte order mark.
+	UTF16BE = &UTF16Encoding{name: "UTF-16BE", order: binary.BigEndian, mode: UnmappableStrict}
+
+	// Windows1251 implements Windows-1251 (CP1251) cyrillic code page.
+	Windows1251 = &Charmap{name: "Windows-1251", table: windows1251Table, mode: UnmappableStrict}
+
+	// KOI8R implements KOI8-R cyrillic code page.
+	KOI8R = &Charmap{name: "KOI8-R", table: koi8rTable, mode: UnmappableStrict}
+
+	// ISO88591 implements ISO-8859-1 (Latin-1) code page.
+	ISO88591 = &Charmap{name: "ISO-8859-1", table: iso88591Table, mode: UnmappableStrict}
+)
+
+// UTF8Encoding implements TextEncoding validating UTF-8 sequences.
+type UTF8Encoding struct {
+	mode UnmappableMode
+}
+
+// WithMode returns UTF8Encoding copy using specified unmappable characters mode.
+func (e UTF8Encoding) WithMode(mode UnmappableMode) *UTF8Encoding {
+	return &UTF8Encoding{mode: mode}
+}
+
+// Name returns encoding name. Implements TextEncoding.
+func (e UTF8Encoding) Name() string { return "UTF-8" }
+
+// UnitSize returns encoding code unit size. Implements TextEncoding.
+func (e UTF8Encoding) UnitSize() int { return 1 }
+
+// Encode returns string bytes if string is valid UTF-8. Implements TextEncoding.
+func (e UTF8Encoding) Encode(s string) ([]byte, error) {
+	if utf8.ValidString(s) {
+		return []byte(s), nil
+	}
+
+	if e.mode == UnmappableStrict {
+		return nil, fmt.Errorf("%w: %v: invalid UTF-8", ErrUnmappable, e.Name())
+	}
+
+	return []byte(strings.ToValidUTF8(s, string(utf8.RuneError))), nil
+}
+
+// Decode returns bytes as string if bytes are valid UTF-8. Implements TextEncoding.
+func (e UTF8Encoding) Decode(data []byte) (string, error) {
+	if utf8.Valid(data) {
+		return string(data), nil
+	}
+
+	if e.mode == UnmappableStrict {
+		return "", fmt.Errorf("%w: %v: invalid UTF-8", ErrUnmappable, e.Name())
+	}
+
+	return strings.ToValidUTF8(string(data), string(utf8.RuneError)), nil
+}
+
+// UTF16Encoding implements TextEncoding for UTF-16 with specified byte order and optional byte order mark.
+type UTF16Encoding struct {
+	name  string
+	order binary.ByteOrder
+	bom   bool
+	mode  UnmappableMode
+}
+
+// WithMode returns UTF16Encoding copy using specified unmappable characters mode.
+func (e UTF16Encoding) WithMode(mode UnmappableMode) *UTF16Encoding {
+	e.mode = mode
+
+	return &e
+}
+
+// Name returns encoding name. Implements TextEncoding.
+func (e UTF16Encoding) Name() string { return e.name }
+
+// UnitSize returns encoding code unit size. Implements TextEncoding.
+func (e UTF16Encoding) UnitSize() int { return Uint16size }
+
+// Encode translates string into UTF-16 code units. Prepends byte order mark if required.
+// Implements TextEncoding.
+func (e UTF16Encoding) Encode(s string) ([]byte, error) {
+	if !utf8.ValidString(s) && e.mode == UnmappableStrict {
+		return nil, fmt.Errorf("%w: %v: invalid UTF-8", ErrUnmappable, e.name)
+	}
+
+	units := utf16.Encode([]rune(s))
+	if e.bom {
+		units = append([]uint16{0xfeff}, units...)
+	}
+
+	data := AllocateBytes(len(units) * Uint16size)
+	for idx, unit := range units {
+		e.order.PutUint16(data[idx*Uint16size:], unit)
+	}
+
+	return data, nil
+}
+
+// Decode translates UTF-16 code units into string.
+// Leading byte order mark is removed, if encoding allows BOM it also selects byte order.
+// Implements TextEncoding.
+func (e UTF16Encoding) Decode(data []byte) (string, error) {
+	if len(data)%Uint16size != 0 {
+		return "", fmt.Errorf("%w: %v: odd bytes count %d", ErrUnmappable, e.name, len(data))
+	}
+
+	order := e.order
+
+	if len(data) >= Uint16size {
+		switch {
+		case data[0] == 0xfe && data[1] == 0xff && (e.bom || order == binary.BigEndian):
+			order, data = binary.BigEndian, data[Uint16size:]
+		case data[0] == 0xff && data[1] == 0xfe && (e.bom || order == binary.LittleEndian):
+			order, data = binary.LittleEndian, data[Uint16size:]
+		}
+	}
+
+	runes := make([]rune, 0, len(data)/Uint16size)
+
+	for idx := 0; idx < len(data); idx += Uint16size {
+		unit := rune(order.Uint16(data[idx:]))
+
+		switch {
+		case !utf16.IsSurrogate(unit):
+			runes = append(runes, unit)
+			continue
+		case idx+Uint16size < len(data):
+			pair := utf16.DecodeRune(unit, rune(order.Uint16(data[idx+Uint16size:])))
+			if pair != utf8.RuneError {
+				runes = append(runes, pair)
+				idx += Uint16size
+
+				continue
+			}
+		}
+
+		if e.mode == UnmappableStrict {
+			return "", fmt.Errorf("%w: %v: unpaired surrogate at %d", ErrUnmappable, e.name, idx)
+		}
+
+		runes = append(runes, utf8.RuneError)
+	}
+
+	return string(runes), nil
+}
+
+// charmapTable holds single-byte code page upper half characters and lazily built reverse mapping.
+type charmapTable struct {
+	high    [128]rune
+	once    sync.Once
+	reverse map[rune]byte
+}
+
+// encodeMap returns character to byte mapping for code page upper half.
+func (t *charmapTable) encodeMap() map[rune]byte {
+	t.once.Do(func() {
+		t.reverse = make(map[rune]byte, len(t.high))
+		for idx, char := range t.high {
+			if char != undefinedCode {
+				t.reverse[char] = byte(0x80 + idx)
+			}
+		}
+	})
+
+	return t.reverse
+}
+
+// Charmap implements TextEncoding for single-byte code pages having ASCII in lower half.
+type Charmap struct {
+	name  string
+	table *charmapTable
+	mode  UnmappableMode
+}
+
+// WithMode returns Charmap copy using specified unmappable characters mode.
+func (c Charmap) WithMode(mode UnmappableMode) *Charmap {
+	c.mode = mode
+
+	return &c
+}
+
+// Name returns encoding name. Implements TextEncoding.
+func (c Charmap) Name() string { return c.name }
+
+// UnitSize returns encoding code unit size. Implements TextEncoding.
+func (c Charmap) UnitSize() int { return 1 }
+
+// Encode translates string into code page bytes. Implements TextEncoding.
+func (c Charmap) Encode(s string) ([]byte, error) {
+	data := make([]byte, 0, len(s))
+	reverse := c.table.encodeMap()
+
+	for offset, char := range s {
+		if char < utf8.RuneSelf {
+			data = append(data, byte(char))
+			continue
+		}
+
+		encoded, ok := reverse[char]
+		switch {
+		case ok:
+			data = append(data, encoded)
+		case c.mode == UnmappableStrict:
+			return nil, fmt.Errorf("%w: %v: %q at %d", ErrUnmappable, c.name, char, offset)
+		default:
+			data = append(data, encodeReplacement)
+		}
+	}
+
+	return data, nil
+}
+
+// Decode translates code page bytes into string. Implements TextEncoding.
+func (c Charmap) Decode(data []byte) (string, error) {
+	runes := make([]rune, len(data))
+
+	for idx, code := range data {
+		if code < utf8.RuneSelf {
+			runes[idx] = rune(code)
+			continue
+		}
+
+		runes[idx] = c.table.high[code-0x80]
+		if runes[idx] == undefinedCode && c.mode == UnmappableStrict {
+			return "", fmt.Errorf("%w: %v: undefined code %#02x at %d", ErrUnmappable, c.name, code, idx)
+		}
+	}
+
+	return string(runes), nil
+}
+
+// iso88591Table maps ISO-8859-1 upper half bytes to the same unicode code points.
+var iso88591Table = func() *charmapTable {
+	table := new(charmapTable)
+	for idx := range table.high {
+		table.high[idx] = rune(0x80 + idx)
+	}
+
+	return table
+}()
+
+// windows1251Table holds Windows-1251 upper half characters, 0x98 is undefined.
+var windows1251Table = &charmapTable{high: [128]rune{
+	0x0402, 0x0403, 0x201A, 0x0453, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x20AC, 0x2030, 0x0409, 0x2039, 0x040A, 0x040C, 0x040B, 0x040F,
+	0x0452, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0xFFFD, 0x2122, 0x0459, 0x203A, 0x045A, 0x045C, 0x045B, 0x045F,
+	0x00A0, 0x040E, 0x045E, 0x0408, 0x00A4, 0x0490, 0x00A6, 0x00A7,
+	0x0401, 0x00A9, 0x0404, 0x00AB, 0x00AC, 0x00AD, 0x00AE, 0x0407,
+	0x00B0, 0x00B1, 0x0406, 0x0456, 0x0491, 0x00B5, 0x00B6, 0x00B7,
+	0x0451, 0x2116, 0x0454, 0x00BB, 0x0458, 0x0405, 0x0455, 0x0457,
+	0x0410, 0x0411, 0x0412, 0x0413, 0x0414, 0x0415, 0x0416, 0x0417,
+	0x0418, 0x0419, 0x041A, 0x041B, 0x041C, 0x041D, 0x041E, 0x041F,
+	0x0420, 0x0421, 0x0422, 0x0423, 0x0424, 0x0425, 0x0426, 0x0427,
+	0x0428, 0x0429, 0x042A, 0x042B, 0x042C, 0x042D, 0x042E, 0x042F,
+	0x0430, 0x0431, 0x0432, 0x0433, 0x0434, 0x0435, 0x0436, 0x0437,
+	0x0438, 0x0439, 0x043A, 0x043B, 0x043C, 0x043D, 0x043E, 0x043F,
+	0x0440, 0x0441, 0x0442, 0x0443, 0x0444, 0x0445, 0x0446, 0x0447,
+	0x0448, 0x0449, 0x044A, 0x044B, 0x044C, 0x044D, 0x044E, 0x044F,
+}}
+
+// koi8rTable holds KOI8-R upper half characters.
+var koi8rTable = &charmapTable{high: [128]rune{
+	0x2500, 0x2502, 0x250C, 0x2510, 0x2514, 0x2518, 0x251C, 0x2524,
+	0x252C, 0x2534, 0x253C, 0x2580, 0x2584, 0x2588, 0x258C, 0x2590,
+	0x2591, 0x2592, 0x2593, 0x2320, 0x25A0, 0x2219, 0x221A, 0x2248,
+	0x2264, 0x2265, 0x00A0, 0x2321, 0x00B0, 0x00B2, 0x00B7, 0x00F7,
+	0x2550, 0x2551, 0x2552, 0x0451, 0x2553, 0x2554, 0x2555, 0x2556,
+	0x2557, 0x2558, 0x2559, 0x255A, 0x255B, 0x255C, 0x255D, 0x255E,
+	0x255F, 0x2560, 0x2561, 0x0401, 0x2562, 0x2563, 0x2564, 0x2565,
+	0x2566, 0x2567, 0x2568, 0x2569, 0x256A, 0x256B, 0x256C, 0x00A9,
+	0x044E, 0x0430, 0x0431, 0x0446, 0x0434, 0x0435, 0x0444, 0x0433,
+	0x0445, 0x0438, 0x0439, 0x043A, 0x043B, 0x043C, 0x043D, 0x043E,
+	0x043F, 0x044F, 0x0440, 0x0441, 0x0442, 0x0443, 0x0436, 0x0432,
+	0x044C, 0x044B, 0x0437, 0x0448, 0x044D, 0x0449, 0x0447, 0x044A,
+	0x042E, 0x0410, 0x0411, 0x0426, 0x0414, 0x0415, 0x0424, 0x0413,
+	0x0425, 0x0418, 0x0419, 0x041A, 0x041B, 0x041C, 0x041D, 0x041E,
+	0x041F, 0x042F, 0x0420, 0x0421, 0x0422, 0x0423, 0x0416, 0x0412,
+	0x042C, 0x042B, 0x0417, 0x0428, 0x042D, 0x0429, 0x0427, 0x042A,
+}}
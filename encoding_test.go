@@ -0,0 +1,147 @@
+package binutils_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/amarin/binutils"
+)
+
+func TestTextEncoding_EncodeDecode(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		encoding TextEncoding
+		text     string
+		hex      string
+	}{
+		{"utf8", UTF8, "Привет", "d09fd180d0b8d0b2d0b5d182"},
+		{"windows1251", Windows1251, "Привет №1", "cff0e8e2e5f220b931"},
+		{"koi8r", KOI8R, "Привет", "f0d2c9d7c5d4"},
+		{"iso88591", ISO88591, "Grüße", "4772fcdf65"},
+		{"utf16le", UTF16LE, "Я𝄞", "2f0434d81edd"},
+		{"utf16be", UTF16BE, "Я𝄞", "042fd834dd1e"},
+		{"utf16_bom", UTF16, "Я", "feff042f"},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			encoded, err := tt.encoding.Encode(tt.text)
+			require.NoError(t, err)
+			require.Equal(t, tt.hex, hex.EncodeToString(encoded))
+			decoded, err := tt.encoding.Decode(encoded)
+			require.NoError(t, err)
+			require.Equal(t, tt.text, decoded)
+		})
+	}
+}
+
+func TestUTF16Encoding_DecodeBOM(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		hex  string
+	}{
+		{"bom_be", "feff042f"},
+		{"bom_le", "fffe2f04"},
+		{"no_bom_be", "042f"},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tt.hex)
+			require.NoError(t, err)
+			decoded, err := UTF16.Decode(data)
+			require.NoError(t, err)
+			require.Equal(t, "Я", decoded)
+		})
+	}
+}
+
+func TestTextEncoding_Unmappable(t *testing.T) {
+	_, err := Windows1251.Encode("星")
+	require.True(t, errors.Is(err, ErrUnmappable))
+
+	encoded, err := Windows1251.WithMode(UnmappableReplace).Encode("a星")
+	require.NoError(t, err)
+	require.Equal(t, []byte("a?"), encoded)
+
+	_, err = Windows1251.Decode([]byte{0x98})
+	require.True(t, errors.Is(err, ErrUnmappable))
+
+	decoded, err := Windows1251.WithMode(UnmappableReplace).Decode([]byte{0x98})
+	require.NoError(t, err)
+	require.Equal(t, "�", decoded)
+
+	_, err = UTF16LE.Decode([]byte{0x34, 0xd8})
+	require.True(t, errors.Is(err, ErrUnmappable))
+
+	decoded, err = UTF16LE.WithMode(UnmappableReplace).Decode([]byte{0x34, 0xd8})
+	require.NoError(t, err)
+	require.Equal(t, "�", decoded)
+
+	_, err = UTF8.Decode([]byte{0xff})
+	require.True(t, errors.Is(err, ErrUnmappable))
+}
+
+func TestBinaryWriter_SetEncoding(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		encoding TextEncoding
+		hex      string
+	}{
+		{"none", nil, "d09fd18000"},
+		{"koi8r", KOI8R, "f0d200"},
+		{"utf16le", UTF16LE, "1f0440040000"},
+		{"utf16_bom", UTF16, "feff041f04400000"},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			expected, err := hex.DecodeString(tt.hex)
+			require.NoError(t, err)
+
+			buffer := new(bytes.Buffer)
+			writer := NewBinaryWriter(buffer)
+			writer.SetEncoding(tt.encoding)
+			require.Equal(t, tt.encoding, writer.Encoding())
+			require.NoError(t, writer.WriteObject("Пр"))
+			require.Equal(t, expected, buffer.Bytes())
+			require.Equal(t, len(expected), writer.BytesWritten())
+
+			reader := NewBinaryReader(buffer)
+			reader.SetEncoding(tt.encoding)
+			require.Equal(t, tt.encoding, reader.Encoding())
+			line := new(string)
+			require.NoError(t, reader.ReadObject(line))
+			require.Equal(t, "Пр", *line)
+			require.Equal(t, len(expected), reader.BytesTaken())
+		})
+	}
+}
+
+func TestBinaryWriter_WriteStringZ_Unmappable(t *testing.T) {
+	writer := NewBinaryWriter(new(bytes.Buffer))
+	writer.SetEncoding(ISO88591)
+	require.True(t, errors.Is(writer.WriteStringZ("星"), ErrUnmappable))
+	require.Equal(t, 0, writer.BytesWritten())
+}
+
+func TestBinaryReader_ReadStringZ_Encoding(t *testing.T) {
+	reader := NewBinaryReader(bytes.NewBuffer([]byte{0x41, 0x00, 0x42}))
+	reader.SetEncoding(UTF16LE)
+	_, err := reader.ReadStringZ() // no zero code unit
+	require.True(t, errors.Is(err, ErrRequired0T))
+}
+
+func TestBinaryReader_ReadStringZ_Unmappable(t *testing.T) {
+	data := []byte{0x00, 0xd8, 0x41, 0x00, 0x00, 0x00} // unpaired surrogate
+	reader := NewBinaryReader(bytes.NewBuffer(data))
+	reader.SetEncoding(UTF16LE)
+	_, err := reader.ReadStringZ()
+	require.True(t, errors.Is(err, ErrUnmappable), "unexpected error %v", err)
+
+	bytesReader := NewBinaryReaderBytes(data)
+	bytesReader.SetEncoding(UTF16LE)
+	_, err = bytesReader.ReadStringZ()
+	require.True(t, errors.Is(err, ErrUnmappable), "unexpected error %v", err)
+}
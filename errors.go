@@ -37,6 +37,27 @@ var (
 	// ErrRead returned if general read error.
 	ErrRead = fmt.Errorf("%w: read", Error)
 
+	// ErrUnmappable returned if character can not be represented using selected text encoding.
+	ErrUnmappable = fmt.Errorf("%w: unmappable character", Error)
+
+	// ErrInvalidUTF8 returned if strict UTF-8 mode enabled and invalid UTF-8 sequence taken.
+	ErrInvalidUTF8 = fmt.Errorf("%w: invalid UTF-8", Error)
+
+	// ErrUnreadRune returned if UnreadRune called not immediately after rune read.
+	ErrUnreadRune = fmt.Errorf("%w: invalid use of UnreadRune", Error)
+
+	// ErrVarintOverflow returned if varint encoded value overflows 64-bit integer.
+	ErrVarintOverflow = fmt.Errorf("%w: varint overflows a 64-bit integer", Error)
+
+	// ErrFrameSize returned if frame size exceeds allowed maximum.
+	ErrFrameSize = fmt.Errorf("%w: frame size exceeds maximum", Error)
+
+	// ErrUnconsumed returned if strict finish mode enabled and bounded reader has unread bytes left.
+	ErrUnconsumed = fmt.Errorf("%w: unconsumed data", Error)
+
+	// ErrUnmapped returned if memory mapped reader used after Close.
+	ErrUnmapped = fmt.Errorf("%w: mapping closed", Error)
+
 	// ErrClose returned if general close error.
 	ErrClose = fmt.Errorf("%w: close", Error)
 )
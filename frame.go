@@ -0,0 +1,170 @@
+package binutils
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+)
+
+// FrameHeader defines length prefix format of frames written by FrameWriter and read by FrameReader.
+type FrameHeader int
+
+const (
+	// FrameHeaderUint32 prefixes frame with 4 bytes length using configured byte order.
+	FrameHeaderUint32 FrameHeader = iota
+	// FrameHeaderUint16 prefixes frame with 2 bytes length using configured byte order.
+	FrameHeaderUint16
+	// FrameHeaderUvarint prefixes frame with unsigned base 128 varint length.
+	FrameHeaderUvarint
+)
+
+// DefaultMaxFrameSize defines maximum frame size used if zero or negative maximum specified.
+const DefaultMaxFrameSize = 16 << 20
+
+// FrameWriter writes length-prefixed frames into BinaryWriter.
+type FrameWriter struct {
+	writer  *BinaryWriter
+	header  FrameHeader
+	maxSize int
+}
+
+// NewFrameWriter creates FrameWriter writing frames prefixed by specified header into writer.
+// Frames larger than maxSize are rejected, zero or negative maxSize means DefaultMaxFrameSize.
+func NewFrameWriter(writer *BinaryWriter, header FrameHeader, maxSize int) *FrameWriter {
+	return &FrameWriter{writer: writer, header: header, maxSize: frameMaxSize(header, maxSize)}
+}
+
+// frameMaxSize returns maximum frame size allowed by header and required maximum.
+func frameMaxSize(header FrameHeader, maxSize int) int {
+	if maxSize <= 0 {
+		maxSize = DefaultMaxFrameSize
+	}
+
+	switch maxUint32 := uint64(math.MaxUint32); {
+	case header == FrameHeaderUint16 && maxSize > math.MaxUint16:
+		maxSize = math.MaxUint16
+	case header == FrameHeaderUint32 && uint64(maxSize) > maxUint32:
+		maxSize = int(maxUint32) // variable conversion keeps 32-bit builds compiling
+	}
+
+	return maxSize
+}
+
+// WriteFrame writes payload prefixed with its length.
+// Returns ErrFrameSize if payload exceeds maximum frame size or any underlying writer error.
+func (f *FrameWriter) WriteFrame(payload []byte) (err error) {
+	if len(payload) > f.maxSize {
+		return fmt.Errorf("%w: %d > %d", ErrFrameSize, len(payload), f.maxSize)
+	}
+
+	switch f.header {
+	case FrameHeaderUint16:
+		err = f.writer.WriteUint16(uint16(len(payload)))
+	case FrameHeaderUvarint:
+		err = f.writer.WriteUvarint(uint64(len(payload)))
+	default:
+		err = f.writer.WriteUint32(uint32(len(payload)))
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return f.writer.WriteBytes(payload)
+}
+
+// WriteObject encodes data using BinaryWriter.WriteObject and writes result as single frame.
+// If data implements BinarySizer frame buffer is preallocated and oversized data rejected before encoding.
+func (f *FrameWriter) WriteObject(data interface{}) error {
+	buffer := new(bytes.Buffer)
+
+	if sizer, ok := data.(BinarySizer); ok {
+		if size := sizer.BinarySize(); size > f.maxSize {
+			return fmt.Errorf("%w: %d > %d", ErrFrameSize, size, f.maxSize)
+		}
+	}
+
+	buffer.Grow(preallocSize(data))
+
+	if err := f.writer.derive(buffer).WriteObject(data); err != nil {
+		return err
+	}
+
+	return f.WriteFrame(buffer.Bytes())
+}
+
+// FrameReader reads length-prefixed frames from BinaryReader.
+type FrameReader struct {
+	reader  *BinaryReader
+	header  FrameHeader
+	maxSize int
+	current *BinaryReader // current frame payload reader
+}
+
+// NewFrameReader creates FrameReader reading frames prefixed by specified header from reader.
+// Frames larger than maxSize are rejected, zero or negative maxSize means DefaultMaxFrameSize.
+func NewFrameReader(reader *BinaryReader, header FrameHeader, maxSize int) *FrameReader {
+	return &FrameReader{reader: reader, header: header, maxSize: frameMaxSize(header, maxSize)}
+}
+
+// Next skips unread bytes of previous frame if any and reads next frame header.
+// Returns frame payload reader limited to frame size and frame size.
+// Returns io.EOF if no more frames available, ErrFrameSize if frame exceeds maximum frame size.
+func (f *FrameReader) Next() (frame *BinaryReader, size int, err error) {
+	if f.current != nil {
+		if _, err = f.current.Drain(); err != nil {
+			return nil, 0, err
+		}
+
+		f.current = nil
+	}
+
+	var frameSize uint64
+
+	switch f.header {
+	case FrameHeaderUint16:
+		var uint16size uint16
+		uint16size, err = f.reader.ReadUint16()
+		frameSize = uint64(uint16size)
+	case FrameHeaderUvarint:
+		frameSize, err = f.reader.ReadUvarint()
+	default:
+		var uint32size uint32
+		uint32size, err = f.reader.ReadUint32()
+		frameSize = uint64(uint32size)
+	}
+
+	switch {
+	case err != nil:
+		return nil, 0, err
+	case frameSize > uint64(f.maxSize):
+		return nil, 0, fmt.Errorf("%w: %d > %d", ErrFrameSize, frameSize, f.maxSize)
+	}
+
+	f.current = f.reader.Sub(int(frameSize))
+
+	return f.current, int(frameSize), nil
+}
+
+// ReadFrame reads next frame payload.
+// Returns io.EOF if no more frames available.
+func (f *FrameReader) ReadFrame() ([]byte, error) {
+	frame, size, err := f.Next()
+	if err != nil {
+		return nil, err
+	}
+
+	return frame.ReadBytesCount(size)
+}
+
+// ReadObject reads next frame and decodes target from it using BinaryReader.ReadObject.
+// Target could not read beyond frame boundary, unread frame bytes are skipped by next frame read.
+// Returns io.EOF if no more frames available.
+func (f *FrameReader) ReadObject(target interface{}) error {
+	frame, _, err := f.Next()
+	if err != nil {
+		return err
+	}
+
+	return frame.ReadObject(target)
+}
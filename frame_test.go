@@ -0,0 +1,117 @@
+package binutils_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/amarin/binutils"
+)
+
+// greedyRecord reads all available bytes regardless of its real size.
+type greedyRecord struct {
+	data []byte
+}
+
+func (g *greedyRecord) BinaryReadFrom(r *BinaryReader) (err error) {
+	for {
+		var next uint8
+		if next, err = r.ReadUint8(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+
+			return err
+		}
+
+		g.data = append(g.data, next)
+	}
+}
+
+func TestFrameWriter_WriteFrame(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		header FrameHeader
+		hex    []byte
+	}{
+		{"uint32", FrameHeaderUint32, []byte{0, 0, 0, 2, 1, 2, 0, 0, 0, 0}},
+		{"uint16", FrameHeaderUint16, []byte{0, 2, 1, 2, 0, 0}},
+		{"uvarint", FrameHeaderUvarint, []byte{2, 1, 2, 0}},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			buffer := new(bytes.Buffer)
+			writer := NewFrameWriter(NewBinaryWriter(buffer), tt.header, 0)
+			require.NoError(t, writer.WriteFrame([]byte{1, 2}))
+			require.NoError(t, writer.WriteFrame(nil))
+			require.Equal(t, tt.hex, buffer.Bytes())
+
+			reader := NewFrameReader(NewBinaryReader(buffer), tt.header, 0)
+			frame, err := reader.ReadFrame()
+			require.NoError(t, err)
+			require.Equal(t, []byte{1, 2}, frame)
+			frame, err = reader.ReadFrame()
+			require.NoError(t, err)
+			require.Empty(t, frame)
+			_, err = reader.ReadFrame()
+			require.Equal(t, io.EOF, err)
+		})
+	}
+}
+
+func TestFrameWriter_MaxSizeUint32(t *testing.T) {
+	if strconv.IntSize < 64 {
+		t.Skip("frame size could not exceed uint32 on 32-bit platforms")
+	}
+
+	oversized := uint64(math.MaxUint32) + 1
+	writer := NewFrameWriter(NewBinaryWriter(new(bytes.Buffer)), FrameHeaderUint32, int(^uint(0)>>1))
+	err := writer.WriteObject(wrongSizer(oversized))
+	require.True(t, errors.Is(err, ErrFrameSize), "maximum must be limited by uint32 header, got %v", err)
+}
+
+func TestFrameReader_ReadObject(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	writer := NewFrameWriter(NewBinaryWriter(buffer), FrameHeaderUvarint, 0)
+	require.NoError(t, writer.WriteObject([]byte{1, 2, 3}))
+	require.NoError(t, writer.WriteObject(uint16(0x0405)))
+	require.NoError(t, writer.WriteObject("text"))
+
+	reader := NewFrameReader(NewBinaryReader(buffer), FrameHeaderUvarint, 0)
+	first := new(greedyRecord)
+	require.NoError(t, reader.ReadObject(first))
+	require.Equal(t, []byte{1, 2, 3}, first.data) // greedy reader stopped at frame boundary
+
+	// skips not consumed frame bytes
+	frame, size, err := reader.Next()
+	require.NoError(t, err)
+	require.Equal(t, 2, size)
+	firstByte, err := frame.ReadUint8()
+	require.NoError(t, err)
+	require.Equal(t, uint8(4), firstByte)
+
+	text := new(string)
+	require.NoError(t, reader.ReadObject(text))
+	require.Equal(t, "text", *text)
+}
+
+func TestFrameReader_MaxSize(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	require.True(t, errors.Is(NewFrameWriter(NewBinaryWriter(buffer), FrameHeaderUint32, 2).WriteFrame([]byte{1, 2, 3}), ErrFrameSize))
+	require.Zero(t, buffer.Len())
+
+	require.NoError(t, NewFrameWriter(NewBinaryWriter(buffer), FrameHeaderUint32, 0).WriteFrame([]byte{1, 2, 3}))
+	_, err := NewFrameReader(NewBinaryReader(buffer), FrameHeaderUint32, 2).ReadFrame()
+	require.True(t, errors.Is(err, ErrFrameSize))
+}
+
+func TestFrameReader_Truncated(t *testing.T) {
+	reader := NewFrameReader(NewBinaryReader(bytes.NewBuffer([]byte{0, 4, 1, 2})), FrameHeaderUint16, 0)
+	_, err := reader.ReadFrame()
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+}
@@ -1,5 +1,10 @@
 package binutils
 
+import (
+	"encoding/binary"
+	"io"
+)
+
 type untilStopByteReader interface {
 	// ReadBytes until the first occurrence of stopByte in the input,
 	// returning a slice containing the data up to and including the delimiter.
@@ -10,10 +15,101 @@ type untilStopByteReader interface {
 	ReadBytes(stopByte byte) ([]byte, error)
 }
 
+// Reader describes typed reads shared by BinaryReader and BytesReader.
+// Decoders accepting Reader work with any backend, see BinaryDecoder.
+type Reader interface {
+	io.Reader
+	untilStopByteReader
+
+	ByteOrder() binary.ByteOrder
+	Encoding() TextEncoding
+	BytesTaken() int
+
+	ReadBytesCount(amount int) ([]byte, error)
+	ReadBytesInto(dst []byte) error
+	ReadUint8() (uint8, error)
+	ReadUint16() (uint16, error)
+	ReadUint32() (uint32, error)
+	ReadUint64() (uint64, error)
+	ReadUint() (uint, error)
+	ReadInt8() (int8, error)
+	ReadInt16() (int16, error)
+	ReadInt32() (int32, error)
+	ReadInt64() (int64, error)
+	ReadInt() (int, error)
+	ReadUvarint() (uint64, error)
+	ReadVarint() (int64, error)
+	ReadUTF8Rune() (rune, int, error)
+	UnreadRune() error
+	ReadUTF32Rune() (rune, error)
+	ReadStringZ() (string, error)
+	ReadStringZInto(dst []byte) ([]byte, error)
+	ReadHex(amount int) (string, error)
+	ReadObject(target interface{}) error
+
+	ReadUint16s(dst []uint16) error
+	ReadInt16s(dst []int16) error
+	ReadUint32s(dst []uint32) error
+	ReadInt32s(dst []int32) error
+	ReadUint64s(dst []uint64) error
+	ReadInt64s(dst []int64) error
+	ReadFloat32s(dst []float32) error
+	ReadFloat64s(dst []float64) error
+}
+
+// Writer describes typed writes shared by BinaryWriter and BufferWriter.
+// Encoders accepting Writer work with any backend, see BinaryEncoder.
+type Writer interface {
+	io.Writer
+
+	ByteOrder() binary.ByteOrder
+	Encoding() TextEncoding
+	BytesWritten() int
+
+	WriteUint8(data uint8) error
+	WriteUint16(data uint16) error
+	WriteUint32(data uint32) error
+	WriteUint64(data uint64) error
+	WriteUint(data uint) error
+	WriteInt8(data int8) error
+	WriteInt16(data int16) error
+	WriteInt32(data int32) error
+	WriteInt64(data int64) error
+	WriteInt(data int) error
+	WriteUvarint(data uint64) error
+	WriteVarint(data int64) error
+	WriteUTF8Rune(char rune) error
+	WriteUTF32Rune(char rune) error
+	WriteStringZ(data string) error
+	WriteBytes(data []byte) error
+	WriteHex(hexString string) error
+	WriteObject(data interface{}) error
+
+	WriteUint16s(data []uint16) error
+	WriteInt16s(data []int16) error
+	WriteUint32s(data []uint32) error
+	WriteInt32s(data []int32) error
+	WriteUint64s(data []uint64) error
+	WriteInt64s(data []int64) error
+	WriteFloat32s(data []float32) error
+	WriteFloat64s(data []float64) error
+}
+
+// Check shipped backends implement common interfaces.
+var (
+	_ Reader = (*BinaryReader)(nil)
+	_ Reader = (*BytesReader)(nil)
+	_ Writer = (*BinaryWriter)(nil)
+	_ Writer = (*BufferWriter)(nil)
+	_ Writer = (*SizeWriter)(nil)
+)
+
 // BinaryReaderFrom interface wraps the BinaryReadFrom method.
 // Implementation method BinaryReadFrom reads implementors data from BinaryReader
 // until its data restored or any error encountered.
 // Returns any error encountered during reading if happened or nil.
+//
+// Prefer BinaryDecoder for new types, it is not bound to stream backend.
 type BinaryReaderFrom interface {
 	BinaryReadFrom(*BinaryReader) error
 }
@@ -22,6 +118,74 @@ type BinaryReaderFrom interface {
 // Implementation method BinaryWriteTo writes implementors data into BinaryWriter
 // until all marshalled or any error occurs.
 // Returns any error encountered during writing if happened or nil.
+//
+// Prefer BinaryEncoder for new types, it is not bound to stream backend.
 type BinaryWriterTo interface {
 	BinaryWriteTo(*BinaryWriter) error
 }
+
+// BinaryDecoder interface wraps the DecodeBinary method.
+// Implementation method DecodeBinary reads implementors data from any Reader backend
+// until its data restored or any error encountered.
+// ReadObject of any shipped Reader prefers DecodeBinary over BinaryReadFrom.
+type BinaryDecoder interface {
+	DecodeBinary(Reader) error
+}
+
+// BinaryEncoder interface wraps the EncodeBinary method.
+// Implementation method EncodeBinary writes implementors data into any Writer backend
+// until all marshalled or any error occurs.
+// WriteObject of any shipped Writer prefers EncodeBinary over any other interface.
+type BinaryEncoder interface {
+	EncodeBinary(Writer) error
+}
+
+// AsBinaryReader returns reader itself if it is BinaryReader,
+// otherwise BinaryReader taking bytes from reader using its byte order and text encoding.
+// Use it to pass any Reader to BinaryReaderFrom implementations.
+// Note bytes buffered by adapter, e.g. taken back by UnreadRune, are not returned to reader.
+func AsBinaryReader(reader Reader) *BinaryReader {
+	if binaryReader, ok := reader.(*BinaryReader); ok {
+		return binaryReader
+	}
+
+	adapter := NewBinaryReader(reader)
+	adapter.order, adapter.encoding = reader.ByteOrder(), reader.Encoding()
+
+	return adapter
+}
+
+// runeScanner adapts Reader to io.RuneScanner.
+type runeScanner struct {
+	reader Reader
+}
+
+// ReadRune reads single UTF-8 encoded character using ReadUTF8Rune. Implements io.RuneReader.
+func (s runeScanner) ReadRune() (rune, int, error) {
+	return s.reader.ReadUTF8Rune()
+}
+
+// UnreadRune returns last character back to reader using its UnreadRune. Implements io.RuneScanner.
+func (s runeScanner) UnreadRune() error {
+	return s.reader.UnreadRune()
+}
+
+// AsRuneScanner returns io.RuneScanner reading UTF-8 encoded characters from reader.
+// Reader methods ReadRune and UnreadRune of returned value map to ReadUTF8Rune and UnreadRune of reader.
+func AsRuneScanner(reader Reader) io.RuneScanner {
+	return runeScanner{reader: reader}
+}
+
+// AsBinaryWriter returns writer itself if it is BinaryWriter,
+// otherwise BinaryWriter writing into writer using its byte order and text encoding.
+// Use it to pass any Writer to BinaryWriterTo implementations.
+func AsBinaryWriter(writer Writer) *BinaryWriter {
+	if binaryWriter, ok := writer.(*BinaryWriter); ok {
+		return binaryWriter
+	}
+
+	adapter := NewBinaryWriter(writer)
+	adapter.order, adapter.encoding = writer.ByteOrder(), writer.Encoding()
+
+	return adapter
+}
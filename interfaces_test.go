@@ -0,0 +1,140 @@
+package binutils_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/amarin/binutils"
+)
+
+// point implements backend independent BinaryEncoder and BinaryDecoder.
+type point struct {
+	X, Y int32
+	Name string
+}
+
+func (p point) EncodeBinary(writer Writer) error {
+	if err := writer.WriteInt32s([]int32{p.X, p.Y}); err != nil {
+		return err
+	}
+
+	return writer.WriteStringZ(p.Name)
+}
+
+func (p *point) DecodeBinary(reader Reader) (err error) {
+	if p.X, err = reader.ReadInt32(); err != nil {
+		return err
+	}
+
+	if p.Y, err = reader.ReadInt32(); err != nil {
+		return err
+	}
+
+	p.Name, err = reader.ReadStringZ()
+
+	return err
+}
+
+// legacyPoint implements stream bound BinaryWriterTo and BinaryReaderFrom.
+type legacyPoint struct {
+	X, Y int16
+}
+
+func (p legacyPoint) BinaryWriteTo(writer *BinaryWriter) error {
+	return writer.WriteInt16s([]int16{p.X, p.Y})
+}
+
+func (p *legacyPoint) BinaryReadFrom(reader *BinaryReader) error {
+	values := make([]int16, 2)
+	if err := reader.ReadInt16s(values); err != nil {
+		return err
+	}
+
+	p.X, p.Y = values[0], values[1]
+
+	return nil
+}
+
+func TestBinaryEncoder_Backends(t *testing.T) {
+	expected := []byte{
+		1, 0, 0, 0, 0xfe, 0xff, 0xff, 0xff, 'a', 0, // point
+		3, 0, 4, 0, // legacyPoint
+	}
+
+	stream := new(bytes.Buffer)
+	streamWriter := NewBinaryWriter(stream)
+	bufferWriter := NewBinaryWriterBuffer(nil)
+
+	for _, writer := range []Writer{streamWriter, bufferWriter} {
+		switch typed := writer.(type) {
+		case *BinaryWriter:
+			typed.SetByteOrder(binary.LittleEndian)
+		case *BufferWriter:
+			typed.SetByteOrder(binary.LittleEndian)
+		}
+
+		require.NoError(t, writer.WriteObject(point{X: 1, Y: -2, Name: "a"}))
+		require.NoError(t, writer.WriteObject(legacyPoint{X: 3, Y: 4}))
+		require.Equal(t, len(expected), writer.BytesWritten())
+	}
+
+	require.Equal(t, expected, stream.Bytes())
+	require.Equal(t, expected, bufferWriter.Bytes())
+
+	streamReader := NewBinaryReader(bytes.NewReader(expected))
+	bytesReader := NewBinaryReaderBytes(expected)
+
+	for _, reader := range []Reader{streamReader, bytesReader} {
+		switch typed := reader.(type) {
+		case *BinaryReader:
+			typed.SetByteOrder(binary.LittleEndian)
+		case *BytesReader:
+			typed.SetByteOrder(binary.LittleEndian)
+		}
+
+		decoded := new(point)
+		require.NoError(t, reader.ReadObject(decoded))
+		require.Equal(t, point{X: 1, Y: -2, Name: "a"}, *decoded)
+
+		legacy := new(legacyPoint)
+		require.NoError(t, reader.ReadObject(legacy))
+		require.Equal(t, legacyPoint{X: 3, Y: 4}, *legacy)
+		require.Equal(t, len(expected), reader.BytesTaken())
+	}
+}
+
+func TestAsBinaryReader(t *testing.T) {
+	streamReader := NewBinaryReader(bytes.NewReader(nil))
+	require.True(t, streamReader == AsBinaryReader(streamReader))
+
+	bytesReader := NewBinaryReaderBytes([]byte{2, 0, 1, 0, 0xff})
+	bytesReader.SetByteOrder(binary.LittleEndian)
+	bytesReader.SetEncoding(UTF16LE)
+
+	adapter := AsBinaryReader(bytesReader)
+	require.Equal(t, binary.LittleEndian, adapter.ByteOrder())
+	require.Equal(t, UTF16LE, adapter.Encoding())
+
+	legacy := new(legacyPoint)
+	require.NoError(t, legacy.BinaryReadFrom(adapter))
+	require.Equal(t, legacyPoint{X: 2, Y: 1}, *legacy)
+	require.Equal(t, 1, bytesReader.Remaining())
+}
+
+func TestAsBinaryWriter(t *testing.T) {
+	streamWriter := NewBinaryWriter(new(bytes.Buffer))
+	require.True(t, streamWriter == AsBinaryWriter(streamWriter))
+
+	bufferWriter := NewBinaryWriterBuffer(nil)
+	bufferWriter.SetEncoding(Windows1251)
+
+	adapter := AsBinaryWriter(bufferWriter)
+	require.Equal(t, binary.BigEndian, adapter.ByteOrder())
+	require.Equal(t, Windows1251, adapter.Encoding())
+
+	require.NoError(t, legacyPoint{X: 2, Y: 1}.BinaryWriteTo(adapter))
+	require.Equal(t, []byte{0, 2, 0, 1}, bufferWriter.Bytes())
+}
@@ -0,0 +1,120 @@
+package binutils
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+)
+
+// limitedSource reads at most remaining bytes from parent BinaryReader.
+type limitedSource struct {
+	parent    *BinaryReader
+	remaining int
+}
+
+// Read reads up to remaining bytes from parent reader. Implements io.Reader.
+// Returns io.EOF when limit reached or io.ErrUnexpectedEOF if parent ends before limit.
+func (l *limitedSource) Read(p []byte) (n int, err error) {
+	if l.remaining <= 0 {
+		return 0, io.EOF
+	}
+
+	if len(p) > l.remaining {
+		p = p[:l.remaining]
+	}
+
+	n, err = l.parent.Read(p)
+	l.remaining -= n
+
+	if err == io.EOF && l.remaining > 0 {
+		err = io.ErrUnexpectedEOF
+	}
+
+	return n, err
+}
+
+// derive creates BinaryReader over source using the same byte order, text encoding, strict modes and envelope version.
+func (r *BinaryReader) derive(source io.Reader) *BinaryReader {
+	derived := NewBinaryReader(source)
+
+	r.mu.Lock()
+	derived.order, derived.encoding = r.order, r.encoding
+	derived.strictUTF8, derived.strictFinish = r.strictUTF8, r.strictFinish
+	derived.tracer, derived.traceOffset = r.tracer, r.traceOffset+r.bytesTaken
+	derived.version, derived.versioned = r.version, r.versioned
+	r.mu.Unlock()
+
+	return derived
+}
+
+// Sub returns BinaryReader taking at most amount bytes from r and reporting io.EOF at the boundary.
+// Sub reader inherits byte order, text encoding, strict modes and envelope version of r.
+// Bytes taken by sub reader are counted by both sub reader and r.
+// Use Finish or Drain to skip bytes left unread by nested decoder.
+func (r *BinaryReader) Sub(amount int) *BinaryReader {
+	return r.derive(&limitedSource{parent: r, remaining: amount})
+}
+
+// Remaining returns bytes count left unread in reader created by Sub or -1 if reader is not limited.
+func (r *BinaryReader) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	limited, ok := r.source.(*limitedSource)
+	if !ok {
+		return -1
+	}
+
+	return limited.remaining + len(r.unread)
+}
+
+// SetStrictFinish enables or disables strict finish mode.
+// In strict mode Finish returns ErrUnconsumed if reader has unread bytes left.
+func (r *BinaryReader) SetStrictFinish(strict bool) {
+	r.mu.Lock()
+	r.strictFinish = strict
+	r.mu.Unlock()
+}
+
+// StrictFinish returns true if strict finish mode enabled.
+func (r *BinaryReader) StrictFinish() (strict bool) {
+	r.mu.Lock()
+	strict = r.strictFinish
+	r.mu.Unlock()
+
+	return strict
+}
+
+// Drain reads and discards bytes until reader limit reached or io.EOF encountered.
+// Returns skipped bytes count and any error except io.EOF.
+func (r *BinaryReader) Drain() (skipped int, err error) {
+	copied, err := io.Copy(ioutil.Discard, r)
+
+	return int(copied), err
+}
+
+// Finish drains bytes left unread so parent reader continues right after sub reader boundary.
+// Returns ErrUnconsumed if strict finish mode enabled and any bytes skipped.
+func (r *BinaryReader) Finish() error {
+	skipped, err := r.Drain()
+
+	switch {
+	case err != nil:
+		return err
+	case skipped > 0 && r.StrictFinish():
+		return fmt.Errorf("%w: %d bytes left", ErrUnconsumed, skipped)
+	default:
+		return nil
+	}
+}
+
+// derive creates BinaryWriter over writer using the same byte order and text encoding.
+func (w *BinaryWriter) derive(writer io.Writer) *BinaryWriter {
+	derived := NewBinaryWriter(writer)
+
+	w.mu.Lock()
+	derived.order, derived.encoding = w.order, w.encoding
+	w.mu.Unlock()
+
+	return derived
+}
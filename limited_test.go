@@ -0,0 +1,89 @@
+package binutils_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/amarin/binutils"
+)
+
+func TestBinaryReader_Sub(t *testing.T) {
+	reader := NewBinaryReader(bytes.NewBuffer([]byte{0, 1, 2, 3, 4, 5, 6}))
+	require.Equal(t, -1, reader.Remaining())
+
+	sub := reader.Sub(4)
+	require.Equal(t, 4, sub.Remaining())
+
+	value, err := sub.ReadUint16()
+	require.NoError(t, err)
+	require.Equal(t, uint16(0x0001), value)
+	require.Equal(t, 2, sub.Remaining())
+
+	_, err = sub.ReadUint32() // crosses the boundary
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+	require.Equal(t, 0, sub.Remaining())
+
+	_, err = sub.ReadUint8()
+	require.Equal(t, io.EOF, err)
+	require.Equal(t, 4, reader.BytesTaken())
+
+	next, err := reader.ReadUint8()
+	require.NoError(t, err)
+	require.Equal(t, uint8(4), next)
+}
+
+func TestBinaryReader_Sub_Truncated(t *testing.T) {
+	sub := NewBinaryReader(bytes.NewBuffer([]byte{0, 1})).Sub(4)
+	_, err := sub.ReadUint32()
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestBinaryReader_Finish(t *testing.T) {
+	reader := NewBinaryReader(bytes.NewBuffer([]byte{1, 2, 3, 4, 5, 6}))
+
+	sub := reader.Sub(3)
+	_, err := sub.ReadUint8()
+	require.NoError(t, err)
+	require.NoError(t, sub.Finish())
+	require.Equal(t, 0, sub.Remaining())
+	require.Equal(t, 3, reader.BytesTaken())
+
+	reader.SetStrictFinish(true)
+	require.True(t, reader.StrictFinish())
+	sub = reader.Sub(2)
+	require.True(t, sub.StrictFinish()) // inherited
+	require.True(t, errors.Is(sub.Finish(), ErrUnconsumed))
+
+	next, err := reader.ReadUint8() // still aligned after failed finish
+	require.NoError(t, err)
+	require.Equal(t, uint8(6), next)
+
+	sub = reader.Sub(0)
+	require.NoError(t, sub.Finish())
+}
+
+func TestBinaryReader_Drain(t *testing.T) {
+	reader := NewBinaryReader(bytes.NewBuffer([]byte{1, 2, 3, 4, 5}))
+	sub := reader.Sub(3)
+	skipped, err := sub.Drain()
+	require.NoError(t, err)
+	require.Equal(t, 3, skipped)
+
+	skipped, err = reader.Drain()
+	require.NoError(t, err)
+	require.Equal(t, 2, skipped)
+}
+
+func TestBinaryReader_Sub_Nested(t *testing.T) {
+	reader := NewBinaryReader(bytes.NewBuffer([]byte{1, 2, 3, 4, 5}))
+	outer := reader.Sub(4)
+	inner := outer.Sub(2)
+	require.NoError(t, inner.Finish())
+	require.Equal(t, 2, outer.Remaining())
+	require.Equal(t, 2, outer.BytesTaken())
+	require.Equal(t, 2, reader.BytesTaken())
+}
@@ -0,0 +1,261 @@
+// Package logfile implements append-only record log files such as write-ahead logs.
+//
+// Log is a directory of segment files named by the sequence number of their first record.
+// Every record is framed by payload length, CRC-32 of sequence number and payload, and sequence number.
+// Writer appends records rotating segments by size and truncates torn tail left by crash on open.
+// Reader iterates records of all segments verifying CRC and sequence numbers continuity.
+package logfile
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/amarin/binutils"
+)
+
+// Record header layout: payload length uint32, CRC-32 uint32 and sequence number uint64, all big-endian.
+const (
+	headerSize     = binutils.Uint32size + binutils.Uint32size + binutils.Uint64size
+	crcOffset      = binutils.Uint32size
+	sequenceOffset = crcOffset + binutils.Uint32size
+)
+
+// Default option values.
+const (
+	// DefaultMaxSegmentSize is a default segment size rotation threshold.
+	DefaultMaxSegmentSize = 64 << 20
+	// DefaultMaxRecordSize is a default maximum record payload size.
+	DefaultMaxRecordSize = binutils.DefaultMaxFrameSize
+	// FirstSequence is a sequence number of the first record in empty log.
+	FirstSequence = 1
+)
+
+// segmentFormat is a segment file name format, segments named by first record sequence number.
+const segmentFormat = "%020d.log"
+
+// Options configures log writing and reading.
+type Options struct {
+	// MaxSegmentSize is a segment size threshold. New segment started if record does not fit current one.
+	// Zero means DefaultMaxSegmentSize.
+	MaxSegmentSize int64
+	// MaxRecordSize is a maximum record payload size. Zero means DefaultMaxRecordSize.
+	MaxRecordSize int
+	// Sync calls fsync after every appended record if true.
+	Sync bool
+}
+
+// DefaultOptions used by Open and OpenReader if nil options passed.
+var DefaultOptions = Options{MaxSegmentSize: DefaultMaxSegmentSize, MaxRecordSize: DefaultMaxRecordSize} // nolint:gochecknoglobals
+
+// Some predefined errors used during processing.
+var (
+	// Error indicates any log file errors.
+	Error = fmt.Errorf("%w: logfile", binutils.Error)
+
+	// ErrTornTail returned if the last segment ends with incomplete or partially written record.
+	ErrTornTail = fmt.Errorf("%w: torn tail", Error)
+
+	// ErrCorrupt returned if record CRC or sequence number is invalid not at the log tail.
+	ErrCorrupt = fmt.Errorf("%w: corrupt record", Error)
+
+	// ErrRecordSize returned if record payload exceeds maximum record size.
+	ErrRecordSize = fmt.Errorf("%w: record size", Error)
+
+	// ErrFailed returned if writer used after partially written record could not be truncated.
+	ErrFailed = fmt.Errorf("%w: writer failed", Error)
+)
+
+// options returns passed options having zero sizes replaced by defaults, or defaults if nil.
+func options(passed *Options) Options {
+	if passed == nil {
+		return DefaultOptions
+	}
+
+	result := *passed
+	if result.MaxSegmentSize == 0 {
+		result.MaxSegmentSize = DefaultOptions.MaxSegmentSize
+	}
+
+	if result.MaxRecordSize == 0 {
+		result.MaxRecordSize = DefaultOptions.MaxRecordSize
+	}
+
+	return result
+}
+
+// segment describes single segment file.
+type segment struct {
+	path  string
+	first uint64 // sequence number of the first record
+}
+
+// listSegments returns log segments sorted by first sequence number.
+func listSegments(dir string) ([]segment, error) {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var segments []segment
+
+	for _, entry := range entries {
+		var first uint64
+		if entry.IsDir() || len(entry.Name()) != len(fmt.Sprintf(segmentFormat, 0)) {
+			continue
+		}
+
+		if _, err := fmt.Sscanf(entry.Name(), segmentFormat, &first); err != nil {
+			continue
+		}
+
+		segments = append(segments, segment{path: filepath.Join(dir, entry.Name()), first: first})
+	}
+
+	sort.Slice(segments, func(i, j int) bool { return segments[i].first < segments[j].first })
+
+	return segments, nil
+}
+
+// segmentPath returns path of segment starting with specified sequence number.
+func segmentPath(dir string, first uint64) string {
+	return filepath.Join(dir, fmt.Sprintf(segmentFormat, first))
+}
+
+// appendRecord appends framed record to buffer.
+func appendRecord(buffer []byte, sequence uint64, payload []byte) []byte {
+	start := len(buffer)
+	buffer = binutils.AppendUint32(buffer, uint32(len(payload)))
+	buffer = binutils.AppendUint32(buffer, 0) // CRC placeholder
+	buffer = binutils.AppendUint64(buffer, sequence)
+	buffer = append(buffer, payload...)
+
+	sum := crc32.ChecksumIEEE(buffer[start+sequenceOffset:])
+	binary.BigEndian.PutUint32(buffer[start+crcOffset:], sum)
+
+	return buffer
+}
+
+// segmentScanner reads framed records from single segment.
+type segmentScanner struct {
+	reader        *binutils.BinaryReader
+	maxRecordSize int
+	header        [headerSize]byte
+	offset        int64 // end offset of the last valid record
+}
+
+// newSegmentScanner creates scanner reading records from source.
+func newSegmentScanner(source io.Reader, maxRecordSize int) *segmentScanner {
+	return &segmentScanner{reader: binutils.NewBinaryReader(bufio.NewReader(source)), maxRecordSize: maxRecordSize}
+}
+
+// next reads next record. Returns io.EOF at clean segment end,
+// ErrTornTail if segment ends inside record or the last record CRC is invalid,
+// and ErrCorrupt if invalid record is followed by more data.
+func (s *segmentScanner) next() (sequence uint64, payload []byte, err error) {
+	if err = s.reader.ReadBytesInto(s.header[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, nil, fmt.Errorf("%w: incomplete header at offset %d", ErrTornTail, s.offset)
+		}
+
+		return 0, nil, err
+	}
+
+	size := binary.BigEndian.Uint32(s.header[:])
+	sum := binary.BigEndian.Uint32(s.header[crcOffset:])
+	sequence = binary.BigEndian.Uint64(s.header[sequenceOffset:])
+
+	if int64(size) > int64(s.maxRecordSize) {
+		return 0, nil, s.invalid(fmt.Sprintf("record size %d", size))
+	}
+
+	payload = make([]byte, size)
+	if err = s.reader.ReadBytesInto(payload); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, nil, fmt.Errorf("%w: incomplete record at offset %d", ErrTornTail, s.offset)
+		}
+
+		return 0, nil, err
+	}
+
+	hash := crc32.NewIEEE()
+	_, _ = hash.Write(s.header[sequenceOffset:]) // never returns error
+	_, _ = hash.Write(payload)                   // never returns error
+
+	if hash.Sum32() != sum {
+		return 0, nil, s.invalid("crc mismatch")
+	}
+
+	s.offset += int64(headerSize) + int64(size)
+
+	return sequence, payload, nil
+}
+
+// invalid returns ErrTornTail if no data follows invalid record or ErrCorrupt otherwise.
+func (s *segmentScanner) invalid(reason string) error {
+	skipped, err := s.reader.Drain()
+
+	switch {
+	case err != nil:
+		return err
+	case skipped > 0:
+		return fmt.Errorf("%w: %v at offset %d", ErrCorrupt, reason, s.offset)
+	default:
+		return fmt.Errorf("%w: %v at offset %d", ErrTornTail, reason, s.offset)
+	}
+}
+
+// Repair truncates torn tail of the last log segment.
+// Returns number of bytes truncated or ErrCorrupt if invalid record is followed by more data. Missing log directory is not an error.
+func Repair(dir string, passed *Options) (truncated int64, err error) {
+	segments, err := listSegments(dir)
+
+	switch {
+	case os.IsNotExist(err):
+		return 0, nil
+	case err != nil || len(segments) == 0:
+		return 0, err
+	}
+
+	last := segments[len(segments)-1]
+
+	file, err := os.OpenFile(last.path, os.O_RDWR, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	defer file.Close() // nolint:errcheck
+
+	scanner := newSegmentScanner(file, options(passed).MaxRecordSize)
+
+	for {
+		_, _, err = scanner.next()
+
+		switch {
+		case err == nil:
+			continue
+		case errors.Is(err, io.EOF):
+			return 0, nil
+		case !errors.Is(err, ErrTornTail):
+			return 0, err
+		}
+
+		info, err := file.Stat()
+		if err != nil {
+			return 0, err
+		}
+
+		if err = file.Truncate(scanner.offset); err != nil {
+			return 0, err
+		}
+
+		return info.Size() - scanner.offset, file.Sync()
+	}
+}
@@ -0,0 +1,242 @@
+package logfile_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amarin/binutils"
+	"github.com/amarin/binutils/logfile"
+)
+
+type entry struct {
+	ID   uint32
+	Name string
+}
+
+func (e entry) BinaryWriteTo(writer *binutils.BinaryWriter) error {
+	if err := writer.WriteUint32(e.ID); err != nil {
+		return err
+	}
+
+	return writer.WriteStringZ(e.Name)
+}
+
+func (e *entry) BinaryReadFrom(reader *binutils.BinaryReader) (err error) {
+	if e.ID, err = reader.ReadUint32(); err != nil {
+		return err
+	}
+
+	e.Name, err = reader.ReadStringZ()
+
+	return err
+}
+
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "logfile")
+	require.NoError(t, err)
+
+	return dir
+}
+
+func appendEntries(t *testing.T, dir string, options *logfile.Options, from, to uint32) {
+	writer, err := logfile.Open(dir, options)
+	require.NoError(t, err)
+
+	for id := from; id <= to; id++ {
+		sequence, err := writer.Append(entry{ID: id, Name: fmt.Sprintf("entry-%d", id)})
+		require.NoError(t, err)
+		require.Equal(t, uint64(id), sequence)
+	}
+
+	require.Equal(t, uint64(to+1), writer.NextSequence())
+	require.NoError(t, writer.Close())
+}
+
+func readEntries(t *testing.T, dir string) (entries []entry, err error) {
+	reader, err := logfile.OpenReader(dir, nil)
+	require.NoError(t, err)
+
+	defer func() { require.NoError(t, reader.Close()) }()
+
+	for {
+		record, err := reader.Next()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return entries, nil
+			}
+
+			return entries, err
+		}
+
+		var value entry
+		require.NoError(t, record.Decode(&value))
+		require.Equal(t, uint64(value.ID), record.Sequence)
+
+		entries = append(entries, value)
+	}
+}
+
+func segmentNames(t *testing.T, dir string) (names []string) {
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+
+	for _, file := range files {
+		names = append(names, file.Name())
+	}
+
+	return names
+}
+
+func TestWriter_Rotation(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir) // nolint:errcheck
+
+	options := logfile.DefaultOptions
+	options.MaxSegmentSize = 60 // two 28 bytes records per segment
+
+	appendEntries(t, dir, &options, 1, 3)
+	appendEntries(t, dir, &options, 4, 5) // reopened writer continues sequence and last segment
+
+	require.Equal(t, []string{
+		"00000000000000000001.log",
+		"00000000000000000003.log",
+		"00000000000000000005.log",
+	}, segmentNames(t, dir))
+
+	entries, err := readEntries(t, dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 5)
+	require.Equal(t, entry{ID: 5, Name: "entry-5"}, entries[4])
+}
+
+func TestReader_TornTail(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir) // nolint:errcheck
+
+	appendEntries(t, dir, nil, 1, 3)
+
+	path := filepath.Join(dir, "00000000000000000001.log")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(path, info.Size()-3)) // crash during last record write
+
+	entries, err := readEntries(t, dir)
+	require.True(t, errors.Is(err, logfile.ErrTornTail), err)
+	require.Len(t, entries, 2)
+
+	truncated, err := logfile.Repair(dir, nil)
+	require.NoError(t, err)
+	require.Equal(t, int64(28-3), truncated) // partially written record removed
+
+	appendEntries(t, dir, nil, 3, 4) // sequence continues after the last valid record
+
+	entries, err = readEntries(t, dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+}
+
+func TestReader_Corrupt(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		offset   int64 // offset of byte to damage from file end
+		expected error
+		valid    int
+	}{
+		{"last_record_crc", 1, logfile.ErrTornTail, 2},
+		{"middle_record_crc", 30, logfile.ErrCorrupt, 1},
+		{"first_record_size", 84, logfile.ErrCorrupt, 0},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			dir := tempDir(t)
+			defer os.RemoveAll(dir) // nolint:errcheck
+
+			appendEntries(t, dir, nil, 1, 3)
+
+			path := filepath.Join(dir, "00000000000000000001.log")
+			data, err := ioutil.ReadFile(path)
+			require.NoError(t, err)
+			require.Len(t, data, 3*28)
+
+			data[int64(len(data))-tt.offset] ^= 0xff
+			require.NoError(t, ioutil.WriteFile(path, data, 0o644))
+
+			entries, err := readEntries(t, dir)
+			require.True(t, errors.Is(err, tt.expected), err)
+			require.Len(t, entries, tt.valid)
+
+			_, err = logfile.Open(dir, nil)
+			if tt.expected == logfile.ErrTornTail {
+				require.NoError(t, err)
+			} else {
+				require.True(t, errors.Is(err, logfile.ErrCorrupt), err)
+			}
+		})
+	}
+}
+
+func TestReader_SegmentGap(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir) // nolint:errcheck
+
+	options := logfile.DefaultOptions
+	options.MaxSegmentSize = 30
+
+	appendEntries(t, dir, &options, 1, 3)
+	require.NoError(t, os.Remove(filepath.Join(dir, "00000000000000000002.log")))
+
+	entries, err := readEntries(t, dir)
+	require.True(t, errors.Is(err, logfile.ErrCorrupt), err)
+	require.Len(t, entries, 1)
+}
+
+func TestWriter_AppendBytes(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir) // nolint:errcheck
+
+	writer, err := logfile.Open(filepath.Join(dir, "nested"), &logfile.Options{MaxSegmentSize: 1024, MaxRecordSize: 4, Sync: true})
+	require.NoError(t, err)
+
+	_, err = writer.AppendBytes([]byte{1, 2, 3, 4, 5})
+	require.True(t, errors.Is(err, logfile.ErrRecordSize))
+
+	sequence, err := writer.AppendBytes([]byte{1, 2, 3, 4})
+	require.NoError(t, err)
+	require.Equal(t, uint64(logfile.FirstSequence), sequence)
+	require.NoError(t, writer.Sync())
+	require.NoError(t, writer.Close())
+
+	reader, err := logfile.OpenReader(filepath.Join(dir, "nested"), nil)
+	require.NoError(t, err)
+
+	record, err := reader.Next()
+	require.NoError(t, err)
+	require.Equal(t, int64(0), record.Offset)
+
+	payload, err := record.Bytes()
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3, 4}, payload)
+
+	_, err = reader.Next()
+	require.True(t, errors.Is(err, io.EOF))
+	require.NoError(t, reader.Close())
+}
+
+func TestOpen_PartialOptions(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir) // nolint:errcheck
+
+	appendEntries(t, dir, &logfile.Options{Sync: true}, 1, 3) // zero sizes are taken from defaults
+	require.Equal(t, []string{"00000000000000000001.log"}, segmentNames(t, dir))
+
+	entries, err := readEntries(t, dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+}
@@ -0,0 +1,137 @@
+package logfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/amarin/binutils"
+)
+
+// Record represents single record taken by Reader.
+type Record struct {
+	Sequence uint64                 // record sequence number
+	Value    *binutils.BinaryReader // payload reader
+	Segment  string                 // segment file path
+	Offset   int64                  // record header offset in segment
+}
+
+// Decode decodes record payload into target using BinaryReadFrom.
+// Payload bytes left unread are skipped, or binutils.ErrUnconsumed returned in strict finish mode.
+func (r *Record) Decode(target binutils.BinaryReaderFrom) error {
+	if err := target.BinaryReadFrom(r.Value); err != nil {
+		return err
+	}
+
+	return r.Value.Finish()
+}
+
+// Bytes reads record payload bytes not taken yet.
+func (r *Record) Bytes() ([]byte, error) {
+	return r.Value.ReadBytesCount(r.Value.Remaining())
+}
+
+// Reader iterates over records of all log segments.
+type Reader struct {
+	options  Options
+	segments []segment
+	file     *os.File
+	scanner  *segmentScanner
+	expected uint64 // expected sequence number of the next record
+}
+
+// OpenReader opens log directory for reading. If passed options is nil, DefaultOptions used.
+func OpenReader(dir string, passed *Options) (*Reader, error) {
+	segments, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	reader := &Reader{options: options(passed), segments: segments}
+	if len(segments) > 0 {
+		reader.expected = segments[0].first
+	}
+
+	return reader, nil
+}
+
+// Next reads next record verifying its CRC and sequence number.
+// Returns io.EOF after the last record, ErrTornTail if the last segment ends with incomplete
+// or invalid record, and ErrCorrupt if any other record is invalid.
+func (r *Reader) Next() (*Record, error) {
+	for {
+		if r.scanner == nil {
+			if len(r.segments) == 0 {
+				return nil, io.EOF
+			}
+
+			if err := r.openSegment(); err != nil {
+				return nil, err
+			}
+		}
+
+		offset := r.scanner.offset
+		sequence, payload, err := r.scanner.next()
+
+		switch {
+		case errors.Is(err, io.EOF):
+			if err = r.closeSegment(); err != nil {
+				return nil, err
+			}
+
+			continue
+		case errors.Is(err, ErrTornTail) && len(r.segments) > 0: // not the last segment
+			return nil, fmt.Errorf("%w: %v: %v", ErrCorrupt, r.file.Name(), err)
+		case err != nil:
+			return nil, fmt.Errorf("%v: %w", r.file.Name(), err)
+		case sequence != r.expected:
+			return nil, fmt.Errorf("%w: %v: sequence %d, expected %d", ErrCorrupt, r.file.Name(), sequence, r.expected)
+		}
+
+		r.expected++
+
+		return &Record{
+			Sequence: sequence,
+			Value:    binutils.NewBinaryReader(bytes.NewReader(payload)).Sub(len(payload)),
+			Segment:  r.file.Name(),
+			Offset:   offset,
+		}, nil
+	}
+}
+
+// openSegment opens next segment checking it starts with expected sequence number.
+func (r *Reader) openSegment() (err error) {
+	next := r.segments[0]
+	r.segments = r.segments[1:]
+
+	if next.first != r.expected {
+		return fmt.Errorf("%w: segment %v starts with %d, expected %d", ErrCorrupt, next.path, next.first, r.expected)
+	}
+
+	if r.file, err = os.Open(next.path); err != nil {
+		return err
+	}
+
+	r.scanner = newSegmentScanner(r.file, r.options.MaxRecordSize)
+
+	return nil
+}
+
+// closeSegment closes current segment.
+func (r *Reader) closeSegment() error {
+	err := r.file.Close()
+	r.file, r.scanner = nil, nil
+
+	return err
+}
+
+// Close closes current segment if any.
+func (r *Reader) Close() error {
+	if r.file == nil {
+		return nil
+	}
+
+	return r.closeSegment()
+}
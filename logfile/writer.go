@@ -0,0 +1,207 @@
+package logfile
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/amarin/binutils"
+)
+
+// Writer appends records into log directory.
+type Writer struct {
+	dir     string
+	options Options
+	file    *os.File
+	size    int64  // current segment size
+	next    uint64 // next record sequence number
+	failed  error  // error of partially written record left in segment, appends are rejected if set
+	buffer  []byte // record framing buffer
+	payload *bytes.Buffer
+	encoder *binutils.BinaryWriter
+}
+
+// Open opens log directory for appending creating it if missing.
+// Torn tail of the last segment is truncated, see Repair.
+// If passed options is nil, DefaultOptions used.
+func Open(dir string, passed *Options) (*Writer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+
+	if _, err := Repair(dir, passed); err != nil {
+		return nil, err
+	}
+
+	payload := new(bytes.Buffer)
+	w := &Writer{dir: dir, options: options(passed), next: FirstSequence, payload: payload}
+	w.encoder = binutils.NewBinaryWriter(payload)
+
+	segments, err := listSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(segments) == 0 {
+		return w, nil
+	}
+
+	last := segments[len(segments)-1]
+	if w.next, err = lastSequence(last, w.options.MaxRecordSize); err != nil {
+		return nil, err
+	}
+
+	w.next++
+
+	if w.file, err = os.OpenFile(last.path, os.O_WRONLY|os.O_APPEND, 0); err != nil {
+		return nil, err
+	}
+
+	info, err := w.file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	w.size = info.Size()
+
+	return w, nil
+}
+
+// lastSequence returns sequence number of the last record in segment or previous one if segment is empty.
+func lastSequence(last segment, maxRecordSize int) (uint64, error) {
+	file, err := os.Open(last.path)
+	if err != nil {
+		return 0, err
+	}
+
+	defer file.Close() // nolint:errcheck
+
+	scanner := newSegmentScanner(file, maxRecordSize)
+	expected := last.first
+
+	for {
+		sequence, _, err := scanner.next()
+
+		switch {
+		case errors.Is(err, io.EOF):
+			return expected - 1, nil
+		case err != nil:
+			return 0, err
+		case sequence != expected:
+			return 0, fmt.Errorf("%w: sequence %d, expected %d", ErrCorrupt, sequence, expected)
+		}
+
+		expected++
+	}
+}
+
+// NextSequence returns sequence number assigned to the next appended record.
+func (w *Writer) NextSequence() uint64 {
+	return w.next
+}
+
+// Append encodes record using BinaryWriteTo and appends it to the log.
+// Returns sequence number assigned to record.
+func (w *Writer) Append(record binutils.BinaryWriterTo) (uint64, error) {
+	w.payload.Reset()
+
+	if err := record.BinaryWriteTo(w.encoder); err != nil {
+		return 0, err
+	}
+
+	return w.AppendBytes(w.payload.Bytes())
+}
+
+// AppendBytes appends record payload to the log. Returns sequence number assigned to record.
+// Record is written by single write call, so it is either appended completely or leaves torn tail on crash.
+// If write fails, segment is truncated back to drop partially written record. If truncation fails too
+// writer is failed and subsequent appends return ErrFailed. If sync fails after record written,
+// record sequence number is returned along with error and is not reused.
+func (w *Writer) AppendBytes(payload []byte) (uint64, error) {
+	switch {
+	case w.failed != nil:
+		return 0, fmt.Errorf("%w: %v", ErrFailed, w.failed)
+	case len(payload) > w.options.MaxRecordSize:
+		return 0, fmt.Errorf("%w: %d exceeds %d", ErrRecordSize, len(payload), w.options.MaxRecordSize)
+	}
+
+	recordSize := int64(headerSize + len(payload))
+
+	if w.file == nil || (w.size > 0 && w.size+recordSize > w.options.MaxSegmentSize) {
+		if err := w.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	w.buffer = appendRecord(w.buffer[:0], w.next, payload)
+
+	if _, err := w.file.Write(w.buffer); err != nil {
+		if truncateErr := w.file.Truncate(w.size); truncateErr != nil {
+			w.failed = fmt.Errorf("%v: truncate: %v", err, truncateErr)
+		}
+
+		return 0, err
+	}
+
+	sequence := w.next
+	w.size += recordSize
+	w.next++
+
+	if w.options.Sync {
+		if err := w.file.Sync(); err != nil {
+			return sequence, err
+		}
+	}
+
+	return sequence, nil
+}
+
+// rotate closes current segment if any and starts new one named by next sequence number.
+func (w *Writer) rotate() (err error) {
+	if w.file != nil {
+		if err = w.closeSegment(); err != nil {
+			return err
+		}
+	}
+
+	path := segmentPath(w.dir, w.next)
+	if w.file, err = os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL|os.O_APPEND, 0o644); err != nil {
+		return err
+	}
+
+	w.size = 0
+
+	return nil
+}
+
+// closeSegment syncs and closes current segment.
+func (w *Writer) closeSegment() error {
+	if err := w.file.Sync(); err != nil {
+		return err
+	}
+
+	err := w.file.Close()
+	w.file = nil
+
+	return err
+}
+
+// Sync commits appended records to stable storage.
+func (w *Writer) Sync() error {
+	if w.file == nil {
+		return nil
+	}
+
+	return w.file.Sync()
+}
+
+// Close syncs and closes current segment.
+func (w *Writer) Close() error {
+	if w.file == nil {
+		return nil
+	}
+
+	return w.closeSegment()
+}
@@ -0,0 +1,113 @@
+package binutils
+
+import (
+	"io"
+)
+
+// mappedSource reads bytes of memory mapped file.
+type mappedSource struct {
+	data   []byte
+	offset int
+	unmap  func([]byte) error // releases mapping, nil if data is not mapped
+	closed bool
+}
+
+// Read copies up to len(p) bytes of mapped data into p. Implements io.Reader.
+func (m *mappedSource) Read(p []byte) (int, error) {
+	switch {
+	case m.closed:
+		return 0, ErrUnmapped
+	case m.offset >= len(m.data):
+		return 0, io.EOF
+	}
+
+	n := copy(p, m.data[m.offset:])
+	m.offset += n
+
+	return n, nil
+}
+
+// take returns up to amount bytes of mapped data without copying.
+// Returned slice capacity is limited so append could not overwrite following mapped data.
+func (m *mappedSource) take(amount int) ([]byte, error) {
+	switch {
+	case m.closed:
+		return nil, ErrUnmapped
+	case m.offset >= len(m.data) && amount > 0:
+		return nil, io.EOF
+	}
+
+	end := m.offset + amount
+	if end > len(m.data) {
+		end = len(m.data)
+	}
+
+	data := m.data[m.offset:end:end]
+	m.offset = end
+
+	if len(data) < amount {
+		return data, io.ErrUnexpectedEOF
+	}
+
+	return data, nil
+}
+
+// Close releases mapping. Any further reads return ErrUnmapped. Implements io.Closer.
+// Requires owning BinaryReader mutex held, see BinaryReader.Close.
+func (m *mappedSource) Close() error {
+	if m.closed {
+		return nil
+	}
+
+	m.closed = true
+	data := m.data
+	m.data = nil
+
+	if m.unmap == nil || len(data) == 0 {
+		return nil
+	}
+
+	return m.unmap(data)
+}
+
+// SetZeroCopy enables or disables zero-copy mode of reader created by OpenFileMapped.
+// In zero-copy mode ReadBytesCount returns slices referencing mapped memory instead of copies.
+// Such slices are read-only: writing into them crashes the process.
+// They stay valid only until reader Close, which unmaps the file,
+// so copy any data required after Close. Mode has no effect for other readers.
+func (r *BinaryReader) SetZeroCopy(zeroCopy bool) {
+	r.mu.Lock()
+	r.zeroCopy = zeroCopy
+	r.mu.Unlock()
+}
+
+// ZeroCopy returns true if zero-copy mode enabled.
+func (r *BinaryReader) ZeroCopy() (zeroCopy bool) {
+	r.mu.Lock()
+	zeroCopy = r.zeroCopy
+	r.mu.Unlock()
+
+	return zeroCopy
+}
+
+// takeMapped takes amount bytes of mapped memory if zero-copy mode enabled for memory mapped reader.
+// Returns false if bytes should be copied as usual.
+func (r *BinaryReader) takeMapped(amount int) ([]byte, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mapped, ok := r.source.(*mappedSource)
+	if !ok || !r.zeroCopy || len(r.unread) > 0 {
+		return nil, false, nil
+	}
+
+	data, err := mapped.take(amount)
+	r.bytesTaken += len(data)
+
+	if r.tracer != nil {
+		r.tracer.capture(data)
+	}
+	r.lastRuneSize = -1
+
+	return data, true, err
+}
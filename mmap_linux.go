@@ -0,0 +1,47 @@
+//go:build linux
+// +build linux
+
+package binutils
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"syscall"
+)
+
+// OpenFileMapped opens specified file path, maps it into memory read-only and returns BinaryReader over mapping.
+// Reads copy bytes from mapped memory unless zero-copy mode enabled using SetZeroCopy.
+// Close unmaps the file, any reads after Close return ErrUnmapped.
+func OpenFileMapped(filePath string) (*BinaryReader, error) {
+	absFileName, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(absFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	defer file.Close() // nolint:errcheck // mapping stays valid after file closed
+
+	info, err := file.Stat()
+
+	switch {
+	case err != nil:
+		return nil, err
+	case info.Size() == 0: // empty mapping is not allowed
+		return NewBinaryReader(&mappedSource{}), nil
+	case info.Size() > math.MaxInt32 && ^uint(0) == math.MaxUint32:
+		return nil, fmt.Errorf("%w: file size %d exceeds address space", ErrRead, info.Size())
+	}
+
+	data, err := syscall.Mmap(int(file.Fd()), 0, int(info.Size()), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("%w: mmap: %v", ErrRead, err)
+	}
+
+	return NewBinaryReader(&mappedSource{data: data, unmap: syscall.Munmap}), nil
+}
@@ -0,0 +1,27 @@
+//go:build !linux
+// +build !linux
+
+package binutils
+
+import (
+	"io/ioutil"
+	"path/filepath"
+)
+
+// OpenFileMapped opens specified file path and returns BinaryReader over its content.
+// Memory mapping is supported on Linux only, so file content is loaded into memory on other platforms
+// keeping the same zero-copy semantics and lifetime rules, see SetZeroCopy.
+// Any reads after Close return ErrUnmapped.
+func OpenFileMapped(filePath string) (*BinaryReader, error) {
+	absFileName, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := ioutil.ReadFile(absFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewBinaryReader(&mappedSource{data: data}), nil
+}
@@ -0,0 +1,228 @@
+package binutils_test
+
+import (
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"unsafe"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/amarin/binutils"
+)
+
+func mappedFile(t *testing.T, data []byte) (path string, cleanup func()) {
+	dir, err := ioutil.TempDir("", "mmap")
+	require.NoError(t, err)
+
+	path = filepath.Join(dir, "mapped.bin")
+	require.NoError(t, ioutil.WriteFile(path, data, 0o644))
+
+	return path, func() { require.NoError(t, os.RemoveAll(dir)) }
+}
+
+func TestOpenFileMapped(t *testing.T) {
+	path, cleanup := mappedFile(t, []byte{0x01, 0x02, 'a', 'b', 0, 'c', 'd', 'e', 'f'})
+	defer cleanup()
+
+	reader, err := OpenFileMapped(path)
+	require.NoError(t, err)
+	require.False(t, reader.ZeroCopy())
+
+	value, err := reader.ReadUint16()
+	require.NoError(t, err)
+	require.Equal(t, uint16(0x0102), value)
+
+	line, err := reader.ReadStringZ()
+	require.NoError(t, err)
+	require.Equal(t, "ab", line)
+
+	copied, err := reader.ReadBytesCount(2)
+	require.NoError(t, err)
+	require.Equal(t, []byte("cd"), copied)
+	copied[0] = 'x' // copies are writable
+
+	_, err = reader.ReadBytesCount(3)
+	require.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	require.NoError(t, reader.Close())
+}
+
+func TestBinaryReader_SetZeroCopy(t *testing.T) {
+	path, cleanup := mappedFile(t, []byte("0123456789"))
+	defer cleanup()
+
+	reader, err := OpenFileMapped(path)
+	require.NoError(t, err)
+
+	reader.SetZeroCopy(true)
+	require.True(t, reader.ZeroCopy())
+
+	first, err := reader.ReadBytesCount(4)
+	require.NoError(t, err)
+	require.Equal(t, []byte("0123"), first)
+	require.Equal(t, len(first), cap(first)) // append never overwrites mapped data
+
+	second, err := reader.ReadBytesCount(4)
+	require.NoError(t, err)
+	require.Equal(t, []byte("4567"), second)
+	require.Equal(t, 8, reader.BytesTaken())
+
+	// both slices reference adjacent bytes of the same mapping
+	require.Equal(t, uintptr(unsafe.Pointer(&first[0]))+4, uintptr(unsafe.Pointer(&second[0])))
+
+	last, err := reader.ReadBytesCount(4)
+	require.True(t, errors.Is(err, io.ErrUnexpectedEOF))
+	require.Equal(t, []byte("89"), last)
+
+	_, err = reader.ReadBytesCount(1)
+	require.True(t, errors.Is(err, io.EOF))
+
+	// mapped slices must not be used after Close, reader reports ErrUnmapped instead of faulting
+	require.NoError(t, reader.Close())
+	require.NoError(t, reader.Close())
+
+	_, err = reader.ReadBytesCount(1)
+	require.True(t, errors.Is(err, ErrUnmapped))
+
+	_, err = reader.ReadUint8()
+	require.True(t, errors.Is(err, ErrUnmapped))
+}
+
+func TestBinaryReader_Close_Mapped(t *testing.T) {
+	path, cleanup := mappedFile(t, make([]byte, 1<<20))
+	defer cleanup()
+
+	reader, err := OpenFileMapped(path)
+	require.NoError(t, err)
+
+	var (
+		started sync.WaitGroup
+		done    sync.WaitGroup
+		errs    = make(chan error, 4)
+	)
+
+	for i := 0; i < cap(errs); i++ {
+		started.Add(1)
+		done.Add(1)
+
+		go func() {
+			defer done.Done()
+
+			started.Done()
+
+			for {
+				if _, err := reader.ReadBytesCount(64); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+
+	started.Wait()
+	require.NoError(t, reader.Close())
+	done.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.True(t, errors.Is(err, ErrUnmapped) || errors.Is(err, io.EOF), "unexpected error %v", err)
+	}
+
+	_, err = reader.ReadUint8()
+	require.True(t, errors.Is(err, ErrUnmapped), "read after Close must fail, got %v", err)
+	require.NoError(t, reader.Close())
+}
+
+func TestOpenFileMapped_Empty(t *testing.T) {
+	path, cleanup := mappedFile(t, nil)
+	defer cleanup()
+
+	reader, err := OpenFileMapped(path)
+	require.NoError(t, err)
+
+	reader.SetZeroCopy(true)
+
+	data, err := reader.ReadBytesCount(0)
+	require.NoError(t, err)
+	require.Empty(t, data)
+
+	_, err = reader.ReadUint8()
+	require.True(t, errors.Is(err, io.EOF))
+	require.NoError(t, reader.Close())
+
+	_, err = OpenFileMapped(path + ".missing")
+	require.Error(t, err)
+}
+
+func TestBinaryReader_SetZeroCopy_NotMapped(t *testing.T) {
+	source := []byte("abc")
+	reader := NewBinaryReader(&sliceSource{data: source})
+	reader.SetZeroCopy(true)
+
+	data, err := reader.ReadBytesCount(3)
+	require.NoError(t, err)
+	require.Equal(t, []byte("abc"), data)
+
+	data[0] = 'x'
+	require.Equal(t, byte('a'), source[0]) // other readers always return copies
+}
+
+// sliceSource reads bytes of slice.
+type sliceSource struct {
+	data []byte
+}
+
+func (s *sliceSource) Read(p []byte) (int, error) {
+	if len(s.data) == 0 {
+		return 0, io.EOF
+	}
+
+	n := copy(p, s.data)
+	s.data = s.data[n:]
+
+	return n, nil
+}
+
+func BenchmarkOpenFileMapped_ReadBytesCount(b *testing.B) {
+	dir, err := ioutil.TempDir("", "mmap")
+	require.NoError(b, err)
+
+	defer os.RemoveAll(dir) // nolint:errcheck
+
+	path := filepath.Join(dir, "mapped.bin")
+	require.NoError(b, ioutil.WriteFile(path, make([]byte, 1<<20), 0o644))
+
+	for _, zeroCopy := range []bool{false, true} {
+		name := "copy"
+		if zeroCopy {
+			name = "zero_copy"
+		}
+
+		b.Run(name, func(b *testing.B) {
+			reader, err := OpenFileMapped(path)
+			require.NoError(b, err)
+
+			reader.SetZeroCopy(zeroCopy)
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				if _, err := reader.ReadBytesCount(1024); err != nil { // reopen at file end
+					b.StopTimer()
+					require.NoError(b, reader.Close())
+
+					reader, err = OpenFileMapped(path)
+					require.NoError(b, err)
+					reader.SetZeroCopy(zeroCopy)
+					b.StartTimer()
+				}
+			}
+
+			b.StopTimer()
+			require.NoError(b, reader.Close())
+		})
+	}
+}
@@ -0,0 +1,825 @@
+package msgpack
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/amarin/binutils"
+)
+
+// Limits restricts decoded data to protect decoder from malicious input.
+type Limits struct {
+	MaxDepth        int // maximum nesting level of arrays and maps
+	MaxContainerLen int // maximum number of array items or map pairs
+	MaxStringLen    int // maximum str, bin or extension data length
+}
+
+// DefaultLimits used by decoders unless changed by SetLimits.
+var DefaultLimits = Limits{ // nolint:gochecknoglobals
+	MaxDepth:        64,
+	MaxContainerLen: 1 << 16,
+	MaxStringLen:    binutils.DefaultMaxFrameSize,
+}
+
+// Decoder reads MessagePack encoded values from binutils.BinaryReader.
+type Decoder struct {
+	reader  *binutils.BinaryReader
+	limits  Limits
+	depth   int
+	scratch [binutils.Uint64size]byte
+}
+
+// NewDecoder creates Decoder reading from reader using DefaultLimits.
+func NewDecoder(reader *binutils.BinaryReader) *Decoder {
+	return &Decoder{reader: reader, limits: DefaultLimits}
+}
+
+// SetLimits sets decoder limits.
+func (d *Decoder) SetLimits(limits Limits) {
+	d.limits = limits
+}
+
+// Limits returns current decoder limits.
+func (d *Decoder) Limits() Limits {
+	return d.limits
+}
+
+// enter increments nesting depth checking limit.
+func (d *Decoder) enter() error {
+	if d.depth >= d.limits.MaxDepth {
+		return fmt.Errorf("%w: depth %d", ErrLimit, d.limits.MaxDepth)
+	}
+
+	d.depth++
+
+	return nil
+}
+
+// leave decrements nesting depth.
+func (d *Decoder) leave() {
+	d.depth--
+}
+
+// checkContainerLen checks container length limit.
+func (d *Decoder) checkContainerLen(length int, err error) (int, error) {
+	if err == nil && length > d.limits.MaxContainerLen {
+		return 0, fmt.Errorf("%w: container length %d", ErrLimit, length)
+	}
+
+	return length, err
+}
+
+// readData reads str, bin or extension data of length bytes checking string length limit.
+func (d *Decoder) readData(length int) ([]byte, error) {
+	if length > d.limits.MaxStringLen {
+		return nil, fmt.Errorf("%w: data length %d", ErrLimit, length)
+	}
+
+	return d.reader.ReadBytesCount(length)
+}
+
+// readCode reads next format code.
+func (d *Decoder) readCode() (byte, error) {
+	return d.reader.ReadUint8()
+}
+
+// readUint reads big-endian unsigned integer of size bytes.
+func (d *Decoder) readUint(size int) (uint64, error) {
+	buffer := d.scratch[:size]
+	if err := d.reader.ReadBytesInto(buffer); err != nil {
+		return 0, err
+	}
+
+	switch size {
+	case binutils.Uint8size:
+		return uint64(buffer[0]), nil
+	case binutils.Uint16size:
+		return uint64(binary.BigEndian.Uint16(buffer)), nil
+	case binutils.Uint32size:
+		return uint64(binary.BigEndian.Uint32(buffer)), nil
+	default:
+		return binary.BigEndian.Uint64(buffer), nil
+	}
+}
+
+// readLength reads length of size bytes following format code.
+func (d *Decoder) readLength(size int) (int, error) {
+	length, err := d.readUint(size)
+	if err == nil && length > math.MaxInt32 {
+		return 0, fmt.Errorf("%w: length %d", ErrOverflow, length)
+	}
+
+	return int(length), err
+}
+
+// invalidCode returns ErrInvalidCode annotated by code and expected family.
+func invalidCode(code byte, expected string) error {
+	return fmt.Errorf("%w: %#02x, expected %v", ErrInvalidCode, code, expected)
+}
+
+// DecodeNil reads nil value.
+func (d *Decoder) DecodeNil() error {
+	code, err := d.readCode()
+	if err == nil && code != codeNil {
+		return invalidCode(code, "nil")
+	}
+
+	return err
+}
+
+// DecodeBool reads bool value.
+func (d *Decoder) DecodeBool() (bool, error) {
+	code, err := d.readCode()
+
+	switch {
+	case err != nil:
+		return false, err
+	case code == codeTrue:
+		return true, nil
+	case code == codeFalse:
+		return false, nil
+	default:
+		return false, invalidCode(code, "bool")
+	}
+}
+
+// decodeInteger reads any integer family value following code.
+// Returns value bits and true if value is signed negative.
+func (d *Decoder) decodeInteger(code byte) (bits uint64, negative bool, err error) {
+	switch {
+	case code <= codePositiveFixIntMax:
+		return uint64(code), false, nil
+	case code >= codeNegativeFixIntMin:
+		return uint64(int64(int8(code))), true, nil
+	}
+
+	switch code {
+	case codeUint8, codeUint16, codeUint32, codeUint64:
+		bits, err = d.readUint(1 << (code - codeUint8))
+
+		return bits, false, err
+	case codeInt8:
+		bits, err = d.readUint(binutils.Int8size)
+		bits = uint64(int64(int8(bits)))
+	case codeInt16:
+		bits, err = d.readUint(binutils.Int16size)
+		bits = uint64(int64(int16(bits)))
+	case codeInt32:
+		bits, err = d.readUint(binutils.Int32size)
+		bits = uint64(int64(int32(bits)))
+	case codeInt64:
+		bits, err = d.readUint(binutils.Int64size)
+	default:
+		return 0, false, invalidCode(code, "integer")
+	}
+
+	return bits, int64(bits) < 0, err
+}
+
+// DecodeInt reads any integer family value as int64.
+// Returns ErrOverflow if unsigned value exceeds math.MaxInt64.
+func (d *Decoder) DecodeInt() (int64, error) {
+	code, err := d.readCode()
+	if err != nil {
+		return 0, err
+	}
+
+	bits, negative, err := d.decodeInteger(code)
+	if err == nil && !negative && bits > math.MaxInt64 {
+		return 0, fmt.Errorf("%w: %d", ErrOverflow, bits)
+	}
+
+	return int64(bits), err
+}
+
+// DecodeUint reads any integer family value as uint64.
+// Returns ErrOverflow if value is negative.
+func (d *Decoder) DecodeUint() (uint64, error) {
+	code, err := d.readCode()
+	if err != nil {
+		return 0, err
+	}
+
+	bits, negative, err := d.decodeInteger(code)
+	if err == nil && negative {
+		return 0, fmt.Errorf("%w: %d", ErrOverflow, int64(bits))
+	}
+
+	return bits, err
+}
+
+// DecodeFloat64 reads float or integer family value as float64.
+func (d *Decoder) DecodeFloat64() (float64, error) {
+	code, err := d.readCode()
+	if err != nil {
+		return 0, err
+	}
+
+	return d.decodeFloat(code)
+}
+
+// decodeFloat reads float or integer family value following code.
+func (d *Decoder) decodeFloat(code byte) (float64, error) {
+	switch code {
+	case codeFloat32:
+		bits, err := d.readUint(binutils.Uint32size)
+		return float64(math.Float32frombits(uint32(bits))), err
+	case codeFloat64:
+		bits, err := d.readUint(binutils.Uint64size)
+		return math.Float64frombits(bits), err
+	}
+
+	bits, negative, err := d.decodeInteger(code)
+	if negative {
+		return float64(int64(bits)), err
+	}
+
+	return float64(bits), err
+}
+
+// stringLength returns str family value length following code.
+func (d *Decoder) stringLength(code byte) (int, error) {
+	switch {
+	case code&0xe0 == codeFixStr:
+		return int(code &^ 0xe0), nil
+	case code == codeStr8:
+		return d.readLength(binutils.Uint8size)
+	case code == codeStr16:
+		return d.readLength(binutils.Uint16size)
+	case code == codeStr32:
+		return d.readLength(binutils.Uint32size)
+	default:
+		return 0, invalidCode(code, "string")
+	}
+}
+
+// bytesLength returns bin family value length following code.
+func (d *Decoder) bytesLength(code byte) (int, error) {
+	switch code {
+	case codeBin8:
+		return d.readLength(binutils.Uint8size)
+	case codeBin16:
+		return d.readLength(binutils.Uint16size)
+	case codeBin32:
+		return d.readLength(binutils.Uint32size)
+	default:
+		return 0, invalidCode(code, "binary")
+	}
+}
+
+// DecodeString reads str family value. Bin family values are accepted too.
+func (d *Decoder) DecodeString() (string, error) {
+	code, err := d.readCode()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := d.decodeRaw(code)
+
+	return string(data), err
+}
+
+// DecodeBytes reads bin family value. Str family values are accepted too, nil is decoded as nil slice.
+func (d *Decoder) DecodeBytes() ([]byte, error) {
+	code, err := d.readCode()
+	if err != nil || code == codeNil {
+		return nil, err
+	}
+
+	return d.decodeRaw(code)
+}
+
+// decodeRaw reads str or bin family value bytes following code.
+func (d *Decoder) decodeRaw(code byte) ([]byte, error) {
+	length, err := d.stringLength(code)
+	if err != nil {
+		if length, err = d.bytesLength(code); err != nil {
+			return nil, invalidCode(code, "string or binary")
+		}
+	}
+
+	return d.readData(length)
+}
+
+// arrayLength returns array family length following code checking container length limit.
+func (d *Decoder) arrayLength(code byte) (length int, err error) {
+	switch {
+	case code&0xf0 == codeFixArray:
+		length = int(code &^ 0xf0)
+	case code == codeArray16:
+		length, err = d.readLength(binutils.Uint16size)
+	case code == codeArray32:
+		length, err = d.readLength(binutils.Uint32size)
+	default:
+		return 0, invalidCode(code, "array")
+	}
+
+	return d.checkContainerLen(length, err)
+}
+
+// mapLength returns map family length following code checking container length limit.
+func (d *Decoder) mapLength(code byte) (length int, err error) {
+	switch {
+	case code&0xf0 == codeFixMap:
+		length = int(code &^ 0xf0)
+	case code == codeMap16:
+		length, err = d.readLength(binutils.Uint16size)
+	case code == codeMap32:
+		length, err = d.readLength(binutils.Uint32size)
+	default:
+		return 0, invalidCode(code, "map")
+	}
+
+	return d.checkContainerLen(length, err)
+}
+
+// DecodeArrayLen reads array header. Nil is decoded as -1 length.
+func (d *Decoder) DecodeArrayLen() (int, error) {
+	code, err := d.readCode()
+	if err != nil || code == codeNil {
+		return -1, err
+	}
+
+	return d.arrayLength(code)
+}
+
+// DecodeMapLen reads map header. Nil is decoded as -1 length.
+func (d *Decoder) DecodeMapLen() (int, error) {
+	code, err := d.readCode()
+	if err != nil || code == codeNil {
+		return -1, err
+	}
+
+	return d.mapLength(code)
+}
+
+// DecodeExt reads extension value.
+func (d *Decoder) DecodeExt() (*Ext, error) {
+	code, err := d.readCode()
+	if err != nil {
+		return nil, err
+	}
+
+	return d.decodeExt(code)
+}
+
+// decodeExt reads extension value following code.
+func (d *Decoder) decodeExt(code byte) (ext *Ext, err error) {
+	var length int
+
+	switch code {
+	case codeFixExt1, codeFixExt2, codeFixExt4, codeFixExt8, codeFixExt16:
+		length = 1 << (code - codeFixExt1)
+	case codeExt8:
+		length, err = d.readLength(binutils.Uint8size)
+	case codeExt16:
+		length, err = d.readLength(binutils.Uint16size)
+	case codeExt32:
+		length, err = d.readLength(binutils.Uint32size)
+	default:
+		return nil, invalidCode(code, "extension")
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	extType, err := d.reader.ReadInt8()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := d.readData(length)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Ext{Type: extType, Data: data}, nil
+}
+
+// DecodeTime reads timestamp extension value.
+func (d *Decoder) DecodeTime() (time.Time, error) {
+	ext, err := d.DecodeExt()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return extTime(ext)
+}
+
+// extTime converts timestamp extension into time.
+func extTime(ext *Ext) (time.Time, error) {
+	if ext.Type != TimestampType {
+		return time.Time{}, fmt.Errorf("%w: extension type %d", ErrTimestamp, ext.Type)
+	}
+
+	switch len(ext.Data) {
+	case 4:
+		return time.Unix(int64(binary.BigEndian.Uint32(ext.Data)), 0), nil
+	case 8:
+		bits := binary.BigEndian.Uint64(ext.Data)
+		nanoseconds := int64(bits >> 34)
+
+		if nanoseconds > 999999999 {
+			return time.Time{}, fmt.Errorf("%w: nanoseconds %d", ErrTimestamp, nanoseconds)
+		}
+
+		return time.Unix(int64(bits&(1<<34-1)), nanoseconds), nil
+	case 12:
+		nanoseconds := int64(binary.BigEndian.Uint32(ext.Data))
+		if nanoseconds > 999999999 {
+			return time.Time{}, fmt.Errorf("%w: nanoseconds %d", ErrTimestamp, nanoseconds)
+		}
+
+		return time.Unix(int64(binary.BigEndian.Uint64(ext.Data[4:])), nanoseconds), nil
+	default:
+		return time.Time{}, fmt.Errorf("%w: data length %d", ErrTimestamp, len(ext.Data))
+	}
+}
+
+// DecodeInterface reads next value of any type.
+// Returns nil, bool, int64 for signed and positive fixint values, uint64 for uint family values,
+// float32, float64, string, []byte, []interface{}, map[interface{}]interface{},
+// time.Time for timestamp extension values or *Ext for other extension values.
+func (d *Decoder) DecodeInterface() (interface{}, error) {
+	code, err := d.readCode()
+	if err != nil {
+		return nil, err
+	}
+
+	return d.decodeInterface(code)
+}
+
+// decodeInterface reads value of any type following code.
+func (d *Decoder) decodeInterface(code byte) (interface{}, error) {
+	switch {
+	case code <= codePositiveFixIntMax || code >= codeNegativeFixIntMin:
+		return int64(int8(code)), nil
+	case code&0xe0 == codeFixStr:
+		data, err := d.decodeRaw(code)
+		return string(data), err
+	case code&0xf0 == codeFixArray:
+		return d.decodeArray(code)
+	case code&0xf0 == codeFixMap:
+		return d.decodeMap(code)
+	}
+
+	switch code {
+	case codeNil:
+		return nil, nil
+	case codeFalse, codeTrue:
+		return code == codeTrue, nil
+	case codeUint8, codeUint16, codeUint32, codeUint64:
+		bits, _, err := d.decodeInteger(code)
+		return bits, err
+	case codeInt8, codeInt16, codeInt32, codeInt64:
+		bits, _, err := d.decodeInteger(code)
+		return int64(bits), err
+	case codeFloat32:
+		value, err := d.decodeFloat(code)
+		return float32(value), err
+	case codeFloat64:
+		return d.decodeFloat(code)
+	case codeStr8, codeStr16, codeStr32:
+		data, err := d.decodeRaw(code)
+		return string(data), err
+	case codeBin8, codeBin16, codeBin32:
+		return d.decodeRaw(code)
+	case codeArray16, codeArray32:
+		return d.decodeArray(code)
+	case codeMap16, codeMap32:
+		return d.decodeMap(code)
+	case codeFixExt1, codeFixExt2, codeFixExt4, codeFixExt8, codeFixExt16, codeExt8, codeExt16, codeExt32:
+		ext, err := d.decodeExt(code)
+		if err != nil || ext.Type != TimestampType {
+			return ext, err
+		}
+
+		return extTime(ext)
+	default:
+		return nil, invalidCode(code, "any value")
+	}
+}
+
+// decodeArray reads array elements following code.
+func (d *Decoder) decodeArray(code byte) ([]interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer d.leave()
+
+	length, err := d.arrayLength(code)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, 0, capacity(length))
+
+	for idx := 0; idx < length; idx++ {
+		value, err := d.DecodeInterface()
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// decodeMap reads map keys and values following code.
+func (d *Decoder) decodeMap(code byte) (map[interface{}]interface{}, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer d.leave()
+
+	length, err := d.mapLength(code)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[interface{}]interface{}, capacity(length))
+
+	for idx := 0; idx < length; idx++ {
+		key, err := d.DecodeInterface()
+		if err != nil {
+			return nil, err
+		}
+
+		if key != nil && !reflect.TypeOf(key).Comparable() {
+			return nil, fmt.Errorf("%w: map key %T", ErrUnsupportedType, key)
+		}
+
+		if values[key], err = d.DecodeInterface(); err != nil {
+			return nil, err
+		}
+	}
+
+	return values, nil
+}
+
+// maxPreallocated limits containers capacity preallocated by declared length.
+const maxPreallocated = 1024
+
+// capacity returns capacity to preallocate for declared container length.
+func capacity(length int) int {
+	if length > maxPreallocated {
+		return maxPreallocated
+	}
+
+	return length
+}
+
+// Decode reads next value into target which must be non-nil pointer.
+// Supported targets are the same as Encoder.Encode supports and interface{}.
+// Nil value sets target to its zero value.
+func (d *Decoder) Decode(target interface{}) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Ptr || value.IsNil() {
+		return fmt.Errorf("%w: %T", binutils.ErrNilPointer, target)
+	}
+
+	code, err := d.readCode()
+	if err != nil {
+		return err
+	}
+
+	return d.decodeValue(code, value.Elem())
+}
+
+// decodeValue reads value following code into target.
+func (d *Decoder) decodeValue(code byte, target reflect.Value) error {
+	if code == codeNil {
+		target.Set(reflect.Zero(target.Type()))
+		return nil
+	}
+
+	switch target.Kind() {
+	case reflect.Ptr:
+		if target.IsNil() {
+			target.Set(reflect.New(target.Type().Elem()))
+		}
+
+		return d.decodeValue(code, target.Elem())
+	case reflect.Interface:
+		value, err := d.decodeInterface(code)
+		if err == nil && value != nil {
+			target.Set(reflect.ValueOf(value))
+		}
+
+		return err
+	case reflect.Bool:
+		if code != codeTrue && code != codeFalse {
+			return invalidCode(code, "bool")
+		}
+
+		target.SetBool(code == codeTrue)
+
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		bits, negative, err := d.decodeInteger(code)
+		if err == nil && ((!negative && bits > math.MaxInt64) || target.OverflowInt(int64(bits))) {
+			return fmt.Errorf("%w: %v", ErrOverflow, target.Type())
+		}
+
+		target.SetInt(int64(bits))
+
+		return err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		bits, negative, err := d.decodeInteger(code)
+		if err == nil && (negative || target.OverflowUint(bits)) {
+			return fmt.Errorf("%w: %v", ErrOverflow, target.Type())
+		}
+
+		target.SetUint(bits)
+
+		return err
+	case reflect.Float32, reflect.Float64:
+		value, err := d.decodeFloat(code)
+		target.SetFloat(value)
+
+		return err
+	case reflect.String:
+		data, err := d.decodeRaw(code)
+		target.SetString(string(data))
+
+		return err
+	case reflect.Slice:
+		return d.decodeSlice(code, target)
+	case reflect.Array:
+		return d.decodeFixedArray(code, target)
+	case reflect.Map:
+		return d.decodeTypedMap(code, target)
+	case reflect.Struct:
+		return d.decodeStruct(code, target)
+	default:
+		return fmt.Errorf("%w: %v", ErrUnsupportedType, target.Type())
+	}
+}
+
+// decodeSlice reads array or binary value following code into slice target.
+func (d *Decoder) decodeSlice(code byte, target reflect.Value) error {
+	if target.Type().Elem().Kind() == reflect.Uint8 {
+		data, err := d.decodeRaw(code)
+		if err != nil {
+			return err
+		}
+
+		target.Set(reflect.MakeSlice(target.Type(), len(data), len(data)))
+		reflect.Copy(target, reflect.ValueOf(data))
+
+		return nil
+	}
+
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+
+	length, err := d.arrayLength(code)
+	if err != nil {
+		return err
+	}
+
+	target.Set(reflect.MakeSlice(target.Type(), 0, capacity(length)))
+
+	for idx := 0; idx < length; idx++ {
+		element := reflect.New(target.Type().Elem()).Elem()
+		if err = d.decodeNext(element); err != nil {
+			return err
+		}
+
+		target.Set(reflect.Append(target, element))
+	}
+
+	return nil
+}
+
+// decodeFixedArray reads array or binary value following code into array target.
+// Returns ErrOverflow if value has more elements than target array.
+func (d *Decoder) decodeFixedArray(code byte, target reflect.Value) error {
+	if target.Type().Elem().Kind() == reflect.Uint8 {
+		data, err := d.decodeRaw(code)
+		if err == nil && len(data) > target.Len() {
+			return fmt.Errorf("%w: %d bytes into %v", ErrOverflow, len(data), target.Type())
+		}
+
+		reflect.Copy(target, reflect.ValueOf(data))
+
+		return err
+	}
+
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+
+	length, err := d.arrayLength(code)
+	if err == nil && length > target.Len() {
+		return fmt.Errorf("%w: %d elements into %v", ErrOverflow, length, target.Type())
+	}
+
+	for idx := 0; idx < length && err == nil; idx++ {
+		err = d.decodeNext(target.Index(idx))
+	}
+
+	return err
+}
+
+// decodeTypedMap reads map value following code into map target.
+func (d *Decoder) decodeTypedMap(code byte, target reflect.Value) error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+
+	length, err := d.mapLength(code)
+	if err != nil {
+		return err
+	}
+
+	target.Set(reflect.MakeMapWithSize(target.Type(), capacity(length)))
+
+	for idx := 0; idx < length; idx++ {
+		key := reflect.New(target.Type().Key()).Elem()
+		if err = d.decodeNext(key); err != nil {
+			return err
+		}
+
+		value := reflect.New(target.Type().Elem()).Elem()
+		if err = d.decodeNext(value); err != nil {
+			return err
+		}
+
+		target.SetMapIndex(key, value)
+	}
+
+	return nil
+}
+
+// decodeStruct reads map value following code into struct target matching keys to field names.
+// Values of unknown keys are skipped.
+func (d *Decoder) decodeStruct(code byte, target reflect.Value) error {
+	if target.Type() == reflect.TypeOf(time.Time{}) {
+		ext, err := d.decodeExt(code)
+		if err != nil {
+			return err
+		}
+
+		value, err := extTime(ext)
+		target.Set(reflect.ValueOf(value))
+
+		return err
+	}
+
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+
+	length, err := d.mapLength(code)
+	if err != nil {
+		return err
+	}
+
+	fields := make(map[string]int)
+	for _, field := range structFields(target.Type()) {
+		fields[field.name] = field.index
+	}
+
+	for idx := 0; idx < length; idx++ {
+		name, err := d.DecodeString()
+		if err != nil {
+			return err
+		}
+
+		fieldIndex, ok := fields[name]
+		if !ok {
+			if _, err = d.DecodeInterface(); err != nil { // skip unknown field value
+				return err
+			}
+
+			continue
+		}
+
+		if err = d.decodeNext(target.Field(fieldIndex)); err != nil {
+			return fmt.Errorf("%w: field %v: %v", Error, name, err)
+		}
+	}
+
+	return nil
+}
+
+// decodeNext reads next value into target.
+func (d *Decoder) decodeNext(target reflect.Value) error {
+	code, err := d.readCode()
+	if err != nil {
+		return err
+	}
+
+	return d.decodeValue(code, target)
+}
@@ -0,0 +1,380 @@
+package msgpack
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/amarin/binutils"
+)
+
+// Encoder writes MessagePack encoded values into binutils.BinaryWriter.
+type Encoder struct {
+	writer *binutils.BinaryWriter
+	buffer []byte // header encoding buffer
+}
+
+// NewEncoder creates Encoder writing into writer.
+func NewEncoder(writer *binutils.BinaryWriter) *Encoder {
+	return &Encoder{writer: writer, buffer: make([]byte, 0, 1+binutils.Uint64size+1)}
+}
+
+// flush writes encoded header buffer.
+func (e *Encoder) flush() error {
+	return e.writer.WriteBytes(e.buffer)
+}
+
+// EncodeNil writes nil.
+func (e *Encoder) EncodeNil() error {
+	return e.writer.WriteUint8(codeNil)
+}
+
+// EncodeBool writes bool value.
+func (e *Encoder) EncodeBool(value bool) error {
+	if value {
+		return e.writer.WriteUint8(codeTrue)
+	}
+
+	return e.writer.WriteUint8(codeFalse)
+}
+
+// EncodeUint writes unsigned integer using the smallest representation.
+func (e *Encoder) EncodeUint(value uint64) error {
+	switch {
+	case value <= codePositiveFixIntMax:
+		e.buffer = append(e.buffer[:0], byte(value))
+	case value <= math.MaxUint8:
+		e.buffer = append(e.buffer[:0], codeUint8, byte(value))
+	case value <= math.MaxUint16:
+		e.buffer = binutils.AppendUint16(append(e.buffer[:0], codeUint16), uint16(value))
+	case value <= math.MaxUint32:
+		e.buffer = binutils.AppendUint32(append(e.buffer[:0], codeUint32), uint32(value))
+	default:
+		e.buffer = binutils.AppendUint64(append(e.buffer[:0], codeUint64), value)
+	}
+
+	return e.flush()
+}
+
+// EncodeInt writes signed integer using the smallest representation.
+// Non-negative values are written using positive fixint or uint family.
+func (e *Encoder) EncodeInt(value int64) error {
+	switch {
+	case value >= 0:
+		return e.EncodeUint(uint64(value))
+	case value >= -32:
+		e.buffer = append(e.buffer[:0], byte(value))
+	case value >= math.MinInt8:
+		e.buffer = append(e.buffer[:0], codeInt8, byte(value))
+	case value >= math.MinInt16:
+		e.buffer = binutils.AppendInt16(append(e.buffer[:0], codeInt16), int16(value))
+	case value >= math.MinInt32:
+		e.buffer = binutils.AppendInt32(append(e.buffer[:0], codeInt32), int32(value))
+	default:
+		e.buffer = binutils.AppendInt64(append(e.buffer[:0], codeInt64), value)
+	}
+
+	return e.flush()
+}
+
+// EncodeFloat32 writes float32 value.
+func (e *Encoder) EncodeFloat32(value float32) error {
+	e.buffer = binutils.AppendUint32(append(e.buffer[:0], codeFloat32), math.Float32bits(value))
+
+	return e.flush()
+}
+
+// EncodeFloat64 writes float64 value.
+func (e *Encoder) EncodeFloat64(value float64) error {
+	e.buffer = binutils.AppendUint64(append(e.buffer[:0], codeFloat64), math.Float64bits(value))
+
+	return e.flush()
+}
+
+// encodeLength writes length header selecting fix, 8, 16 or 32 bits form.
+// Zero fixMax means fix form is not available, zero code8 means 8 bits form is not available.
+func (e *Encoder) encodeLength(length int, fixCode byte, fixMax int, code8, code16, code32 byte) error {
+	switch {
+	case length < 0 || uint64(length) > math.MaxUint32:
+		return fmt.Errorf("%w: length %d", ErrOverflow, length)
+	case length <= fixMax:
+		e.buffer = append(e.buffer[:0], fixCode|byte(length))
+	case code8 != 0 && length <= math.MaxUint8:
+		e.buffer = append(e.buffer[:0], code8, byte(length))
+	case length <= math.MaxUint16:
+		e.buffer = binutils.AppendUint16(append(e.buffer[:0], code16), uint16(length))
+	default:
+		e.buffer = binutils.AppendUint32(append(e.buffer[:0], code32), uint32(length))
+	}
+
+	return e.flush()
+}
+
+// EncodeString writes string value.
+func (e *Encoder) EncodeString(value string) error {
+	if err := e.encodeLength(len(value), codeFixStr, 31, codeStr8, codeStr16, codeStr32); err != nil {
+		return err
+	}
+
+	return e.writer.WriteBytes([]byte(value))
+}
+
+// EncodeBytes writes binary value.
+func (e *Encoder) EncodeBytes(value []byte) error {
+	if err := e.encodeLength(len(value), 0, -1, codeBin8, codeBin16, codeBin32); err != nil {
+		return err
+	}
+
+	return e.writer.WriteBytes(value)
+}
+
+// EncodeArrayLen writes array header. Caller must encode exactly length elements next.
+func (e *Encoder) EncodeArrayLen(length int) error {
+	return e.encodeLength(length, codeFixArray, 15, 0, codeArray16, codeArray32)
+}
+
+// EncodeMapLen writes map header. Caller must encode exactly length key and value pairs next.
+func (e *Encoder) EncodeMapLen(length int) error {
+	return e.encodeLength(length, codeFixMap, 15, 0, codeMap16, codeMap32)
+}
+
+// EncodeExt writes extension value using fixext form if data length allows.
+func (e *Encoder) EncodeExt(extType int8, data []byte) (err error) {
+	switch len(data) {
+	case 1:
+		e.buffer = append(e.buffer[:0], codeFixExt1, byte(extType))
+	case 2:
+		e.buffer = append(e.buffer[:0], codeFixExt2, byte(extType))
+	case 4:
+		e.buffer = append(e.buffer[:0], codeFixExt4, byte(extType))
+	case 8:
+		e.buffer = append(e.buffer[:0], codeFixExt8, byte(extType))
+	case 16:
+		e.buffer = append(e.buffer[:0], codeFixExt16, byte(extType))
+	default:
+		if err = e.encodeLength(len(data), 0, -1, codeExt8, codeExt16, codeExt32); err != nil {
+			return err
+		}
+
+		e.buffer = append(e.buffer[:0], byte(extType))
+	}
+
+	if err = e.flush(); err != nil {
+		return err
+	}
+
+	return e.writer.WriteBytes(data)
+}
+
+// EncodeTime writes time using timestamp extension selecting the smallest of 32, 64 or 96 bits forms.
+func (e *Encoder) EncodeTime(value time.Time) error {
+	seconds, nanoseconds := value.Unix(), uint32(value.Nanosecond())
+
+	switch {
+	case seconds>>34 != 0: // timestamp 96
+		data := make([]byte, 12)
+		binary.BigEndian.PutUint32(data, nanoseconds)
+		binary.BigEndian.PutUint64(data[4:], uint64(seconds))
+
+		return e.EncodeExt(TimestampType, data)
+	case nanoseconds == 0 && seconds <= math.MaxUint32: // timestamp 32
+		return e.EncodeExt(TimestampType, binutils.Uint32bytes(uint32(seconds)))
+	default: // timestamp 64
+		return e.EncodeExt(TimestampType, binutils.Uint64bytes(uint64(nanoseconds)<<34|uint64(seconds)))
+	}
+}
+
+// Encode writes any supported value.
+// Supported values are nil, bool, integers, floats, strings, []byte, time.Time, Ext, *Ext,
+// and arrays, slices, maps, structs or pointers of supported values.
+// Structs are encoded as maps keyed by field names, use `msgpack:"name"` field tag
+// to override name, `msgpack:"-"` to skip field or `msgpack:",omitempty"` to skip field having zero value.
+func (e *Encoder) Encode(value interface{}) error {
+	switch typed := value.(type) {
+	case nil:
+		return e.EncodeNil()
+	case bool:
+		return e.EncodeBool(typed)
+	case int:
+		return e.EncodeInt(int64(typed))
+	case int64:
+		return e.EncodeInt(typed)
+	case uint64:
+		return e.EncodeUint(typed)
+	case float32:
+		return e.EncodeFloat32(typed)
+	case float64:
+		return e.EncodeFloat64(typed)
+	case string:
+		return e.EncodeString(typed)
+	case []byte:
+		return e.EncodeBytes(typed)
+	case time.Time:
+		return e.EncodeTime(typed)
+	case Ext:
+		return e.EncodeExt(typed.Type, typed.Data)
+	case *Ext:
+		if typed == nil {
+			return e.EncodeNil()
+		}
+
+		return e.EncodeExt(typed.Type, typed.Data)
+	default:
+		return e.encodeValue(reflect.ValueOf(value))
+	}
+}
+
+// encodeValue writes value using reflection.
+func (e *Encoder) encodeValue(value reflect.Value) error {
+	switch value.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if value.IsNil() {
+			return e.EncodeNil()
+		}
+
+		return e.Encode(value.Elem().Interface())
+	case reflect.Bool:
+		return e.EncodeBool(value.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return e.EncodeInt(value.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return e.EncodeUint(value.Uint())
+	case reflect.Float32:
+		return e.EncodeFloat32(float32(value.Float()))
+	case reflect.Float64:
+		return e.EncodeFloat64(value.Float())
+	case reflect.String:
+		return e.EncodeString(value.String())
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 {
+			data := make([]byte, value.Len())
+			reflect.Copy(reflect.ValueOf(data), value)
+
+			return e.EncodeBytes(data)
+		}
+
+		return e.encodeArray(value)
+	case reflect.Map:
+		return e.encodeMap(value)
+	case reflect.Struct:
+		return e.encodeStruct(value)
+	default:
+		return fmt.Errorf("%w: %v", ErrUnsupportedType, value.Type())
+	}
+}
+
+// encodeArray writes slice or array elements.
+func (e *Encoder) encodeArray(value reflect.Value) error {
+	if value.Kind() == reflect.Slice && value.IsNil() {
+		return e.EncodeNil()
+	}
+
+	if err := e.EncodeArrayLen(value.Len()); err != nil {
+		return err
+	}
+
+	for idx := 0; idx < value.Len(); idx++ {
+		if err := e.encodeValue(value.Index(idx)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeMap writes map keys and values.
+func (e *Encoder) encodeMap(value reflect.Value) error {
+	if value.IsNil() {
+		return e.EncodeNil()
+	}
+
+	if err := e.EncodeMapLen(value.Len()); err != nil {
+		return err
+	}
+
+	iterator := value.MapRange()
+	for iterator.Next() {
+		if err := e.encodeValue(iterator.Key()); err != nil {
+			return err
+		}
+
+		if err := e.encodeValue(iterator.Value()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// encodeStruct writes struct as map keyed by field names.
+func (e *Encoder) encodeStruct(value reflect.Value) error {
+	if value.Type() == reflect.TypeOf(time.Time{}) {
+		return e.EncodeTime(value.Interface().(time.Time))
+	}
+
+	fields := structFields(value.Type())
+	encoded := fields[:0]
+
+	for _, field := range fields {
+		if !field.omitEmpty || !value.Field(field.index).IsZero() {
+			encoded = append(encoded, field)
+		}
+	}
+
+	if err := e.EncodeMapLen(len(encoded)); err != nil {
+		return err
+	}
+
+	for _, field := range encoded {
+		if err := e.EncodeString(field.name); err != nil {
+			return err
+		}
+
+		if err := e.encodeValue(value.Field(field.index)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// structField describes encoded struct field.
+type structField struct {
+	name      string
+	index     int
+	omitEmpty bool
+}
+
+// structFields returns exported struct fields not skipped by `msgpack:"-"` tag.
+// Tag value is a field name optionally followed by comma separated options,
+// omitempty option skips field having zero value, other options are ignored.
+func structFields(structType reflect.Type) (fields []structField) {
+	for idx := 0; idx < structType.NumField(); idx++ {
+		field := structType.Field(idx)
+		if field.PkgPath != "" { // unexported
+			continue
+		}
+
+		options := strings.Split(field.Tag.Get("msgpack"), ",")
+		name := options[0]
+
+		switch name {
+		case "-":
+			continue
+		case "":
+			name = field.Name
+		}
+
+		result := structField{name: name, index: idx}
+		for _, option := range options[1:] {
+			result.omitEmpty = result.omitEmpty || option == "omitempty"
+		}
+
+		fields = append(fields, result)
+	}
+
+	return fields
+}
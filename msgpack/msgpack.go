@@ -0,0 +1,85 @@
+// Package msgpack implements MessagePack encoding on top of binutils.BinaryReader and binutils.BinaryWriter.
+//
+// Encoder always selects the smallest representation of integers, strings, binaries, arrays, maps and extensions.
+// Decoder decodes values either into interface{} or into typed Go values using reflection
+// and limits nesting depth, container sizes and data lengths.
+// Multi-byte values are always big-endian as required by specification regardless of
+// byte order configured for underlying reader or writer.
+package msgpack
+
+import (
+	"fmt"
+
+	"github.com/amarin/binutils"
+)
+
+// Format codes defined by MessagePack specification.
+const (
+	codePositiveFixIntMax = 0x7f
+	codeFixMap            = 0x80
+	codeFixArray          = 0x90
+	codeFixStr            = 0xa0
+	codeNil               = 0xc0
+	codeNeverUsed         = 0xc1
+	codeFalse             = 0xc2
+	codeTrue              = 0xc3
+	codeBin8              = 0xc4
+	codeBin16             = 0xc5
+	codeBin32             = 0xc6
+	codeExt8              = 0xc7
+	codeExt16             = 0xc8
+	codeExt32             = 0xc9
+	codeFloat32           = 0xca
+	codeFloat64           = 0xcb
+	codeUint8             = 0xcc
+	codeUint16            = 0xcd
+	codeUint32            = 0xce
+	codeUint64            = 0xcf
+	codeInt8              = 0xd0
+	codeInt16             = 0xd1
+	codeInt32             = 0xd2
+	codeInt64             = 0xd3
+	codeFixExt1           = 0xd4
+	codeFixExt2           = 0xd5
+	codeFixExt4           = 0xd6
+	codeFixExt8           = 0xd7
+	codeFixExt16          = 0xd8
+	codeStr8              = 0xd9
+	codeStr16             = 0xda
+	codeStr32             = 0xdb
+	codeArray16           = 0xdc
+	codeArray32           = 0xdd
+	codeMap16             = 0xde
+	codeMap32             = 0xdf
+	codeNegativeFixIntMin = 0xe0
+)
+
+// TimestampType is an extension type reserved for timestamps.
+const TimestampType int8 = -1
+
+// Some predefined errors used during processing.
+var (
+	// Error indicates any MessagePack errors.
+	Error = fmt.Errorf("%w: msgpack", binutils.Error)
+
+	// ErrUnsupportedType returned if value type could not be encoded or decoded.
+	ErrUnsupportedType = fmt.Errorf("%w: unsupported type", Error)
+
+	// ErrInvalidCode returned if format code is never used or does not match expected value family.
+	ErrInvalidCode = fmt.Errorf("%w: invalid code", Error)
+
+	// ErrOverflow returned if decoded value does not fit into target type.
+	ErrOverflow = fmt.Errorf("%w: value overflows target type", Error)
+
+	// ErrTimestamp returned if timestamp extension data is malformed.
+	ErrTimestamp = fmt.Errorf("%w: invalid timestamp", Error)
+
+	// ErrLimit returned if decoded data exceeds decoder limits.
+	ErrLimit = fmt.Errorf("%w: limit exceeded", Error)
+)
+
+// Ext represents application specific extension value.
+type Ext struct {
+	Type int8   // extension type, negative types are reserved by specification
+	Data []byte // extension data
+}
@@ -0,0 +1,261 @@
+package msgpack_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amarin/binutils"
+	"github.com/amarin/binutils/msgpack"
+)
+
+func encode(t *testing.T, value interface{}) []byte {
+	buffer := new(bytes.Buffer)
+	require.NoError(t, msgpack.NewEncoder(binutils.NewBinaryWriter(buffer)).Encode(value))
+
+	return buffer.Bytes()
+}
+
+func decoder(data []byte) *msgpack.Decoder {
+	return msgpack.NewDecoder(binutils.NewBinaryReader(bytes.NewReader(data)))
+}
+
+func TestEncoder_Encode(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		value interface{}
+		hex   string
+	}{
+		{"nil", nil, "c0"},
+		{"false", false, "c2"},
+		{"true", true, "c3"},
+		{"positive_fixint", 127, "7f"},
+		{"uint8", uint16(200), "ccc8"},
+		{"uint16", 0xffff, "cdffff"},
+		{"uint32", uint64(0x10000), "ce00010000"},
+		{"uint64", uint64(math.MaxUint64), "cfffffffffffffffff"},
+		{"negative_fixint", -32, "e0"},
+		{"int8", int8(-33), "d0df"},
+		{"int16", -129, "d1ff7f"},
+		{"int32", int32(math.MinInt32), "d280000000"},
+		{"int64", int64(math.MinInt64), "d38000000000000000"},
+		{"float32", float32(1.5), "ca3fc00000"},
+		{"float64", 1.5, "cb3ff8000000000000"},
+		{"fixstr", "abc", "a3616263"},
+		{"str8", string(bytes.Repeat([]byte{'a'}, 32)), "d920" + hex.EncodeToString(bytes.Repeat([]byte{'a'}, 32))},
+		{"bin8", []byte{1, 2}, "c4020102"},
+		{"fixarray", []int{1, 2}, "920102"},
+		{"array16", make([]bool, 16), "dc0010" + "c2c2c2c2c2c2c2c2c2c2c2c2c2c2c2c2"},
+		{"fixmap", map[string]int{"a": 1}, "81a16101"},
+		{"fixext4", msgpack.Ext{Type: 5, Data: []byte{1, 2, 3, 4}}, "d60501020304"},
+		{"ext8", &msgpack.Ext{Type: 5, Data: []byte{1, 2, 3}}, "c70305010203"},
+		{"timestamp32", time.Unix(1, 0), "d6ff00000001"},
+		{"timestamp64", time.Unix(1, 1), "d7ff0000000400000001"},
+		{"timestamp96", time.Unix(-1, 0), "c70cff00000000ffffffffffffffff"},
+		{"struct", struct {
+			A    int
+			B    string `msgpack:"b"`
+			Skip int    `msgpack:"-"`
+		}{1, "x", 2}, "82a14101a162a178"},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.hex, hex.EncodeToString(encode(t, tt.value)))
+		})
+	}
+}
+
+func TestDecoder_DecodeInterface(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		value    interface{}
+		expected interface{}
+	}{
+		{"nil", nil, nil},
+		{"bool", true, true},
+		{"fixint", 5, int64(5)},
+		{"negative", -300, int64(-300)},
+		{"uint", uint64(300), uint64(300)},
+		{"float32", float32(1.5), float32(1.5)},
+		{"float64", 1.5, 1.5},
+		{"string", "text", "text"},
+		{"bytes", []byte{1}, []byte{1}},
+		{"array", []interface{}{"a", 1}, []interface{}{"a", int64(1)}},
+		{"map", map[string]interface{}{"a": nil}, map[interface{}]interface{}{"a": nil}},
+		{"ext", msgpack.Ext{Type: 1, Data: []byte{1, 2, 3}}, &msgpack.Ext{Type: 1, Data: []byte{1, 2, 3}}},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			decoded, err := decoder(encode(t, tt.value)).DecodeInterface()
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, decoded)
+		})
+	}
+}
+
+func TestDecoder_DecodeTime(t *testing.T) {
+	for _, expected := range []time.Time{
+		time.Unix(1, 0),
+		time.Unix(1<<33, 999999999),
+		time.Unix(-1<<40, 5),
+	} {
+		decoded, err := decoder(encode(t, expected)).DecodeTime()
+		require.NoError(t, err)
+		require.True(t, expected.Equal(decoded), "expected %v got %v", expected, decoded)
+	}
+}
+
+type record struct {
+	ID      uint32            `msgpack:"id"`
+	Name    string            `msgpack:"name"`
+	Tags    []string          `msgpack:"tags"`
+	Score   float64           `msgpack:"score"`
+	Created time.Time         `msgpack:"created"`
+	Attrs   map[string]int    `msgpack:"attrs"`
+	Parent  *record           `msgpack:"parent"`
+	Hash    [4]byte           `msgpack:"hash"`
+	Any     interface{}       `msgpack:"any"`
+	Extra   map[string]string `msgpack:"-"`
+}
+
+func TestDecoder_Decode(t *testing.T) {
+	expected := record{
+		ID:      7,
+		Name:    "seven",
+		Tags:    []string{"a", "b"},
+		Score:   -1.25,
+		Created: time.Unix(1650000000, 123).UTC(),
+		Attrs:   map[string]int{"x": -1},
+		Parent:  &record{ID: 1, Created: time.Unix(0, 0).UTC(), Attrs: map[string]int{}},
+		Hash:    [4]byte{1, 2, 3, 4},
+		Any:     "any",
+	}
+
+	decoded := new(record)
+	require.NoError(t, decoder(encode(t, expected)).Decode(decoded))
+	decoded.Created = decoded.Created.UTC()
+	decoded.Parent.Created = decoded.Parent.Created.UTC()
+	require.Equal(t, expected, *decoded)
+}
+
+func TestEncoder_Encode_OmitEmpty(t *testing.T) {
+	type options struct {
+		Name  string   `msgpack:"n,omitempty"`
+		Count int      `msgpack:",omitempty"`
+		Tags  []string `msgpack:"t,omitempty,unknown"`
+		Flag  bool     `msgpack:"f"`
+	}
+
+	require.Equal(t, []byte{0x81, 0xa1, 'f', 0xc2}, encode(t, options{}))
+	require.Equal(t, []byte{0x82, 0xa5, 'C', 'o', 'u', 'n', 't', 0x01, 0xa1, 'f', 0xc3},
+		encode(t, options{Count: 1, Flag: true}))
+
+	expected := options{Name: "name", Count: -1, Tags: []string{"a"}}
+	decoded := new(options)
+	require.NoError(t, decoder(encode(t, expected)).Decode(decoded))
+	require.Equal(t, expected, *decoded)
+}
+
+func TestDecoder_Decode_Errors(t *testing.T) {
+	var small int8
+	require.True(t, errors.Is(decoder(encode(t, 300)).Decode(&small), msgpack.ErrOverflow))
+
+	var unsigned uint
+	require.True(t, errors.Is(decoder(encode(t, -1)).Decode(&unsigned), msgpack.ErrOverflow))
+
+	var text string
+	require.True(t, errors.Is(decoder(encode(t, true)).Decode(&text), msgpack.ErrInvalidCode))
+
+	require.True(t, errors.Is(decoder([]byte{0xc1}).Decode(&text), msgpack.ErrInvalidCode))
+	require.True(t, errors.Is(decoder(nil).Decode(nil), binutils.ErrNilPointer))
+}
+
+// recursive types decoded by typed container paths.
+type (
+	recursiveSlice []recursiveSlice
+	recursiveArray [1]*recursiveArray
+	recursiveMap   map[string]recursiveMap
+)
+
+func TestDecoder_SetLimits(t *testing.T) {
+	limits := msgpack.Limits{MaxDepth: 4, MaxContainerLen: 2, MaxStringLen: 10}
+
+	for _, tt := range []struct {
+		name string
+		data []byte
+	}{
+		{"depth", []byte{0x91, 0x91, 0x91, 0x91, 0x91, 0x00}},
+		{"array_len", []byte{0xdd, 0x7f, 0xff, 0xff, 0xff}},
+		{"map_len", []byte{0x83, 1, 2, 3, 4, 5, 6}},
+		{"bin_len", []byte{0xc6, 0x7f, 0xff, 0xff, 0xff}},
+		{"str_len", []byte{0xd9, 0x0b}},
+		{"ext_len", []byte{0xc9, 0, 0, 1, 0, 5}},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			target := decoder(tt.data)
+			target.SetLimits(limits)
+			require.Equal(t, limits, target.Limits())
+			_, err := target.DecodeInterface()
+			require.True(t, errors.Is(err, msgpack.ErrLimit), err)
+		})
+	}
+
+	nestedArrays := append(bytes.Repeat([]byte{0x91}, 100), 0x90)
+	nestedMaps := append(bytes.Repeat([]byte{0x81, 0xa1, 'k'}, 100), 0x80)
+
+	for _, tt := range []struct {
+		name   string
+		data   []byte
+		target interface{}
+	}{
+		{"typed_slice", nestedArrays, new(recursiveSlice)},
+		{"typed_array", nestedArrays, new(recursiveArray)},
+		{"typed_map", nestedMaps, new(recursiveMap)},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			target := decoder(tt.data)
+			target.SetLimits(limits)
+			err := target.Decode(tt.target)
+			require.True(t, errors.Is(err, msgpack.ErrLimit), err)
+		})
+	}
+
+	var shallow recursiveSlice
+	require.NoError(t, decoder([]byte{0x91, 0x91, 0x90}).Decode(&shallow))
+	require.Equal(t, recursiveSlice{{{}}}, shallow)
+}
+
+func TestDecoder_TypedMethods(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	writer := binutils.NewBinaryWriter(buffer)
+	encoder := msgpack.NewEncoder(writer)
+	require.NoError(t, encoder.EncodeArrayLen(3))
+	require.NoError(t, encoder.EncodeInt(-5))
+	require.NoError(t, encoder.EncodeString("x"))
+	require.NoError(t, encoder.EncodeMapLen(0))
+	require.NoError(t, encoder.EncodeNil())
+
+	reader := binutils.NewBinaryReader(buffer)
+	dec := msgpack.NewDecoder(reader)
+	length, err := dec.DecodeArrayLen()
+	require.NoError(t, err)
+	require.Equal(t, 3, length)
+	signed, err := dec.DecodeInt()
+	require.NoError(t, err)
+	require.Equal(t, int64(-5), signed)
+	text, err := dec.DecodeString()
+	require.NoError(t, err)
+	require.Equal(t, "x", text)
+	length, err = dec.DecodeMapLen()
+	require.NoError(t, err)
+	require.Equal(t, 0, length)
+	require.NoError(t, dec.DecodeNil())
+	require.Equal(t, writer.BytesWritten(), reader.BytesTaken())
+}
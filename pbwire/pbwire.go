@@ -0,0 +1,485 @@
+// Package pbwire implements low-level Protocol Buffers wire format encoding
+// on top of binutils.BinaryReader and binutils.BinaryWriter without code generation.
+//
+// Fixed width values are always little-endian as required by wire format
+// regardless of byte order configured for underlying reader or writer.
+package pbwire
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/amarin/binutils"
+)
+
+// Type defines field wire type.
+type Type int8
+
+// Wire types defined by Protocol Buffers encoding.
+const (
+	VarintType     Type = 0
+	Fixed64Type    Type = 1
+	BytesType      Type = 2
+	StartGroupType Type = 3
+	EndGroupType   Type = 4
+	Fixed32Type    Type = 5
+)
+
+// Number defines field number.
+type Number int32
+
+// Field numbers range.
+const (
+	MinValidNumber Number = 1
+	MaxValidNumber Number = 1<<29 - 1
+)
+
+// Some predefined errors used during processing.
+var (
+	// Error indicates any wire format errors.
+	Error = fmt.Errorf("%w: protobuf wire", binutils.Error)
+
+	// ErrFieldNumber returned if field number is out of valid range.
+	ErrFieldNumber = fmt.Errorf("%w: invalid field number", Error)
+
+	// ErrWireType returned if wire type is unknown or does not match requested value type.
+	ErrWireType = fmt.Errorf("%w: invalid wire type", Error)
+
+	// ErrEndGroup returned if end group tag does not match start group.
+	ErrEndGroup = fmt.Errorf("%w: mismatched end group", Error)
+
+	// ErrLimit returned if read data exceeds reader limits.
+	ErrLimit = fmt.Errorf("%w: limit exceeded", Error)
+)
+
+// Limits restricts read data to protect reader from malicious input.
+type Limits struct {
+	MaxDepth    int // maximum nesting level of groups
+	MaxFieldLen int // maximum length-delimited field length
+}
+
+// DefaultLimits used by readers unless changed by SetLimits.
+var DefaultLimits = Limits{ // nolint:gochecknoglobals
+	MaxDepth:    64,
+	MaxFieldLen: binutils.DefaultMaxFrameSize,
+}
+
+// Writer writes wire format fields into binutils.BinaryWriter.
+type Writer struct {
+	writer  *binutils.BinaryWriter
+	scratch [binutils.Uint64size]byte
+}
+
+// NewWriter creates Writer writing fields into writer.
+func NewWriter(writer *binutils.BinaryWriter) *Writer {
+	return &Writer{writer: writer}
+}
+
+// WriteTag writes field tag combining field number and wire type.
+func (w *Writer) WriteTag(field Number, wireType Type) error {
+	if field < MinValidNumber || field > MaxValidNumber {
+		return fmt.Errorf("%w: %d", ErrFieldNumber, field)
+	}
+
+	if wireType < VarintType || wireType > Fixed32Type {
+		return fmt.Errorf("%w: %d", ErrWireType, wireType)
+	}
+
+	return w.writer.WriteUvarint(uint64(field)<<3 | uint64(wireType))
+}
+
+// WriteVarint writes varint field. Use it for uint32, uint64, int32, int64, bool and enum values.
+func (w *Writer) WriteVarint(field Number, value uint64) error {
+	if err := w.WriteTag(field, VarintType); err != nil {
+		return err
+	}
+
+	return w.writer.WriteUvarint(value)
+}
+
+// WriteZigZag writes zig-zag encoded varint field. Use it for sint32 and sint64 values.
+func (w *Writer) WriteZigZag(field Number, value int64) error {
+	if err := w.WriteTag(field, VarintType); err != nil {
+		return err
+	}
+
+	return w.writer.WriteVarint(value)
+}
+
+// WriteFixed32 writes little-endian 4 bytes field. Use it for fixed32, sfixed32 and float values.
+func (w *Writer) WriteFixed32(field Number, value uint32) error {
+	if err := w.WriteTag(field, Fixed32Type); err != nil {
+		return err
+	}
+
+	binary.LittleEndian.PutUint32(w.scratch[:], value)
+
+	return w.writer.WriteBytes(w.scratch[:binutils.Uint32size])
+}
+
+// WriteFixed64 writes little-endian 8 bytes field. Use it for fixed64, sfixed64 and double values.
+func (w *Writer) WriteFixed64(field Number, value uint64) error {
+	if err := w.WriteTag(field, Fixed64Type); err != nil {
+		return err
+	}
+
+	binary.LittleEndian.PutUint64(w.scratch[:], value)
+
+	return w.writer.WriteBytes(w.scratch[:binutils.Uint64size])
+}
+
+// WriteFloat writes float value as fixed32 field.
+func (w *Writer) WriteFloat(field Number, value float32) error {
+	return w.WriteFixed32(field, math.Float32bits(value))
+}
+
+// WriteDouble writes double value as fixed64 field.
+func (w *Writer) WriteDouble(field Number, value float64) error {
+	return w.WriteFixed64(field, math.Float64bits(value))
+}
+
+// WriteBytes writes length-delimited field. Use it for bytes, string and packed repeated values.
+func (w *Writer) WriteBytes(field Number, value []byte) error {
+	if err := w.WriteTag(field, BytesType); err != nil {
+		return err
+	}
+
+	if err := w.writer.WriteUvarint(uint64(len(value))); err != nil {
+		return err
+	}
+
+	return w.writer.WriteBytes(value)
+}
+
+// WriteString writes string as length-delimited field.
+func (w *Writer) WriteString(field Number, value string) error {
+	return w.WriteBytes(field, []byte(value))
+}
+
+// WriteMessage writes embedded message fields written by fill as length-delimited field.
+func (w *Writer) WriteMessage(field Number, fill func(message *Writer) error) error {
+	buffer := new(bytes.Buffer)
+	if err := fill(NewWriter(binutils.NewBinaryWriter(buffer))); err != nil {
+		return err
+	}
+
+	return w.WriteBytes(field, buffer.Bytes())
+}
+
+// Field represents single field taken by Reader.
+type Field struct {
+	Number Number                 // field number
+	Type   Type                   // field wire type
+	Value  *binutils.BinaryReader // field value reader bounded to field value bytes
+	limits Limits                 // limits of reader field taken by
+}
+
+// check returns ErrWireType if field wire type differs from expected.
+func (f *Field) check(expected Type) error {
+	if f.Type != expected {
+		return fmt.Errorf("%w: field %d has type %d, expected %d", ErrWireType, f.Number, f.Type, expected)
+	}
+
+	return nil
+}
+
+// Uvarint returns varint field value.
+func (f *Field) Uvarint() (uint64, error) {
+	if err := f.check(VarintType); err != nil {
+		return 0, err
+	}
+
+	return f.Value.ReadUvarint()
+}
+
+// Int64 returns varint field value as int64 or int32 value.
+func (f *Field) Int64() (int64, error) {
+	value, err := f.Uvarint()
+
+	return int64(value), err
+}
+
+// Bool returns varint field value as bool.
+func (f *Field) Bool() (bool, error) {
+	value, err := f.Uvarint()
+
+	return value != 0, err
+}
+
+// ZigZag returns zig-zag encoded varint field value.
+func (f *Field) ZigZag() (int64, error) {
+	if err := f.check(VarintType); err != nil {
+		return 0, err
+	}
+
+	return f.Value.ReadVarint()
+}
+
+// Fixed32 returns fixed32 field value.
+func (f *Field) Fixed32() (uint32, error) {
+	if err := f.check(Fixed32Type); err != nil {
+		return 0, err
+	}
+
+	data, err := f.Value.ReadBytesCount(binutils.Uint32size)
+	if err != nil {
+		return 0, err
+	}
+
+	return binary.LittleEndian.Uint32(data), nil
+}
+
+// Fixed64 returns fixed64 field value.
+func (f *Field) Fixed64() (uint64, error) {
+	if err := f.check(Fixed64Type); err != nil {
+		return 0, err
+	}
+
+	data, err := f.Value.ReadBytesCount(binutils.Uint64size)
+	if err != nil {
+		return 0, err
+	}
+
+	return binary.LittleEndian.Uint64(data), nil
+}
+
+// Float returns fixed32 field value as float.
+func (f *Field) Float() (float32, error) {
+	value, err := f.Fixed32()
+
+	return math.Float32frombits(value), err
+}
+
+// Double returns fixed64 field value as double.
+func (f *Field) Double() (float64, error) {
+	value, err := f.Fixed64()
+
+	return math.Float64frombits(value), err
+}
+
+// Bytes returns length-delimited field value.
+func (f *Field) Bytes() ([]byte, error) {
+	if err := f.check(BytesType); err != nil {
+		return nil, err
+	}
+
+	return f.Value.ReadBytesCount(f.Value.Remaining())
+}
+
+// String returns length-delimited field value as string.
+func (f *Field) String() (string, error) {
+	value, err := f.Bytes()
+
+	return string(value), err
+}
+
+// Message returns Reader over embedded message or group fields.
+func (f *Field) Message() (*Reader, error) {
+	if f.Type != BytesType && f.Type != StartGroupType {
+		return nil, fmt.Errorf("%w: field %d has type %d, expected message", ErrWireType, f.Number, f.Type)
+	}
+
+	return &Reader{reader: f.Value, limits: f.limits}, nil
+}
+
+// Reader iterates over wire format fields taken from binutils.BinaryReader.
+type Reader struct {
+	reader  *binutils.BinaryReader
+	limits  Limits
+	current *Field
+}
+
+// NewReader creates Reader taking fields from reader using DefaultLimits.
+func NewReader(reader *binutils.BinaryReader) *Reader {
+	return &Reader{reader: reader, limits: DefaultLimits}
+}
+
+// SetLimits sets reader limits. Readers returned by Field.Message inherit limits.
+func (r *Reader) SetLimits(limits Limits) {
+	r.limits = limits
+}
+
+// Limits returns current reader limits.
+func (r *Reader) Limits() Limits {
+	return r.limits
+}
+
+// readTag reads field tag. Returns io.EOF if no more fields available.
+func (r *Reader) readTag() (field Number, wireType Type, err error) {
+	tag, err := r.reader.ReadUvarint()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	field, wireType = Number(tag>>3), Type(tag&7)
+
+	switch {
+	case tag>>3 < uint64(MinValidNumber) || tag>>3 > uint64(MaxValidNumber):
+		return 0, 0, fmt.Errorf("%w: %d", ErrFieldNumber, tag>>3)
+	case wireType > Fixed32Type:
+		return 0, 0, fmt.Errorf("%w: %d", ErrWireType, wireType)
+	}
+
+	return field, wireType, nil
+}
+
+// Next skips unread value bytes of previous field if any and reads next field.
+// Unknown fields are skipped correctly simply by calling Next again.
+// Returns io.EOF if no more fields available.
+func (r *Reader) Next() (*Field, error) {
+	field, err := r.next()
+	if err == nil && field.Type == EndGroupType {
+		return nil, fmt.Errorf("%w: field %d", ErrEndGroup, field.Number)
+	}
+
+	return field, err
+}
+
+// next reads next field including end group markers having no value.
+func (r *Reader) next() (*Field, error) {
+	if r.current != nil {
+		if _, err := r.current.Value.Drain(); err != nil {
+			return nil, err
+		}
+
+		r.current = nil
+	}
+
+	number, wireType, err := r.readTag()
+	if err != nil {
+		return nil, err // keep io.EOF as is to detect clean end of message
+	}
+
+	field := &Field{Number: number, Type: wireType, limits: r.limits}
+
+	switch wireType {
+	case VarintType:
+		field.Value, err = r.captureVarint()
+	case Fixed32Type:
+		field.Value = r.reader.Sub(binutils.Uint32size)
+	case Fixed64Type:
+		field.Value = r.reader.Sub(binutils.Uint64size)
+	case BytesType:
+		var length int
+		if length, err = r.readLength(number); err == nil {
+			field.Value = r.reader.Sub(length)
+		}
+	case StartGroupType:
+		field.Value, err = r.captureGroup(number)
+	default: // end group marker has no value
+		return field, nil
+	}
+
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	r.current = field
+
+	return field, nil
+}
+
+// captureVarint takes varint value bytes and returns reader over them.
+func (r *Reader) captureVarint() (*binutils.BinaryReader, error) {
+	raw := make([]byte, 0, binary.MaxVarintLen64)
+
+	for {
+		next, err := r.reader.ReadUint8()
+		if err != nil {
+			return nil, err
+		}
+
+		raw = append(raw, next)
+
+		switch {
+		case next < 0x80:
+			return binutils.NewBinaryReader(bytes.NewReader(raw)), nil
+		case len(raw) == binary.MaxVarintLen64:
+			return nil, binutils.ErrVarintOverflow
+		}
+	}
+}
+
+// readLength reads length-delimited field length checking MaxFieldLen limit.
+func (r *Reader) readLength(number Number) (int, error) {
+	length, err := r.reader.ReadUvarint()
+	if err != nil {
+		return 0, err
+	}
+
+	if length > uint64(r.limits.MaxFieldLen) || length > math.MaxInt32 {
+		return 0, fmt.Errorf("%w: field %d length %d", ErrLimit, number, length)
+	}
+
+	return int(length), nil
+}
+
+// recorder copies bytes taken from reader into buffer.
+type recorder struct {
+	reader io.Reader
+	buffer bytes.Buffer
+}
+
+// Read reads from underlying reader recording taken bytes. Implements io.Reader.
+func (r *recorder) Read(p []byte) (n int, err error) {
+	n, err = r.reader.Read(p)
+	r.buffer.Write(p[:n])
+
+	return n, err
+}
+
+// captureGroup takes group fields until matching end group tag and returns reader over group content.
+// Nested groups are skipped in the same pass, so group bytes are recorded once regardless of nesting.
+func (r *Reader) captureGroup(number Number) (*binutils.BinaryReader, error) {
+	source := &recorder{reader: r.reader}
+	nested := &Reader{reader: binutils.NewBinaryReader(source), limits: r.limits}
+	groups := []Number{number}
+
+	for {
+		contentSize := source.buffer.Len()
+
+		field, wireType, err := nested.readTag()
+		if err != nil {
+			return nil, err
+		}
+
+		switch wireType {
+		case VarintType:
+			_, err = nested.reader.ReadUvarint()
+		case Fixed32Type:
+			_, err = nested.reader.Sub(binutils.Uint32size).Drain()
+		case Fixed64Type:
+			_, err = nested.reader.Sub(binutils.Uint64size).Drain()
+		case BytesType:
+			var length int
+			if length, err = nested.readLength(field); err == nil {
+				_, err = nested.reader.Sub(length).Drain()
+			}
+		case StartGroupType:
+			if len(groups) >= r.limits.MaxDepth {
+				return nil, fmt.Errorf("%w: depth %d", ErrLimit, r.limits.MaxDepth)
+			}
+
+			groups = append(groups, field)
+		default: // end group
+			if expected := groups[len(groups)-1]; field != expected {
+				return nil, fmt.Errorf("%w: field %d, expected %d", ErrEndGroup, field, expected)
+			}
+
+			if groups = groups[:len(groups)-1]; len(groups) == 0 {
+				return binutils.NewBinaryReader(bytes.NewReader(source.buffer.Bytes()[:contentSize])), nil
+			}
+		}
+
+		if err != nil {
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package pbwire_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amarin/binutils"
+	"github.com/amarin/binutils/pbwire"
+)
+
+func TestWriter(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	writer := pbwire.NewWriter(binutils.NewBinaryWriter(buffer))
+	require.NoError(t, writer.WriteVarint(1, 150))
+	require.NoError(t, writer.WriteString(2, "testing"))
+	require.NoError(t, writer.WriteZigZag(3, -2))
+	require.NoError(t, writer.WriteFixed32(4, 1))
+	require.NoError(t, writer.WriteFixed64(5, 1))
+	require.NoError(t, writer.WriteMessage(6, func(message *pbwire.Writer) error {
+		return message.WriteVarint(1, 150)
+	}))
+	require.Equal(t,
+		"089601"+"120774657374696e67"+"1803"+"2501000000"+"290100000000000000"+"3203089601",
+		hex.EncodeToString(buffer.Bytes()))
+
+	require.True(t, errors.Is(writer.WriteTag(0, pbwire.VarintType), pbwire.ErrFieldNumber))
+	require.True(t, errors.Is(writer.WriteTag(1, 6), pbwire.ErrWireType))
+}
+
+func TestReader(t *testing.T) {
+	data, err := hex.DecodeString(
+		"089601" + "120774657374696e67" + "1803" + "2501000000" + "290100000000000000" + "3203089601")
+	require.NoError(t, err)
+
+	reader := pbwire.NewReader(binutils.NewBinaryReader(bytes.NewReader(data)))
+
+	field, err := reader.Next()
+	require.NoError(t, err)
+	require.Equal(t, pbwire.Number(1), field.Number)
+	require.Equal(t, pbwire.VarintType, field.Type)
+	value, err := field.Uvarint()
+	require.NoError(t, err)
+	require.Equal(t, uint64(150), value)
+
+	field, err = reader.Next()
+	require.NoError(t, err)
+	_, err = field.Uvarint()
+	require.True(t, errors.Is(err, pbwire.ErrWireType))
+	text, err := field.String()
+	require.NoError(t, err)
+	require.Equal(t, "testing", text)
+
+	field, err = reader.Next()
+	require.NoError(t, err)
+	signed, err := field.ZigZag()
+	require.NoError(t, err)
+	require.Equal(t, int64(-2), signed)
+
+	field, err = reader.Next()
+	require.NoError(t, err)
+	fixed32, err := field.Fixed32()
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), fixed32)
+
+	_, err = reader.Next() // fixed64 is skipped as unknown field
+	require.NoError(t, err)
+
+	field, err = reader.Next()
+	require.NoError(t, err)
+	message, err := field.Message()
+	require.NoError(t, err)
+	nested, err := message.Next()
+	require.NoError(t, err)
+	value, err = nested.Uvarint()
+	require.NoError(t, err)
+	require.Equal(t, uint64(150), value)
+
+	_, err = reader.Next()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestReader_SkipUnknown(t *testing.T) {
+	// varint, fixed64, bytes, group with nested group, fixed32 and final known field 15.
+	data, err := hex.DecodeString("08ac02" + "110102030405060708" + "1a03616263" +
+		"23" + "0801" + "2b" + "0802" + "2c" + "24" + "2d01020304" + "7801")
+	require.NoError(t, err)
+
+	reader := pbwire.NewReader(binutils.NewBinaryReader(bytes.NewReader(data)))
+
+	var numbers []pbwire.Number
+	for {
+		field, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+
+		require.NoError(t, err)
+		numbers = append(numbers, field.Number)
+
+		if field.Number == 4 {
+			group, err := field.Message()
+			require.NoError(t, err)
+			first, err := group.Next()
+			require.NoError(t, err)
+			require.Equal(t, pbwire.Number(1), first.Number)
+		}
+	}
+
+	require.Equal(t, []pbwire.Number{1, 2, 3, 4, 5, 15}, numbers)
+}
+
+func TestReader_Errors(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		hex  string
+		want error
+	}{
+		{"field_zero", "0001", pbwire.ErrFieldNumber},
+		{"wire_type", "0e01", pbwire.ErrWireType},
+		{"end_group", "0c", pbwire.ErrEndGroup},
+		{"mismatched_group", "0b14", pbwire.ErrEndGroup},
+		{"truncated_fixed", "0d0102", io.ErrUnexpectedEOF},
+		{"truncated_varint", "0880", io.ErrUnexpectedEOF},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := hex.DecodeString(tt.hex)
+			require.NoError(t, err)
+			reader := pbwire.NewReader(binutils.NewBinaryReader(bytes.NewReader(data)))
+
+			field, err := reader.Next()
+			if err == nil && field.Type == pbwire.Fixed32Type {
+				_, err = field.Fixed32()
+			}
+
+			require.Truef(t, errors.Is(err, tt.want), "expected %v, got %v", tt.want, err)
+		})
+	}
+}
+
+func TestReader_Limits(t *testing.T) {
+	nested := bytes.Repeat([]byte{0x0b}, 20000)
+	nested = append(nested, bytes.Repeat([]byte{0x0c}, 20000)...)
+
+	reader := pbwire.NewReader(binutils.NewBinaryReader(bytes.NewReader(nested)))
+	_, err := reader.Next()
+	require.True(t, errors.Is(err, pbwire.ErrLimit), "deep nesting must fail, got %v", err)
+
+	reader = pbwire.NewReader(binutils.NewBinaryReader(bytes.NewReader([]byte{0x0a, 0xff, 0xff, 0xff, 0xff, 0x07})))
+	_, err = reader.Next()
+	require.True(t, errors.Is(err, pbwire.ErrLimit), "oversized field must fail, got %v", err)
+
+	// group containing length-delimited field is limited too.
+	reader = pbwire.NewReader(binutils.NewBinaryReader(bytes.NewReader([]byte{0x0b, 0x12, 0x04, 1, 2, 3, 4, 0x0c})))
+	reader.SetLimits(pbwire.Limits{MaxDepth: 1, MaxFieldLen: 3})
+	require.Equal(t, pbwire.Limits{MaxDepth: 1, MaxFieldLen: 3}, reader.Limits())
+	_, err = reader.Next()
+	require.True(t, errors.Is(err, pbwire.ErrLimit), "oversized group field must fail, got %v", err)
+
+	reader = pbwire.NewReader(binutils.NewBinaryReader(bytes.NewReader([]byte{0x0b, 0x12, 0x03, 1, 2, 3, 0x0c})))
+	reader.SetLimits(pbwire.Limits{MaxDepth: 1, MaxFieldLen: 3})
+	field, err := reader.Next()
+	require.NoError(t, err)
+	group, err := field.Message()
+	require.NoError(t, err)
+	require.Equal(t, reader.Limits(), group.Limits())
+	field, err = group.Next()
+	require.NoError(t, err)
+	data, err := field.Bytes()
+	require.NoError(t, err)
+	require.Equal(t, []byte{1, 2, 3}, data)
+}
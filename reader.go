@@ -1,19 +1,35 @@
 package binutils
 
 import (
+	"encoding/binary"
 	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"sync"
+	"unicode/utf8"
 )
 
 // BinaryReader implements binary writing for various data types into file writer.
 type BinaryReader struct {
-	mu         *sync.Mutex // read mutex protects underlying fields
-	source     io.Reader
-	bytesTaken int
+	mu           *sync.Mutex // read mutex protects underlying fields
+	source       io.Reader
+	bytesTaken   int
+	encoding     TextEncoding     // strings encoding, nil means strings bytes are taken as is
+	strictUTF8   bool             // reject invalid UTF-8 in string reads
+	strictFinish bool             // reject unconsumed bytes in Finish
+	zeroCopy     bool             // return slices of mapped memory instead of copies
+	tracer       *Tracer          // typed operations tracer or nil, see SetTracer
+	traceOffset  int              // trace offset of reader start, set for readers created by Sub
+	version      uint64           // envelope version being decoded, see ReadVersioned
+	versioned    bool             // reader decodes versioned envelope payload
+	scratch      [Uint64size]byte // typed reads buffer, protected by mu
+	order        binary.ByteOrder // multi-byte values bytes order
+
+	unread       []byte            // bytes returned back by UnreadRune, taken before source
+	lastRune     [utf8.UTFMax]byte // last rune bytes taken by ReadUTF8Rune
+	lastRuneSize int               // last rune size or -1 if UnreadRune is not allowed
 }
 
 // OpenFile opens specified file path and returns BinaryReader wrapping it.
@@ -30,7 +46,13 @@ func OpenFile(filePath string) (*BinaryReader, error) {
 
 // NewBinaryReader wraps existing io.Reader into BinaryReader.
 func NewBinaryReader(source io.Reader) *BinaryReader {
-	return &BinaryReader{source: source, mu: new(sync.Mutex), bytesTaken: 0}
+	return &BinaryReader{
+		source:       source,
+		mu:           new(sync.Mutex),
+		bytesTaken:   0,
+		lastRuneSize: -1,
+		order:        binary.BigEndian,
+	}
 }
 
 // ResetBytesTaken zeroes internal bytes taken counter.
@@ -56,9 +78,70 @@ func (r *BinaryReader) increaseBytesTaken(addBytesTaken int) {
 	r.mu.Unlock()
 }
 
+// SetByteOrder sets bytes order used to read multi-byte values. Big-endian bytes order used by default.
+func (r *BinaryReader) SetByteOrder(order binary.ByteOrder) {
+	r.mu.Lock()
+	r.order = order
+	r.mu.Unlock()
+}
+
+// ByteOrder returns bytes order used to read multi-byte values.
+func (r *BinaryReader) ByteOrder() (order binary.ByteOrder) {
+	r.mu.Lock()
+	order = r.order
+	r.mu.Unlock()
+
+	return order
+}
+
+// SetEncoding sets text encoding used to decode strings taken by ReadStringZ and ReadObject.
+// Nil encoding makes strings bytes taken as is without any decoding.
+func (r *BinaryReader) SetEncoding(encoding TextEncoding) {
+	r.mu.Lock()
+	r.encoding = encoding
+	r.mu.Unlock()
+}
+
+// Encoding returns text encoding used to decode strings or nil if strings bytes are taken as is.
+func (r *BinaryReader) Encoding() (encoding TextEncoding) {
+	r.mu.Lock()
+	encoding = r.encoding
+	r.mu.Unlock()
+
+	return encoding
+}
+
+// SetStrictUTF8 enables or disables strict UTF-8 mode.
+// In strict mode string reads without text encoding set and ReadUTF8Rune
+// reject invalid UTF-8 sequences returning ErrInvalidUTF8 annotated with offset of first invalid byte.
+// Offset is counted the same way as BytesTaken.
+func (r *BinaryReader) SetStrictUTF8(strict bool) {
+	r.mu.Lock()
+	r.strictUTF8 = strict
+	r.mu.Unlock()
+}
+
+// StrictUTF8 returns true if strict UTF-8 mode enabled.
+func (r *BinaryReader) StrictUTF8() (strict bool) {
+	r.mu.Lock()
+	strict = r.strictUTF8
+	r.mu.Unlock()
+
+	return strict
+}
+
 // Close closes underlying reader. Implements io.Closer.
 // Returns error if underlying reader not  implements io.Closer.
+// Memory mapped reader is unmapped only after concurrent reads complete, further reads return ErrUnmapped.
+// Other readers are closed without waiting, so Close could interrupt blocked Read of e.g. network connection.
 func (r *BinaryReader) Close() error {
+	if mapped, ok := r.source.(*mappedSource); ok {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+
+		return mapped.Close()
+	}
+
 	closer, ok := r.source.(io.Closer)
 	if !ok {
 		return fmt.Errorf("%w: %T is not io.Closer", ErrClose, r.source)
@@ -73,23 +156,95 @@ func (r *BinaryReader) Close() error {
 // Implements io.Reader itself.
 func (r *BinaryReader) Read(p []byte) (n int, err error) {
 	r.mu.Lock()
-	n, err = r.source.Read(p)
+	n, err = r.readSource(p)
 	r.bytesTaken += n
+	r.lastRuneSize = -1
 	r.mu.Unlock()
 
 	return n, err
 }
 
-// read wraps Read returning only error.
+// readSource reads bytes returned back by UnreadRune first if any, otherwise reads underlying reader.
+// Requires r.mu held, does not count taken bytes.
+func (r *BinaryReader) readSource(p []byte) (n int, err error) {
+	if len(r.unread) > 0 {
+		n = copy(p, r.unread)
+		r.unread = r.unread[n:]
+
+		return n, nil
+	}
+
+	return r.source.Read(p)
+}
+
+// fill reads until p is full or any error encountered. Counts taken bytes.
+// Returns bytes count placed into p. Requires r.mu held.
+func (r *BinaryReader) fill(p []byte) (n int, err error) {
+	for n < len(p) && err == nil {
+		var taken int
+		taken, err = r.readSource(p[n:])
+		n += taken
+	}
+
+	r.bytesTaken += n
+
+	if r.tracer != nil {
+		r.tracer.capture(p[:n])
+	}
+
+	if n == len(p) {
+		return n, nil
+	}
+
+	return n, err
+}
+
+// read reads exactly len(p) bytes counting taken bytes internally.
+// Returns io.ErrUnexpectedEOF if only part of required bytes taken.
 func (r *BinaryReader) read(p []byte) (err error) {
-	_, err = r.Read(p)
+	r.mu.Lock()
+	err = r.readLocked(p)
+	r.mu.Unlock()
 
 	return err
 }
 
+// readLocked reads exactly len(p) bytes counting taken bytes internally. Requires r.mu held.
+func (r *BinaryReader) readLocked(p []byte) error {
+	r.lastRuneSize = -1
+
+	n, err := r.fill(p)
+
+	switch {
+	case err == nil:
+		return nil
+	case n > 0 && err == io.EOF:
+		return io.ErrUnexpectedEOF
+	default:
+		return err
+	}
+}
+
 // ReadBytesCount reads exactly specified amount of bytes.
 // Returns read bytes or error if insufficient bytes count ready to read or any underlying reader error encountered.
-func (r *BinaryReader) ReadBytesCount(amount int) (buffer []byte, err error) {
+// If zero-copy mode enabled for memory mapped reader, returned bytes reference mapped memory, see SetZeroCopy.
+func (r *BinaryReader) ReadBytesCount(amount int) ([]byte, error) {
+	tracer := r.traceBegin()
+	buffer, err := r.readBytesCount(amount)
+
+	if tracer != nil {
+		tracer.end("ReadBytesCount", nil, err)
+	}
+
+	return buffer, err
+}
+
+// readBytesCount implements ReadBytesCount.
+func (r *BinaryReader) readBytesCount(amount int) (buffer []byte, err error) {
+	if buffer, taken, err := r.takeMapped(amount); taken {
+		return buffer, err
+	}
+
 	buffer = make([]byte, amount)
 	if err = r.read(buffer); err != nil { // read required bytes amount counting taken bytes internally
 		return buffer, err
@@ -98,134 +253,404 @@ func (r *BinaryReader) ReadBytesCount(amount int) (buffer []byte, err error) {
 	return buffer, nil
 }
 
+// ReadBytesInto reads exactly len(dst) bytes into caller supplied dst buffer.
+// Returns error if insufficient bytes count ready to read or any underlying reader error encountered.
+func (r *BinaryReader) ReadBytesInto(dst []byte) error {
+	tracer := r.traceBegin()
+	err := r.read(dst)
+
+	if tracer != nil {
+		tracer.end("ReadBytesInto", nil, err)
+	}
+
+	return err
+}
+
 // ReadUint8 reads uint8 value from underlying reader.
 // Returns uint8 value and any error encountered.
 func (r *BinaryReader) ReadUint8() (res uint8, err error) {
-	byteBuffer := AllocateBytes(Uint8size)
+	r.mu.Lock()
+	tracer := r.traceBeginLocked()
+	buffer := r.scratch[:Uint8size]
+	if err = r.readLocked(buffer); err == nil {
+		res = buffer[0]
+	}
+	r.mu.Unlock()
 
-	if err = r.read(byteBuffer); err != nil { // read required bytes amount counting taken bytes internally
-		return 0, err
+	if tracer != nil {
+		tracer.end("ReadUint8", res, err)
 	}
 
-	return Uint8(byteBuffer)
+	return res, err
 }
 
 // ReadUint16 reads uint16 value from underlying reader.
 // Returns uint16 value and any error encountered.
 func (r *BinaryReader) ReadUint16() (res uint16, err error) {
-	byteBuffer := AllocateBytes(Uint16size)
-	if err = r.read(byteBuffer); err != nil { // read required bytes amount counting taken bytes internally
-		return 0, err
+	r.mu.Lock()
+	tracer := r.traceBeginLocked()
+	buffer := r.scratch[:Uint16size]
+	if err = r.readLocked(buffer); err == nil {
+		res = r.order.Uint16(buffer)
 	}
+	r.mu.Unlock()
 
-	return Uint16(byteBuffer)
+	if tracer != nil {
+		tracer.end("ReadUint16", res, err)
+	}
+
+	return res, err
 }
 
 // ReadUint32 reads uint32 value from underlying reader.
 // Returns uint32 value and any error encountered.
 func (r *BinaryReader) ReadUint32() (res uint32, err error) {
-	byteBuffer := AllocateBytes(Uint32size)
+	r.mu.Lock()
+	tracer := r.traceBeginLocked()
+	buffer := r.scratch[:Uint32size]
+	if err = r.readLocked(buffer); err == nil {
+		res = r.order.Uint32(buffer)
+	}
+	r.mu.Unlock()
 
-	if err = r.read(byteBuffer); err != nil { // read required bytes amount counting taken bytes internally
-		return 0, err
+	if tracer != nil {
+		tracer.end("ReadUint32", res, err)
 	}
 
-	return Uint32(byteBuffer)
+	return res, err
 }
 
 // ReadUint64 reads uint64 value from underlying reader.
 // Returns uint64 value and any error encountered.
 func (r *BinaryReader) ReadUint64() (res uint64, err error) {
-	byteBuffer := AllocateBytes(Uint64size)
+	r.mu.Lock()
+	tracer := r.traceBeginLocked()
+	buffer := r.scratch[:Uint64size]
+	if err = r.readLocked(buffer); err == nil {
+		res = r.order.Uint64(buffer)
+	}
+	r.mu.Unlock()
 
-	if err = r.read(byteBuffer); err != nil { // read required bytes amount counting taken bytes internally
-		return 0, err
+	if tracer != nil {
+		tracer.end("ReadUint64", res, err)
 	}
-	return Uint64(byteBuffer)
 
+	return res, err
 }
 
 // ReadUint reads uint value from underlying reader.
 // Returns uint value and any error encountered.
 func (r *BinaryReader) ReadUint() (uint, error) {
+	tracer := r.traceBegin()
 	uint64result, err := r.ReadUint64()
+
+	if tracer != nil {
+		tracer.end("ReadUint", uint(uint64result), err)
+	}
+
 	return uint(uint64result), err
 }
 
 // ReadInt8 reads int8 value from underlying reader.
 // Returns int8 value and any error encountered.
-func (r *BinaryReader) ReadInt8() (res int8, err error) {
-	byteBuffer := AllocateBytes(Int8size)
-	if err = r.read(byteBuffer); err != nil { // read required bytes amount counting taken bytes internally
-		return 0, err
+func (r *BinaryReader) ReadInt8() (int8, error) {
+	tracer := r.traceBegin()
+	uint8result, err := r.ReadUint8()
+
+	if tracer != nil {
+		tracer.end("ReadInt8", int8(uint8result), err)
 	}
 
-	return Int8(byteBuffer)
+	return int8(uint8result), err
 }
 
 // ReadInt16 reads int16 value from underlying reader.
 // Returns int16 value and any error encountered.
-func (r *BinaryReader) ReadInt16() (res int16, err error) {
-	byteBuffer := AllocateBytes(Int16size)
-	if err = r.read(byteBuffer); err != nil { // read required bytes amount counting taken bytes internally
-		return 0, err
+func (r *BinaryReader) ReadInt16() (int16, error) {
+	tracer := r.traceBegin()
+	uint16result, err := r.ReadUint16()
+
+	if tracer != nil {
+		tracer.end("ReadInt16", int16(uint16result), err)
 	}
 
-	return Int16(byteBuffer)
+	return int16(uint16result), err
 }
 
 // ReadInt32 reads int32 value from underlying reader.
 // Returns int32 value and any error encountered.
-func (r *BinaryReader) ReadInt32() (res int32, err error) {
-	byteBuffer := AllocateBytes(Int32size)
-	if err = r.read(byteBuffer); err != nil { // read required bytes amount counting taken bytes internally
-		return 0, err
+func (r *BinaryReader) ReadInt32() (int32, error) {
+	tracer := r.traceBegin()
+	uint32result, err := r.ReadUint32()
+
+	if tracer != nil {
+		tracer.end("ReadInt32", int32(uint32result), err)
 	}
 
-	return Int32(byteBuffer)
+	return int32(uint32result), err
 }
 
 // ReadInt64 reads int64 value from underlying reader.
 // Returns int64 value and any error encountered.
-func (r *BinaryReader) ReadInt64() (res int64, err error) {
-	byteBuffer := AllocateBytes(Int64size)
-	if err = r.read(byteBuffer); err != nil { // read required bytes amount counting taken bytes internally
-		return 0, err
+func (r *BinaryReader) ReadInt64() (int64, error) {
+	tracer := r.traceBegin()
+	uint64result, err := r.ReadUint64()
+
+	if tracer != nil {
+		tracer.end("ReadInt64", int64(uint64result), err)
 	}
 
-	return Int64(byteBuffer)
+	return int64(uint64result), err
 }
 
 // ReadInt reads int value from underlying reader.
 // Returns int value and any error encountered.
 func (r *BinaryReader) ReadInt() (int, error) {
+	tracer := r.traceBegin()
 	int64result, err := r.ReadInt64()
+
+	if tracer != nil {
+		tracer.end("ReadInt", int(int64result), err)
+	}
+
 	return int(int64result), err
 }
 
-// ReadRune reads rune value from underlying io.Reader.
+// ReadUvarint reads unsigned base 128 varint encoded value as produced by binary.PutUvarint.
+// Returns value and any error encountered, ErrVarintOverflow if value overflows 64-bit integer.
+func (r *BinaryReader) ReadUvarint() (uint64, error) {
+	tracer := r.traceBegin()
+	res, err := r.readUvarint()
+
+	if tracer != nil {
+		tracer.end("ReadUvarint", res, err)
+	}
+
+	return res, err
+}
+
+// readUvarint implements ReadUvarint.
+func (r *BinaryReader) readUvarint() (res uint64, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var shift uint
+
+	for idx := 0; idx < binary.MaxVarintLen64; idx++ {
+		if err = r.readLocked(r.scratch[:1]); err != nil {
+			if idx > 0 && err == io.EOF {
+				err = io.ErrUnexpectedEOF
+			}
+
+			return 0, err
+		}
+
+		next := r.scratch[0]
+		if next < 0x80 {
+			if idx == binary.MaxVarintLen64-1 && next > 1 {
+				return 0, ErrVarintOverflow
+			}
+
+			return res | uint64(next)<<shift, nil
+		}
+
+		res |= uint64(next&0x7f) << shift
+		shift += 7
+	}
+
+	return 0, ErrVarintOverflow
+}
+
+// ReadVarint reads signed zig-zag base 128 varint encoded value as produced by binary.PutVarint.
+// Returns value and any error encountered, ErrVarintOverflow if value overflows 64-bit integer.
+func (r *BinaryReader) ReadVarint() (int64, error) {
+	tracer := r.traceBegin()
+	unsigned, err := r.ReadUvarint()
+	signed := int64(unsigned >> 1)
+
+	if unsigned&1 != 0 {
+		signed = ^signed
+	}
+
+	if tracer != nil {
+		tracer.end("ReadVarint", signed, err)
+	}
+
+	return signed, err
+}
+
+// ReadUTF32Rune reads rune value from underlying io.Reader as 4 bytes UTF-32 code unit.
 // Returns rune value and any error encountered.
-func (r *BinaryReader) ReadRune() (res rune, err error) {
-	byteBuffer := AllocateBytes(RuneSize)
-	if err = r.read(byteBuffer); err != nil { // read required bytes amount counting taken bytes internally
+func (r *BinaryReader) ReadUTF32Rune() (rune, error) {
+	tracer := r.traceBegin()
+	uint32result, err := r.ReadUint32()
+
+	if tracer != nil {
+		tracer.end("ReadUTF32Rune", rune(uint32result), err)
+	}
+
+	return rune(uint32result), err
+}
+
+// ReadUTF8Rune reads single UTF-8 encoded character from underlying reader.
+// Returns character, its size in bytes and any error encountered.
+// Invalid UTF-8 sequence is taken as utf8.RuneError of size 1
+// or returns ErrInvalidUTF8 error if strict UTF-8 mode enabled.
+func (r *BinaryReader) ReadUTF8Rune() (rune, int, error) {
+	tracer := r.traceBegin()
+	char, size, err := r.readUTF8Rune()
+
+	if tracer != nil {
+		tracer.end("ReadUTF8Rune", char, err)
+	}
+
+	return char, size, err
+}
+
+// readUTF8Rune implements ReadUTF8Rune.
+func (r *BinaryReader) readUTF8Rune() (char rune, size int, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.lastRuneSize = -1
+	offset := r.bytesTaken
+	buffer := r.lastRune[:]
+
+	if _, err = r.fill(buffer[:1]); err != nil {
+		return 0, 0, err
+	}
+
+	if buffer[0] < utf8.RuneSelf {
+		r.lastRuneSize = 1
+
+		return rune(buffer[0]), 1, nil
+	}
+
+	taken, _ := r.fill(buffer[1:utf8SequenceSize(buffer[0])])
+	char, size = utf8.DecodeRune(buffer[:1+taken])
+
+	if extra := buffer[size : 1+taken]; len(extra) > 0 { // return back bytes not belonging to taken rune
+		r.unread = append(append(make([]byte, 0, len(extra)+len(r.unread)), extra...), r.unread...)
+		r.bytesTaken -= len(extra)
+
+		if r.tracer != nil {
+			r.tracer.uncapture(len(extra))
+		}
+	}
+
+	if char == utf8.RuneError && size == 1 && r.strictUTF8 {
+		return char, size, fmt.Errorf("%w: at offset %d", ErrInvalidUTF8, offset)
+	}
+
+	r.lastRuneSize = size
+
+	return char, size, nil
+}
+
+// utf8SequenceSize returns expected UTF-8 sequence size by its first byte or 1 if byte could not start a sequence.
+func utf8SequenceSize(first byte) int {
+	switch {
+	case first >= 0xc2 && first <= 0xdf:
+		return 2
+	case first >= 0xe0 && first <= 0xef:
+		return 3
+	case first >= 0xf0 && first <= 0xf4:
+		return 4
+	default:
+		return 1
+	}
+}
+
+// ReadRune reads rune value as 4 bytes UTF-32 code unit written by BinaryWriter.WriteRune.
+// Returns rune value, its size which is always RuneSize and any error encountered.
+// Together with UnreadRune implements io.RuneScanner over UTF-32 code units,
+// use AsRuneScanner to read UTF-8 encoded characters as io.RuneScanner.
+//
+// Deprecated: use ReadUTF32Rune or ReadUTF8Rune to state rune encoding explicitly.
+func (r *BinaryReader) ReadRune() (rune, int, error) {
+	tracer := r.traceBegin()
+	char, err := r.readRune()
+
+	if tracer != nil {
+		tracer.end("ReadRune", char, err)
+	}
+
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return char, RuneSize, nil
+}
+
+// readRune implements ReadRune keeping taken bytes for UnreadRune.
+func (r *BinaryReader) readRune() (rune, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	buffer := r.lastRune[:RuneSize]
+	if err := r.readLocked(buffer); err != nil {
 		return 0, err
 	}
 
-	return Rune(byteBuffer)
+	r.lastRuneSize = RuneSize
+
+	return rune(r.order.Uint32(buffer)), nil
+}
+
+// UnreadRune returns last rune taken by ReadUTF8Rune or ReadRune back to reader decreasing bytes taken counter.
+// Only allowed immediately after successful ReadUTF8Rune or ReadRune call, returns ErrUnreadRune otherwise.
+// Implements io.RuneScanner.
+func (r *BinaryReader) UnreadRune() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.lastRuneSize < 0 {
+		return ErrUnreadRune
+	}
+
+	r.unread = append(append(make([]byte, 0, r.lastRuneSize+len(r.unread)), r.lastRune[:r.lastRuneSize]...), r.unread...)
+	r.bytesTaken -= r.lastRuneSize
+	r.lastRuneSize = -1
+
+	if r.tracer != nil {
+		r.tracer.drop()
+	}
+
+	return nil
 }
 
 // ReadBytes reads bytes sequence until the first occurrence of stop byte in the input.
 // Returns a bytes slice containing the data up to and including the delimiter.
 // If ReadBytes encounters an error before finding a delimiter,
 // it returns the data read before the error and the error itself (often io.EOF).
-func (r *BinaryReader) ReadBytes(stop byte) (dataTaken []byte, err error) {
+func (r *BinaryReader) ReadBytes(stop byte) ([]byte, error) {
+	tracer := r.traceBegin()
+	dataTaken, err := r.readBytes(stop)
+
+	if tracer != nil {
+		tracer.end("ReadBytes", nil, err)
+	}
+
+	return dataTaken, err
+}
+
+// readBytes implements ReadBytes.
+func (r *BinaryReader) readBytes(stop byte) (dataTaken []byte, err error) {
+	r.mu.Lock()
 	alreadyImplemented, ok := r.source.(untilStopByteReader)
-	if ok {
+	if ok && len(r.unread) == 0 {
 		dataTaken, err = alreadyImplemented.ReadBytes(stop)
-		r.increaseBytesTaken(len(dataTaken)) // increase counter to taken bytes len
+		r.bytesTaken += len(dataTaken) // increase counter to taken bytes len
+
+		if r.tracer != nil {
+			r.tracer.capture(dataTaken)
+		}
+		r.lastRuneSize = -1
+		r.mu.Unlock()
 
 		return dataTaken, err
 	}
+	r.mu.Unlock()
 	// underlying reader does not implement read bytes until stop,
 	// so read byte-by-byte and compare next ones until stop byte found or any read error happened.
 	var (
@@ -247,14 +672,145 @@ func (r *BinaryReader) ReadBytes(stop byte) (dataTaken []byte, err error) {
 }
 
 // ReadStringZ reads zero-terminated string from underlying reader.
-func (r *BinaryReader) ReadStringZ() (line string, err error) {
+// If text encoding set using SetEncoding string is terminated by zero code unit and decoded using that encoding.
+func (r *BinaryReader) ReadStringZ() (string, error) {
+	tracer := r.traceBegin()
+	line, err := r.readStringZ()
+
+	if tracer != nil {
+		tracer.end("ReadStringZ", line, err)
+	}
+
+	return line, err
+}
+
+// readStringZ implements ReadStringZ.
+func (r *BinaryReader) readStringZ() (line string, err error) {
 	var dataTaken []byte
 
-	if dataTaken, err = r.ReadBytes(0); err != nil {
+	offset := r.BytesTaken()
+	encoding := r.Encoding()
+
+	if encoding == nil {
+		if dataTaken, err = r.ReadBytes(0); err != nil {
+			return "", fmt.Errorf("%w: read: %v", ErrRequired0T, err)
+		}
+
+		dataTaken = dataTaken[:len(dataTaken)-1]
+		if err = r.validateUTF8(dataTaken, offset); err != nil {
+			return "", err
+		}
+
+		return string(dataTaken), nil
+	}
+
+	if dataTaken, err = r.readUnitsZ(encoding.UnitSize()); err != nil {
 		return "", fmt.Errorf("%w: read: %v", ErrRequired0T, err)
 	}
 
-	return string(dataTaken[:len(dataTaken)-1]), nil
+	if line, err = encoding.Decode(dataTaken); err != nil {
+		return "", fmt.Errorf("%v: %v: %w", ErrRead, ErrDecodeTo, err)
+	}
+
+	return line, nil
+}
+
+// ReadStringZInto reads zero-terminated string appending its bytes excluding terminator to dst.
+// Returns extended buffer. Taking strings without text encoding set does not allocate
+// if dst has enough capacity, otherwise decoded string bytes are appended.
+func (r *BinaryReader) ReadStringZInto(dst []byte) ([]byte, error) {
+	tracer := r.traceBegin()
+	start := len(dst)
+	dst, err := r.readStringZInto(dst)
+
+	if tracer != nil {
+		tracer.end("ReadStringZInto", string(dst[start:]), err)
+	}
+
+	return dst, err
+}
+
+// readStringZInto implements ReadStringZInto.
+func (r *BinaryReader) readStringZInto(dst []byte) ([]byte, error) {
+	offset := r.BytesTaken()
+	start := len(dst)
+
+	if encoding := r.Encoding(); encoding != nil {
+		line, err := r.ReadStringZ()
+		return append(dst, line...), err
+	}
+
+	for {
+		char, err := r.ReadUint8()
+		if err != nil {
+			return dst, fmt.Errorf("%w: read: %v", ErrRequired0T, err)
+		}
+
+		if char == 0 {
+			return dst, r.validateUTF8(dst[start:], offset)
+		}
+
+		dst = append(dst, char)
+	}
+}
+
+// validateUTF8 checks data taken at specified offset is valid UTF-8 if strict UTF-8 mode enabled.
+// Returns ErrInvalidUTF8 annotated with first invalid byte offset.
+func (r *BinaryReader) validateUTF8(data []byte, offset int) error {
+	if !r.StrictUTF8() {
+		return nil
+	}
+
+	return checkUTF8(data, offset)
+}
+
+// checkUTF8 returns ErrInvalidUTF8 annotated with offset of first invalid byte if data is not valid UTF-8.
+func checkUTF8(data []byte, offset int) error {
+	if utf8.Valid(data) {
+		return nil
+	}
+
+	for idx := 0; idx < len(data); {
+		char, size := utf8.DecodeRune(data[idx:])
+		if char == utf8.RuneError && size == 1 {
+			return fmt.Errorf("%w: at offset %d", ErrInvalidUTF8, offset+idx)
+		}
+
+		idx += size
+	}
+
+	return nil
+}
+
+// readUnitsZ reads code units of specified size until zero code unit taken.
+// Returns taken bytes excluding terminating zero code unit.
+func (r *BinaryReader) readUnitsZ(unitSize int) (dataTaken []byte, err error) {
+	if unitSize == 1 {
+		if dataTaken, err = r.ReadBytes(0); err != nil {
+			return dataTaken, err
+		}
+
+		return dataTaken[:len(dataTaken)-1], nil
+	}
+
+	unit := AllocateBytes(unitSize)
+
+	for {
+		if err = r.read(unit); err != nil {
+			return dataTaken, err
+		}
+
+		isZero := true
+		for _, unitByte := range unit {
+			isZero = isZero && unitByte == 0
+		}
+
+		if isZero {
+			return dataTaken, nil
+		}
+
+		dataTaken = append(dataTaken, unit...)
+	}
 }
 
 // ReadHex reads exactly specified amount of bytes and return hex representation string for received bytes.
@@ -262,11 +818,16 @@ func (r *BinaryReader) ReadStringZ() (line string, err error) {
 func (r *BinaryReader) ReadHex(amount int) (hexString string, err error) {
 	var dataTaken []byte
 
-	if dataTaken, err = r.ReadBytesCount(amount); err != nil {
-		return "", err
+	tracer := r.traceBegin()
+	if dataTaken, err = r.ReadBytesCount(amount); err == nil {
+		hexString = hex.EncodeToString(dataTaken)
+	}
+
+	if tracer != nil {
+		tracer.end("ReadHex", nil, err)
 	}
 
-	return hex.EncodeToString(dataTaken), nil
+	return hexString, err
 }
 
 // ReadObject reads object data from underlying io.Reader.
@@ -328,6 +889,8 @@ func (r *BinaryReader) ReadObject(target interface{}) error {
 		receivedValue, err := r.ReadStringZ()
 		*tgtType = receivedValue
 		return err
+	case BinaryDecoder:
+		return tgtType.DecodeBinary(r)
 	case BinaryReaderFrom:
 		if err := tgtType.BinaryReadFrom(r); err != nil {
 			return err
@@ -345,6 +908,8 @@ func (r *BinaryReader) ReadObject(target interface{}) error {
 		return nil
 
 	default:
-		return fmt.Errorf("%w: %T should implement io.ReaderFrom or binutils.BinaryReaderFrom", ErrRead, tgtType)
+		return fmt.Errorf(
+			"%w: %T should implement io.ReaderFrom, binutils.BinaryDecoder or binutils.BinaryReaderFrom", ErrRead, tgtType,
+		)
 	}
 }
@@ -3,9 +3,13 @@ package binutils_test
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"strings"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/require"
 
@@ -260,15 +264,49 @@ func TestBinaryReader_ReadRune(t *testing.T) {
 		require.Equal(t, RuneSize, len(bufferBytes))
 		buffer := bytes.NewBuffer(bufferBytes)
 		reader := NewBinaryReader(buffer)
-		target, err := reader.ReadRune()
+		target, size, err := reader.ReadRune()
 		require.NoError(t, err)
+		require.Equal(t, RuneSize, size)
 		require.Equal(t, RuneSize, reader.BytesTaken())
 		require.Equal(t, expected, target)
 	}
 
 	reader := NewBinaryReader(bytes.NewBuffer(nil))
 	reader.ResetBytesTaken()
-	_, err := reader.ReadRune()
+	_, _, err := reader.ReadRune()
+	require.Error(t, err) // error as buffer empty
+	require.Equal(t, 0, reader.BytesTaken())
+
+	buffer := new(bytes.Buffer)
+	require.NoError(t, NewBinaryWriter(buffer).WriteRune('星'))
+	reader = NewBinaryReader(buffer)
+	target, _, err := reader.ReadRune()
+	require.NoError(t, err)
+	require.Equal(t, '星', target, "ReadRune must read runes written by WriteRune")
+	require.NoError(t, reader.UnreadRune())
+	require.Equal(t, 0, reader.BytesTaken())
+	target, err = reader.ReadUTF32Rune()
+	require.NoError(t, err)
+	require.Equal(t, '星', target)
+}
+
+func TestBinaryReader_ReadUTF32Rune(t *testing.T) {
+	for _, expected := range []rune{'Я', '±', 'ა', 'タ', 'W'} {
+		expectedHex := fmt.Sprintf("%#08x", uint32(expected))[2:]
+		bufferBytes, err := hex.DecodeString(expectedHex)
+		require.NoErrorf(t, err, "%v: %v", err, expectedHex)
+		require.Equal(t, RuneSize, len(bufferBytes))
+		buffer := bytes.NewBuffer(bufferBytes)
+		reader := NewBinaryReader(buffer)
+		target, err := reader.ReadUTF32Rune()
+		require.NoError(t, err)
+		require.Equal(t, RuneSize, reader.BytesTaken())
+		require.Equal(t, expected, target)
+	}
+
+	reader := NewBinaryReader(bytes.NewBuffer(nil))
+	reader.ResetBytesTaken()
+	_, err := reader.ReadUTF32Rune()
 	require.Error(t, err) // error as buffer empty
 	require.Equal(t, 0, reader.BytesTaken())
 }
@@ -301,7 +339,7 @@ func TestBinaryReader_ReadBytes(t *testing.T) {
 
 	reader := NewBinaryReader(bytes.NewBuffer(nil))
 	reader.ResetBytesTaken()
-	_, err := reader.ReadRune()
+	_, err := reader.ReadUTF32Rune()
 	require.Error(t, err) // error as buffer empty
 	require.Equal(t, 0, reader.BytesTaken())
 }
@@ -437,3 +475,229 @@ func TestBinaryReader_ReadObject(t *testing.T) {
 		})
 	}
 }
+
+func TestBinaryReader_ReadUTF8Rune(t *testing.T) {
+	reader := NewBinaryReader(bytes.NewBufferString("aЯ星𝄞"))
+	for _, expected := range []rune{'a', 'Я', '星', '𝄞'} {
+		taken := reader.BytesTaken()
+		char, size, err := reader.ReadUTF8Rune()
+		require.NoError(t, err)
+		require.Equal(t, expected, char)
+		require.Equal(t, utf8.RuneLen(expected), size)
+		require.Equal(t, taken+size, reader.BytesTaken())
+	}
+
+	_, _, err := reader.ReadUTF8Rune()
+	require.Equal(t, io.EOF, err)
+}
+
+func TestBinaryReader_ReadUTF8Rune_Invalid(t *testing.T) {
+	reader := NewBinaryReader(bytes.NewBuffer([]byte{0xe6, 0x41, 0xff, 0x42}))
+	for _, expected := range []rune{utf8.RuneError, 'A', utf8.RuneError, 'B'} {
+		char, size, err := reader.ReadUTF8Rune()
+		require.NoError(t, err)
+		require.Equal(t, expected, char)
+		require.Equal(t, 1, size)
+	}
+
+	reader = NewBinaryReader(bytes.NewBuffer([]byte{0x41, 0xe6, 0x41}))
+	reader.SetStrictUTF8(true)
+	require.True(t, reader.StrictUTF8())
+	_, _, err := reader.ReadUTF8Rune()
+	require.NoError(t, err)
+	_, _, err = reader.ReadUTF8Rune()
+	require.True(t, errors.Is(err, ErrInvalidUTF8))
+	require.Contains(t, err.Error(), "offset 1")
+}
+
+func TestBinaryReader_UnreadRune(t *testing.T) {
+	reader := NewBinaryReader(bytes.NewBufferString("星a"))
+	scanner := AsRuneScanner(reader)
+	require.True(t, errors.Is(scanner.UnreadRune(), ErrUnreadRune))
+
+	char, size, err := scanner.ReadRune()
+	require.NoError(t, err)
+	require.Equal(t, '星', char)
+	require.NoError(t, scanner.UnreadRune())
+	require.Equal(t, 0, reader.BytesTaken())
+	require.True(t, errors.Is(reader.UnreadRune(), ErrUnreadRune))
+
+	line, err := reader.ReadBytes('a')
+	require.NoError(t, err)
+	require.Equal(t, "星a", string(line))
+	require.Equal(t, size+1, reader.BytesTaken())
+	require.True(t, errors.Is(reader.UnreadRune(), ErrUnreadRune))
+}
+
+func TestBinaryReader_ReadStringZ_StrictUTF8(t *testing.T) {
+	data := []byte{0x41, 0x42, 0x00, 0x43, 0xff, 0x00}
+
+	reader := NewBinaryReader(bytes.NewBuffer(data))
+	_, err := reader.ReadStringZ()
+	require.NoError(t, err)
+	_, err = reader.ReadStringZ()
+	require.NoError(t, err)
+
+	reader = NewBinaryReader(bytes.NewBuffer(data))
+	reader.SetStrictUTF8(true)
+	line, err := reader.ReadStringZ()
+	require.NoError(t, err)
+	require.Equal(t, "AB", line)
+	_, err = reader.ReadStringZ()
+	require.True(t, errors.Is(err, ErrInvalidUTF8))
+	require.Contains(t, err.Error(), "offset 4")
+}
+
+// endlessSource implements io.Reader producing endless sequence of 0x01 bytes.
+type endlessSource struct{}
+
+func (endlessSource) Read(p []byte) (int, error) {
+	for idx := range p {
+		p[idx] = 0x01
+	}
+
+	return len(p), nil
+}
+
+func TestBinaryReader_ReadBytesInto(t *testing.T) {
+	reader := NewBinaryReader(bytes.NewBuffer(bytesValue))
+	target := make([]byte, 3)
+	require.NoError(t, reader.ReadBytesInto(target))
+	require.Equal(t, bytesValue[:3], target)
+	require.Equal(t, 3, reader.BytesTaken())
+	require.Equal(t, io.ErrUnexpectedEOF, reader.ReadBytesInto(target))
+	require.Equal(t, len(bytesValue), reader.BytesTaken())
+}
+
+func TestBinaryReader_ReadStringZInto(t *testing.T) {
+	reader := NewBinaryReader(bytes.NewBufferString("first\x00second\x00"))
+	buffer := make([]byte, 0, 16)
+
+	line, err := reader.ReadStringZInto(buffer)
+	require.NoError(t, err)
+	require.Equal(t, "first", string(line))
+
+	line, err = reader.ReadStringZInto(line[:0])
+	require.NoError(t, err)
+	require.Equal(t, "second", string(line))
+	require.Equal(t, 13, reader.BytesTaken())
+
+	_, err = reader.ReadStringZInto(line[:0])
+	require.True(t, errors.Is(err, ErrRequired0T))
+}
+
+func TestBinaryReader_ZeroAllocations(t *testing.T) {
+	reader := NewBinaryReader(endlessSource{})
+	buffer := make([]byte, 32)
+
+	for name, read := range map[string]func(){
+		"ReadUint8":     func() { _, _ = reader.ReadUint8() },
+		"ReadUint16":    func() { _, _ = reader.ReadUint16() },
+		"ReadUint32":    func() { _, _ = reader.ReadUint32() },
+		"ReadUint64":    func() { _, _ = reader.ReadUint64() },
+		"ReadInt64":     func() { _, _ = reader.ReadInt64() },
+		"ReadUTF32Rune": func() { _, _ = reader.ReadUTF32Rune() },
+		"ReadBytesInto": func() { _ = reader.ReadBytesInto(buffer) },
+	} {
+		read := read // pin read
+		t.Run(name, func(t *testing.T) {
+			require.Zero(t, testing.AllocsPerRun(100, read))
+		})
+	}
+}
+
+func BenchmarkBinaryReader_ReadUint16(b *testing.B) {
+	reader := NewBinaryReader(endlessSource{})
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := reader.ReadUint16(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBinaryReader_ReadUint32(b *testing.B) {
+	reader := NewBinaryReader(endlessSource{})
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := reader.ReadUint32(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBinaryReader_ReadInt64(b *testing.B) {
+	reader := NewBinaryReader(endlessSource{})
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := reader.ReadInt64(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBinaryReader_ReadBytesInto(b *testing.B) {
+	reader := NewBinaryReader(endlessSource{})
+	buffer := make([]byte, 64)
+	b.ReportAllocs()
+	b.SetBytes(int64(len(buffer)))
+
+	for i := 0; i < b.N; i++ {
+		if err := reader.ReadBytesInto(buffer); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBinaryReader_ReadStringZInto(b *testing.B) {
+	data := bytes.Repeat([]byte("binutils\x00"), 1024)
+	source := bytes.NewReader(data)
+	reader := NewBinaryReader(source)
+	buffer := make([]byte, 0, 16)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if source.Len() == 0 {
+			source.Reset(data)
+		}
+
+		var err error
+		if buffer, err = reader.ReadStringZInto(buffer[:0]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestBinaryReader_ReadUvarint(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	writer := NewBinaryWriter(buffer)
+	for _, value := range []uint64{0, 1, 127, 128, 300, math.MaxUint64} {
+		require.NoError(t, writer.WriteUvarint(value))
+	}
+	for _, value := range []int64{0, -1, 1, math.MinInt64, math.MaxInt64} {
+		require.NoError(t, writer.WriteVarint(value))
+	}
+
+	reader := NewBinaryReader(buffer)
+	for _, expected := range []uint64{0, 1, 127, 128, 300, math.MaxUint64} {
+		value, err := reader.ReadUvarint()
+		require.NoError(t, err)
+		require.Equal(t, expected, value)
+	}
+	for _, expected := range []int64{0, -1, 1, math.MinInt64, math.MaxInt64} {
+		value, err := reader.ReadVarint()
+		require.NoError(t, err)
+		require.Equal(t, expected, value)
+	}
+	require.Equal(t, writer.BytesWritten(), reader.BytesTaken())
+
+	_, err := NewBinaryReader(bytes.NewBuffer([]byte{0x80})).ReadUvarint()
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+
+	overflow := bytes.Repeat([]byte{0xff}, 10)
+	_, err = NewBinaryReader(bytes.NewBuffer(append(overflow, 1))).ReadUvarint()
+	require.True(t, errors.Is(err, ErrVarintOverflow))
+}
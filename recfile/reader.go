@@ -0,0 +1,180 @@
+package recfile
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/amarin/binutils"
+)
+
+// Reader provides random access to records of record file.
+type Reader struct {
+	source  io.ReaderAt
+	offsets []int64 // record offsets followed by index offset
+}
+
+// Open opens record file for random access.
+func Open(filePath string) (*Reader, error) {
+	absFileName, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(absFileName)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	reader, err := NewReader(file, info.Size())
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+
+	return reader, nil
+}
+
+// NewReader creates Reader over source of specified size. Footer and index are loaded and verified immediately.
+// Source is closed by Close if it implements io.Closer.
+func NewReader(source io.ReaderAt, size int64) (*Reader, error) {
+	if size < FooterSize {
+		return nil, fmt.Errorf("%w: %d bytes is too short", ErrFormat, size)
+	}
+
+	footerData := make([]byte, FooterSize)
+	if _, err := source.ReadAt(footerData, size-FooterSize); err != nil {
+		return nil, err
+	}
+
+	parsed, err := parseFooter(footerData, size)
+	if err != nil {
+		return nil, err
+	}
+
+	index := make([]byte, int64(parsed.count)*binutils.Uint64size)
+	if _, err = source.ReadAt(index, int64(parsed.indexOffset)); err != nil {
+		return nil, err
+	}
+
+	checksum := crc32.Update(crc32.ChecksumIEEE(index), crc32.IEEETable, footerData[:FooterSize-binutils.Uint32size])
+	if checksum != parsed.checksum {
+		return nil, ErrChecksum
+	}
+
+	// index offset is limited by file size, so offsets not exceeding it are valid non-negative int64 values
+	offsets := make([]int64, parsed.count+1)
+	for idx := range offsets[:parsed.count] {
+		offset := binary.BigEndian.Uint64(index[idx*binutils.Uint64size:])
+		if offset > parsed.indexOffset {
+			return nil, fmt.Errorf("%w: record %d offset %d exceeds index offset %d", ErrFormat, idx, offset, parsed.indexOffset)
+		}
+
+		offsets[idx] = int64(offset)
+	}
+
+	offsets[parsed.count] = int64(parsed.indexOffset)
+
+	for idx := 1; idx < len(offsets); idx++ {
+		if offsets[idx] < offsets[idx-1] {
+			return nil, fmt.Errorf("%w: record %d offset %d precedes previous", ErrFormat, idx, offsets[idx])
+		}
+	}
+
+	return &Reader{source: source, offsets: offsets}, nil
+}
+
+// Len returns number of records.
+func (r *Reader) Len() int {
+	return len(r.offsets) - 1
+}
+
+// Size returns record size in bytes.
+func (r *Reader) Size(index int) (int, error) {
+	if index < 0 || index >= r.Len() {
+		return 0, fmt.Errorf("%w: %d of %d", ErrIndex, index, r.Len())
+	}
+
+	return int(r.offsets[index+1] - r.offsets[index]), nil
+}
+
+// Get returns reader of record having specified index.
+// Record is read directly from source on demand, returned reader reports io.EOF at record end.
+func (r *Reader) Get(index int) (*binutils.BinaryReader, error) {
+	size, err := r.Size(index)
+	if err != nil {
+		return nil, err
+	}
+
+	section := io.NewSectionReader(r.source, r.offsets[index], int64(size))
+
+	return binutils.NewBinaryReader(section).Sub(size), nil
+}
+
+// Decode decodes record having specified index into target using BinaryReadFrom.
+// Record bytes left unread are skipped, or binutils.ErrUnconsumed returned in strict finish mode.
+func (r *Reader) Decode(index int, target binutils.BinaryReaderFrom) error {
+	record, err := r.Get(index)
+	if err != nil {
+		return err
+	}
+
+	if err = target.BinaryReadFrom(record); err != nil {
+		return err
+	}
+
+	return record.Finish()
+}
+
+// Forward returns iterator over records from the first to the last one.
+func (r *Reader) Forward() *Iterator {
+	return &Iterator{reader: r, next: 0, step: 1}
+}
+
+// Backward returns iterator over records from the last to the first one.
+func (r *Reader) Backward() *Iterator {
+	return &Iterator{reader: r, next: r.Len() - 1, step: -1}
+}
+
+// Close closes source if it implements io.Closer.
+func (r *Reader) Close() error {
+	if closer, ok := r.source.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// Iterator iterates over records in forward or backward direction.
+type Iterator struct {
+	reader *Reader
+	next   int
+	step   int
+	index  int
+}
+
+// Next returns reader of the next record. Returns io.EOF if no more records available.
+func (i *Iterator) Next() (*binutils.BinaryReader, error) {
+	if i.next < 0 || i.next >= i.reader.Len() {
+		return nil, io.EOF
+	}
+
+	i.index = i.next
+	i.next += i.step
+
+	return i.reader.Get(i.index)
+}
+
+// Index returns index of record returned by the last Next call.
+func (i *Iterator) Index() int {
+	return i.index
+}
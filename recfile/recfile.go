@@ -0,0 +1,204 @@
+// Package recfile implements indexed record files supporting random access to records.
+//
+// Records are written back to back followed by an index of record offsets and a fixed size footer.
+// Footer holds magic, format version, records count, index offset and CRC-32 of index and footer fields.
+// Reader loads footer and index only, records are read on demand using io.ReaderAt.
+package recfile
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/amarin/binutils"
+)
+
+// Footer layout constants.
+const (
+	// Magic identifies record file footer.
+	Magic = "BREC"
+	// Version is a current format version.
+	Version uint16 = 1
+	// FooterSize is a footer length in bytes:
+	// magic, version uint16, reserved uint16, count uint64, index offset uint64 and CRC-32 uint32.
+	FooterSize = 4 + binutils.Uint16size + binutils.Uint16size + binutils.Uint64size + binutils.Uint64size +
+		binutils.Uint32size
+)
+
+// Some predefined errors used during processing.
+var (
+	// Error indicates any record file errors.
+	Error = fmt.Errorf("%w: recfile", binutils.Error)
+
+	// ErrFormat returned if file has no valid footer or unsupported version.
+	ErrFormat = fmt.Errorf("%w: invalid format", Error)
+
+	// ErrChecksum returned if index or footer checksum mismatch.
+	ErrChecksum = fmt.Errorf("%w: checksum mismatch", Error)
+
+	// ErrIndex returned if record index is out of range.
+	ErrIndex = fmt.Errorf("%w: index out of range", Error)
+
+	// ErrClosed returned if writer used after Close.
+	ErrClosed = fmt.Errorf("%w: closed", Error)
+
+	// ErrFailed returned if writer used after record was partially written.
+	ErrFailed = fmt.Errorf("%w: writer failed", Error)
+)
+
+// Writer appends records and writes index and footer on Close.
+type Writer struct {
+	target   io.Writer
+	buffered *bufio.Writer
+	writer   *binutils.BinaryWriter
+	offsets  []uint64
+	failed   error // error of partially written record, index is never written if set
+	closed   bool
+}
+
+// Create creates file and returns Writer appending records into it.
+func Create(filePath string) (*Writer, error) {
+	absFileName, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("%v: resolve path: %w", Error, err)
+	}
+
+	file, err := os.Create(absFileName)
+	if err != nil {
+		return nil, fmt.Errorf("%v: create file: %w", Error, err)
+	}
+
+	return NewWriter(file), nil
+}
+
+// NewWriter creates Writer appending records into target.
+// Target is closed by Close if it implements io.Closer.
+func NewWriter(target io.Writer) *Writer {
+	buffered := bufio.NewWriter(target)
+
+	return &Writer{target: target, buffered: buffered, writer: binutils.NewBinaryWriter(buffered)}
+}
+
+// Len returns number of records appended.
+func (w *Writer) Len() int {
+	return len(w.offsets)
+}
+
+// Append encodes record using BinaryWriteTo and appends it. Returns record index.
+// Record is indexed only if encoded successfully. If failed record is partially written
+// writer is failed, subsequent calls and Close return ErrFailed.
+func (w *Writer) Append(record binutils.BinaryWriterTo) (int, error) {
+	return w.append(record.BinaryWriteTo)
+}
+
+// AppendBytes appends raw record. Returns record index.
+// Writer is failed if record is partially written, see Append.
+func (w *Writer) AppendBytes(record []byte) (int, error) {
+	return w.append(func(writer *binutils.BinaryWriter) error { return writer.WriteBytes(record) })
+}
+
+// append writes record using write function and indexes it if succeeded.
+func (w *Writer) append(write func(writer *binutils.BinaryWriter) error) (int, error) {
+	switch {
+	case w.closed:
+		return 0, ErrClosed
+	case w.failed != nil:
+		return 0, fmt.Errorf("%w: %v", ErrFailed, w.failed)
+	}
+
+	offset := w.writer.BytesWritten()
+	if err := write(w.writer); err != nil {
+		if w.writer.BytesWritten() != offset {
+			w.failed = err
+		}
+
+		return 0, err
+	}
+
+	w.offsets = append(w.offsets, uint64(offset))
+
+	return len(w.offsets) - 1, nil
+}
+
+// Close writes index and footer, flushes buffered data and closes target if it implements io.Closer.
+// If writer is failed index is not written, target is closed and ErrFailed returned.
+func (w *Writer) Close() error {
+	if w.closed {
+		return ErrClosed
+	}
+
+	w.closed = true
+
+	if w.failed != nil {
+		if closer, ok := w.target.(io.Closer); ok {
+			closer.Close() // nolint:errcheck // append error takes precedence
+		}
+
+		return fmt.Errorf("%w: index not written: %v", ErrFailed, w.failed)
+	}
+
+	indexOffset := uint64(w.writer.BytesWritten())
+	index := make([]byte, 0, len(w.offsets)*binutils.Uint64size+FooterSize)
+
+	for _, offset := range w.offsets {
+		index = binutils.AppendUint64(index, offset)
+	}
+
+	index = append(index, Magic...)
+	index = binutils.AppendUint16(index, Version)
+	index = binutils.AppendUint16(index, 0) // reserved
+	index = binutils.AppendUint64(index, uint64(len(w.offsets)))
+	index = binutils.AppendUint64(index, indexOffset)
+	index = binutils.AppendUint32(index, crc32.ChecksumIEEE(index))
+
+	if err := w.writer.WriteBytes(index); err != nil {
+		return err
+	}
+
+	if err := w.buffered.Flush(); err != nil {
+		return err
+	}
+
+	if closer, ok := w.target.(io.Closer); ok {
+		return closer.Close()
+	}
+
+	return nil
+}
+
+// footer holds decoded footer fields.
+type footer struct {
+	count       uint64
+	indexOffset uint64
+	checksum    uint32
+}
+
+// parseFooter decodes and validates footer fields except checksum.
+func parseFooter(data []byte, size int64) (result footer, err error) {
+	if string(data[:len(Magic)]) != Magic {
+		return result, fmt.Errorf("%w: no footer magic", ErrFormat)
+	}
+
+	data = data[len(Magic):]
+	if version := binary.BigEndian.Uint16(data); version != Version {
+		return result, fmt.Errorf("%w: unsupported version %d", ErrFormat, version)
+	}
+
+	data = data[2*binutils.Uint16size:]
+	result.count = binary.BigEndian.Uint64(data)
+	result.indexOffset = binary.BigEndian.Uint64(data[binutils.Uint64size:])
+	result.checksum = binary.BigEndian.Uint32(data[2*binutils.Uint64size:])
+
+	indexEnd := uint64(size - FooterSize)
+	if result.indexOffset > indexEnd || (indexEnd-result.indexOffset)/binutils.Uint64size != result.count ||
+		(indexEnd-result.indexOffset)%binutils.Uint64size != 0 {
+		return result, fmt.Errorf("%w: index of %d records at %d does not match file size %d",
+			ErrFormat, result.count, result.indexOffset, size)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,245 @@
+package recfile_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amarin/binutils"
+	"github.com/amarin/binutils/recfile"
+)
+
+type entry struct {
+	ID   uint32
+	Name string
+}
+
+func (e entry) BinaryWriteTo(writer *binutils.BinaryWriter) error {
+	if err := writer.WriteUint32(e.ID); err != nil {
+		return err
+	}
+
+	return writer.WriteStringZ(e.Name)
+}
+
+func (e *entry) BinaryReadFrom(reader *binutils.BinaryReader) (err error) {
+	if e.ID, err = reader.ReadUint32(); err != nil {
+		return err
+	}
+
+	e.Name, err = reader.ReadStringZ()
+
+	return err
+}
+
+func writeEntries(t *testing.T, target io.Writer, count int) {
+	writer := recfile.NewWriter(target)
+
+	for id := 0; id < count; id++ {
+		index, err := writer.Append(entry{ID: uint32(id), Name: fmt.Sprintf("entry-%d", id)})
+		require.NoError(t, err)
+		require.Equal(t, id, index)
+	}
+
+	index, err := writer.AppendBytes(nil)
+	require.NoError(t, err)
+	require.Equal(t, count, index)
+	require.Equal(t, count+1, writer.Len())
+	require.NoError(t, writer.Close())
+
+	_, err = writer.AppendBytes(nil)
+	require.True(t, errors.Is(err, recfile.ErrClosed))
+}
+
+func TestReader_Get(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	writeEntries(t, buffer, 100)
+
+	reader, err := recfile.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+	require.NoError(t, err)
+	require.Equal(t, 101, reader.Len())
+
+	var value entry
+	require.NoError(t, reader.Decode(42, &value))
+	require.Equal(t, entry{ID: 42, Name: "entry-42"}, value)
+
+	size, err := reader.Size(42)
+	require.NoError(t, err)
+	require.Equal(t, 4+len("entry-42")+1, size)
+
+	empty, err := reader.Get(100)
+	require.NoError(t, err)
+	require.Equal(t, 0, empty.Remaining())
+
+	_, err = reader.Get(101)
+	require.True(t, errors.Is(err, recfile.ErrIndex))
+
+	_, err = reader.Get(-1)
+	require.True(t, errors.Is(err, recfile.ErrIndex))
+	require.NoError(t, reader.Close())
+}
+
+func TestReader_Iterators(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recfile")
+	require.NoError(t, err)
+
+	defer os.RemoveAll(dir) // nolint:errcheck
+
+	path := filepath.Join(dir, "records.bin")
+	file, err := recfile.Create(path)
+	require.NoError(t, err)
+
+	for _, name := range []string{"a", "b", "c"} {
+		_, err = file.AppendBytes([]byte(name))
+		require.NoError(t, err)
+	}
+
+	require.NoError(t, file.Close())
+
+	reader, err := recfile.Open(path)
+	require.NoError(t, err)
+
+	defer func() { require.NoError(t, reader.Close()) }()
+
+	for _, tt := range []struct {
+		name     string
+		iterator *recfile.Iterator
+		expected string
+		indexes  []int
+	}{
+		{"forward", reader.Forward(), "abc", []int{0, 1, 2}},
+		{"backward", reader.Backward(), "cba", []int{2, 1, 0}},
+	} {
+		var (
+			taken   string
+			indexes []int
+		)
+
+		for {
+			record, err := tt.iterator.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			require.NoError(t, err)
+
+			data, err := record.ReadBytesCount(record.Remaining())
+			require.NoError(t, err)
+
+			taken += string(data)
+			indexes = append(indexes, tt.iterator.Index())
+		}
+
+		require.Equal(t, tt.expected, taken, tt.name)
+		require.Equal(t, tt.indexes, indexes, tt.name)
+	}
+}
+
+// setIndexEntry sets offset of record idx in index and updates footer checksum.
+func setIndexEntry(data []byte, idx int, offset uint64) []byte {
+	footer := data[len(data)-recfile.FooterSize:]
+	count := int(binary.BigEndian.Uint64(footer[4+2*binutils.Uint16size:]))
+	index := data[len(data)-recfile.FooterSize-count*binutils.Uint64size : len(data)-recfile.FooterSize]
+	binary.BigEndian.PutUint64(index[idx*binutils.Uint64size:], offset)
+
+	checksum := crc32.Update(crc32.ChecksumIEEE(index), crc32.IEEETable, footer[:recfile.FooterSize-binutils.Uint32size])
+	binary.BigEndian.PutUint32(footer[recfile.FooterSize-binutils.Uint32size:], checksum)
+
+	return data
+}
+
+func TestNewReader_Errors(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	writeEntries(t, buffer, 3)
+	data := buffer.Bytes()
+
+	for _, tt := range []struct {
+		name     string
+		damage   func(data []byte) []byte
+		expected error
+	}{
+		{"short", func(data []byte) []byte { return data[:recfile.FooterSize-1] }, recfile.ErrFormat},
+		{"magic", func(data []byte) []byte { data[len(data)-recfile.FooterSize] = 'X'; return data }, recfile.ErrFormat},
+		{"version", func(data []byte) []byte { data[len(data)-recfile.FooterSize+5] = 9; return data }, recfile.ErrFormat},
+		{"count", func(data []byte) []byte { data[len(data)-recfile.FooterSize+15] = 9; return data }, recfile.ErrFormat},
+		{"truncated", func(data []byte) []byte { return data[1:] }, recfile.ErrFormat},
+		{"index", func(data []byte) []byte { data[len(data)-recfile.FooterSize-1] = 1; return data }, recfile.ErrChecksum},
+		{"checksum", func(data []byte) []byte { data[len(data)-1] ^= 1; return data }, recfile.ErrChecksum},
+		{"negative_offset", func(data []byte) []byte { return setIndexEntry(data, 0, 1<<63) }, recfile.ErrFormat},
+		{"offset_past_index", func(data []byte) []byte { return setIndexEntry(data, 2, 1<<20) }, recfile.ErrFormat},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			damaged := tt.damage(append([]byte(nil), data...))
+			_, err := recfile.NewReader(bytes.NewReader(damaged), int64(len(damaged)))
+			require.True(t, errors.Is(err, tt.expected), err)
+		})
+	}
+}
+
+// failingRecord writes prefix bytes and returns configured error.
+type failingRecord struct {
+	prefix []byte
+	err    error
+}
+
+func (r failingRecord) BinaryWriteTo(writer *binutils.BinaryWriter) error {
+	if err := writer.WriteBytes(r.prefix); err != nil {
+		return err
+	}
+
+	return r.err
+}
+
+func TestWriter_AppendError(t *testing.T) {
+	recordError := errors.New("record error")
+
+	t.Run("nothing_written", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		writer := recfile.NewWriter(buffer)
+
+		_, err := writer.Append(failingRecord{err: recordError})
+		require.Equal(t, recordError, err)
+		require.Equal(t, 0, writer.Len(), "failed record must not be indexed")
+
+		index, err := writer.AppendBytes([]byte{1})
+		require.NoError(t, err)
+		require.Equal(t, 0, index)
+		require.NoError(t, writer.Close())
+
+		reader, err := recfile.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+		require.NoError(t, err)
+		require.Equal(t, 1, reader.Len())
+	})
+
+	t.Run("partially_written", func(t *testing.T) {
+		buffer := new(bytes.Buffer)
+		writer := recfile.NewWriter(buffer)
+
+		_, err := writer.AppendBytes([]byte{1})
+		require.NoError(t, err)
+
+		_, err = writer.Append(failingRecord{prefix: []byte{2, 3}, err: recordError})
+		require.Equal(t, recordError, err)
+		require.Equal(t, 1, writer.Len(), "failed record must not be indexed")
+
+		_, err = writer.AppendBytes([]byte{4})
+		require.True(t, errors.Is(err, recfile.ErrFailed), err)
+
+		err = writer.Close()
+		require.True(t, errors.Is(err, recfile.ErrFailed), err)
+		require.Contains(t, err.Error(), recordError.Error())
+
+		_, err = recfile.NewReader(bytes.NewReader(buffer.Bytes()), int64(buffer.Len()))
+		require.True(t, errors.Is(err, recfile.ErrFormat), "index must not be written")
+	})
+}
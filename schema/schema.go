@@ -0,0 +1,320 @@
+// Package schema implements declarative binary layouts interpreted on top of binutils.BinaryReader
+// and binutils.BinaryWriter.
+//
+// Layout is described by Go values: primitive types such as Uint16 or StringZ, nested structures built by Struct,
+// repeated values built by Array with fixed count or count taken from earlier field using Ref,
+// and conditional fields having Field.If condition evaluated against earlier fields.
+//
+// Decode produces generic tree of values: structures are decoded into map[string]interface{},
+// arrays into []interface{}, raw bytes into []byte, strings into string and numbers into Go types
+// matching primitive types, e.g. uint16 for Uint16. Encode accepts the same tree back,
+// numbers may be of any Go numeric type fitting into field type.
+//
+//	header := schema.Struct(
+//		schema.Field{Name: "version", Type: schema.Uint8},
+//		schema.Field{Name: "flags", Type: schema.Uint8},
+//		schema.Field{Name: "timestamp", Type: schema.Uint32, If: schema.HasBits("flags", 1)},
+//		schema.Field{Name: "count", Type: schema.Uint16},
+//		schema.Field{Name: "names", Type: schema.Array(schema.StringZ, schema.Ref("count"))},
+//	)
+//	tree, err := schema.Decode(reader, header)
+package schema
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/amarin/binutils"
+)
+
+// Some predefined errors used during processing.
+var (
+	// Error indicates any schema errors.
+	Error = fmt.Errorf("%w: schema", binutils.Error)
+
+	// ErrValue returned if encoded value type does not match field type or value does not fit into it.
+	ErrValue = fmt.Errorf("%w: invalid value", Error)
+
+	// ErrMissing returned if encoded structure misses present field.
+	ErrMissing = fmt.Errorf("%w: missing field", Error)
+
+	// ErrCount returned if repeat count is negative or does not match encoded array length.
+	ErrCount = fmt.Errorf("%w: invalid count", Error)
+
+	// ErrReference returned if count or condition refers unknown or non-numeric field.
+	ErrReference = fmt.Errorf("%w: invalid reference", Error)
+)
+
+// FieldError describes error of particular field decoding or encoding.
+type FieldError struct {
+	Path string // field path, e.g. header.items[2].name
+	Err  error  // underlying error
+}
+
+// Error returns error text prefixed by field path.
+func (e *FieldError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+// Unwrap returns underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// wrapPath returns err prefixed by path element, path element is either field name or [index].
+func wrapPath(element string, err error) error {
+	var fieldError *FieldError
+	if !errors.As(err, &fieldError) {
+		return &FieldError{Path: element, Err: err}
+	}
+
+	if fieldError.Path[0] == '[' {
+		return &FieldError{Path: element + fieldError.Path, Err: fieldError.Err}
+	}
+
+	return &FieldError{Path: element + "." + fieldError.Path, Err: fieldError.Err}
+}
+
+// Type describes binary layout of value.
+type Type interface {
+	// decode takes value from reader.
+	decode(reader *binutils.BinaryReader, scope *Scope) (interface{}, error)
+	// encode puts value into writer.
+	encode(writer *binutils.BinaryWriter, scope *Scope, value interface{}) error
+}
+
+// Decode takes value described by layout from reader.
+// Returns decoded tree or error wrapped into FieldError if any field decoding failed.
+func Decode(reader *binutils.BinaryReader, layout Type) (interface{}, error) {
+	return layout.decode(reader, nil)
+}
+
+// Encode puts value tree described by layout into writer.
+// Returns error wrapped into FieldError if any field encoding failed.
+func Encode(writer *binutils.BinaryWriter, layout Type, value interface{}) error {
+	return layout.encode(writer, nil, value)
+}
+
+// Scope gives access to already processed fields of current and enclosing structures.
+type Scope struct {
+	record map[string]interface{} // current structure processed fields
+	parent *Scope                 // enclosing structure scope or nil
+}
+
+// Value returns processed field value looking up current structure first, then enclosing ones.
+func (s *Scope) Value(name string) (interface{}, bool) {
+	for scope := s; scope != nil; scope = scope.parent {
+		if value, ok := scope.record[name]; ok {
+			return value, true
+		}
+	}
+
+	return nil, false
+}
+
+// uint returns processed numeric field value.
+func (s *Scope) uint(name string) (uint64, error) {
+	value, ok := s.Value(name)
+	if !ok {
+		return 0, fmt.Errorf("%w: unknown field %q", ErrReference, name)
+	}
+
+	number, err := toUint64(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: field %q: %v", ErrReference, name, err)
+	}
+
+	return number, nil
+}
+
+// Condition decides if conditional field present using already processed fields.
+type Condition func(scope *Scope) bool
+
+// Equal returns condition satisfied if numeric field equals to value.
+// Condition is not satisfied if field is unknown or non-numeric.
+func Equal(name string, value uint64) Condition {
+	return func(scope *Scope) bool {
+		number, err := scope.uint(name)
+		return err == nil && number == value
+	}
+}
+
+// HasBits returns condition satisfied if numeric field has all mask bits set.
+// Condition is not satisfied if field is unknown or non-numeric.
+func HasBits(name string, mask uint64) Condition {
+	return func(scope *Scope) bool {
+		number, err := scope.uint(name)
+		return err == nil && number&mask == mask
+	}
+}
+
+// Field describes named structure field.
+type Field struct {
+	Name string    // field name used as decoded map key
+	Type Type      // field layout
+	If   Condition // presence condition, nil if field is always present
+}
+
+// structType implements Struct.
+type structType []Field
+
+// Struct returns layout of structure fields sequence decoded into map[string]interface{}.
+// Absent conditional fields are not included into decoded map and ignored when encoding.
+func Struct(fields ...Field) Type {
+	return structType(fields)
+}
+
+// decode implements Type.
+func (t structType) decode(reader *binutils.BinaryReader, parent *Scope) (interface{}, error) {
+	scope := &Scope{record: make(map[string]interface{}, len(t)), parent: parent}
+
+	for _, field := range t {
+		if field.If != nil && !field.If(scope) {
+			continue
+		}
+
+		value, err := field.Type.decode(reader, scope)
+		if err != nil {
+			return scope.record, wrapPath(field.Name, err)
+		}
+
+		scope.record[field.Name] = value
+	}
+
+	return scope.record, nil
+}
+
+// encode implements Type.
+func (t structType) encode(writer *binutils.BinaryWriter, parent *Scope, value interface{}) error {
+	record, ok := value.(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%w: expected map[string]interface{}, got %T", ErrValue, value)
+	}
+
+	// conditions and references see fields encoded so far only, the same as when decoding
+	scope := &Scope{record: make(map[string]interface{}, len(t)), parent: parent}
+
+	for _, field := range t {
+		if field.If != nil && !field.If(scope) {
+			continue
+		}
+
+		fieldValue, ok := record[field.Name]
+		if !ok {
+			return wrapPath(field.Name, ErrMissing)
+		}
+
+		if err := field.Type.encode(writer, scope, fieldValue); err != nil {
+			return wrapPath(field.Name, err)
+		}
+
+		scope.record[field.Name] = fieldValue
+	}
+
+	return nil
+}
+
+// Count defines repeat count of Array or Bytes.
+type Count interface {
+	// count returns repeat count.
+	count(scope *Scope) (int, error)
+}
+
+// Fixed defines constant repeat count.
+type Fixed int
+
+// count implements Count.
+func (c Fixed) count(*Scope) (int, error) {
+	if c < 0 {
+		return 0, fmt.Errorf("%w: %d", ErrCount, int(c))
+	}
+
+	return int(c), nil
+}
+
+// Ref defines repeat count taken from earlier numeric field of current or enclosing structures.
+type Ref string
+
+// count implements Count.
+func (c Ref) count(scope *Scope) (int, error) {
+	number, err := scope.uint(string(c))
+	if err != nil {
+		return 0, err
+	}
+
+	if count := int(number); count >= 0 && uint64(count) == number {
+		return count, nil
+	}
+
+	return 0, fmt.Errorf("%w: field %q value %d", ErrCount, string(c), number)
+}
+
+// arrayType implements Array.
+type arrayType struct {
+	element Type  // element layout
+	count   Count // elements count
+}
+
+// Array returns layout of repeated elements decoded into []interface{}.
+// Encoded array length must match count.
+func Array(element Type, count Count) Type {
+	return arrayType{element: element, count: count}
+}
+
+// decode implements Type.
+func (t arrayType) decode(reader *binutils.BinaryReader, scope *Scope) (interface{}, error) {
+	count, err := t.count.count(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]interface{}, 0, minCapacity(count))
+
+	for idx := 0; idx < count; idx++ {
+		value, err := t.element.decode(reader, scope)
+		if err != nil {
+			return values, wrapPath(fmt.Sprintf("[%d]", idx), err)
+		}
+
+		values = append(values, value)
+	}
+
+	return values, nil
+}
+
+// encode implements Type.
+func (t arrayType) encode(writer *binutils.BinaryWriter, scope *Scope, value interface{}) error {
+	values, ok := value.([]interface{})
+	if !ok {
+		return fmt.Errorf("%w: expected []interface{}, got %T", ErrValue, value)
+	}
+
+	count, err := t.count.count(scope)
+	if err != nil {
+		return err
+	}
+
+	if count != len(values) {
+		return fmt.Errorf("%w: expected %d elements, got %d", ErrCount, count, len(values))
+	}
+
+	for idx, element := range values {
+		if err = t.element.encode(writer, scope, element); err != nil {
+			return wrapPath(fmt.Sprintf("[%d]", idx), err)
+		}
+	}
+
+	return nil
+}
+
+// maxPreallocated limits elements preallocated for decoded array, so corrupted count fails on read, not on allocation.
+const maxPreallocated = 1024
+
+// minCapacity returns count limited by maxPreallocated.
+func minCapacity(count int) int {
+	if count > maxPreallocated {
+		return maxPreallocated
+	}
+
+	return count
+}
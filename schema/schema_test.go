@@ -0,0 +1,269 @@
+package schema_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amarin/binutils"
+	"github.com/amarin/binutils/schema"
+)
+
+// point is a nested structure layout.
+var point = schema.Struct(
+	schema.Field{Name: "x", Type: schema.Int16},
+	schema.Field{Name: "y", Type: schema.Int16},
+)
+
+// message is a versioned layout using conditional, repeated and nested fields.
+var message = schema.Struct(
+	schema.Field{Name: "version", Type: schema.Uint8},
+	schema.Field{Name: "flags", Type: schema.Uint8},
+	schema.Field{Name: "timestamp", Type: schema.Uint32, If: schema.HasBits("flags", 1)},
+	schema.Field{Name: "count", Type: schema.Uint16},
+	schema.Field{Name: "points", Type: schema.Array(point, schema.Ref("count"))},
+	schema.Field{Name: "name", Type: schema.StringZ},
+	schema.Field{
+		Name: "checksum",
+		Type: schema.Order(binary.LittleEndian, schema.Uint32),
+		If:   schema.Equal("version", 2),
+	},
+	schema.Field{Name: "groups", Type: schema.Array(schema.Struct(
+		schema.Field{Name: "size", Type: schema.Uvarint},
+		schema.Field{Name: "data", Type: schema.Bytes(schema.Ref("size"))},
+		schema.Field{Name: "tags", Type: schema.Array(schema.Varint, schema.Ref("count"))}, // enclosing count
+	), schema.Fixed(2))},
+	schema.Field{Name: "ratio", Type: schema.Float32},
+)
+
+func TestDecodeEncode(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		data []byte
+		tree map[string]interface{}
+	}{
+		{
+			name: "version_1",
+			data: []byte{
+				1, 0, 0, 1, 0xff, 0xfe, 0, 2, 'a', 0,
+				1, 0xaa, 1, 0, 0,
+				0x3f, 0xc0, 0, 0,
+			},
+			tree: map[string]interface{}{
+				"version": uint8(1),
+				"flags":   uint8(0),
+				"count":   uint16(1),
+				"points":  []interface{}{map[string]interface{}{"x": int16(-2), "y": int16(2)}},
+				"name":    "a",
+				"groups": []interface{}{
+					map[string]interface{}{"size": uint64(1), "data": []byte{0xaa}, "tags": []interface{}{int64(-1)}},
+					map[string]interface{}{"size": uint64(0), "data": []byte{}, "tags": []interface{}{int64(0)}},
+				},
+				"ratio": float32(1.5),
+			},
+		},
+		{
+			name: "version_2",
+			data: []byte{
+				2, 1, 0x5f, 0x5e, 0x10, 0, 0, 0, 0, 0x78, 0x56, 0x34, 0x12,
+				0, 0,
+				0, 0, 0, 0,
+			},
+			tree: map[string]interface{}{
+				"version":   uint8(2),
+				"flags":     uint8(1),
+				"timestamp": uint32(0x5f5e1000),
+				"count":     uint16(0),
+				"points":    []interface{}{},
+				"name":      "",
+				"checksum":  uint32(0x12345678),
+				"groups": []interface{}{
+					map[string]interface{}{"size": uint64(0), "data": []byte{}, "tags": []interface{}{}},
+					map[string]interface{}{"size": uint64(0), "data": []byte{}, "tags": []interface{}{}},
+				},
+				"ratio": float32(0),
+			},
+		},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			reader := binutils.NewBinaryReader(bytes.NewReader(tt.data))
+			tree, err := schema.Decode(reader, message)
+			require.NoError(t, err)
+			require.Equal(t, tt.tree, tree)
+			require.Equal(t, binary.BigEndian, reader.ByteOrder(), "reader byte order must be restored")
+
+			buffer := new(bytes.Buffer)
+			require.NoError(t, schema.Encode(binutils.NewBinaryWriter(buffer), message, tt.tree))
+			require.Equal(t, tt.data, buffer.Bytes())
+		})
+	}
+}
+
+func TestEncode_Conversions(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	layout := schema.Struct(
+		schema.Field{Name: "count", Type: schema.Uint8},
+		schema.Field{Name: "values", Type: schema.Array(schema.Int32, schema.Ref("count"))},
+		schema.Field{Name: "big", Type: schema.Uint64},
+		schema.Field{Name: "real", Type: schema.Float64},
+	)
+
+	require.NoError(t, schema.Encode(binutils.NewBinaryWriter(buffer), layout, map[string]interface{}{
+		"count":  2,
+		"values": []interface{}{-1, uint8(1)},
+		"big":    uint(7),
+		"real":   3,
+	}))
+	require.Equal(t, []byte{
+		2, 0xff, 0xff, 0xff, 0xff, 0, 0, 0, 1, 0, 0, 0, 0, 0, 0, 0, 7, 0x40, 0x08, 0, 0, 0, 0, 0, 0,
+	}, buffer.Bytes())
+}
+
+func TestEncode_Errors(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		layout   schema.Type
+		value    interface{}
+		expected error
+		path     string
+	}{
+		{
+			name:     "not_map",
+			layout:   point,
+			value:    []interface{}{},
+			expected: schema.ErrValue,
+		},
+		{
+			name:     "missing",
+			layout:   point,
+			value:    map[string]interface{}{"x": 1},
+			expected: schema.ErrMissing,
+			path:     "y",
+		},
+		{
+			name:     "overflow",
+			layout:   point,
+			value:    map[string]interface{}{"x": 1, "y": 40000},
+			expected: schema.ErrValue,
+			path:     "y",
+		},
+		{
+			name:     "negative_unsigned",
+			layout:   schema.Struct(schema.Field{Name: "u", Type: schema.Uvarint}),
+			value:    map[string]interface{}{"u": -1},
+			expected: schema.ErrValue,
+			path:     "u",
+		},
+		{
+			name:     "string_type",
+			layout:   schema.Array(schema.StringZ, schema.Fixed(1)),
+			value:    []interface{}{1},
+			expected: schema.ErrValue,
+			path:     "[0]",
+		},
+		{
+			name: "count_mismatch",
+			layout: schema.Struct(
+				schema.Field{Name: "n", Type: schema.Uint8},
+				schema.Field{Name: "items", Type: schema.Array(point, schema.Ref("n"))},
+			),
+			value:    map[string]interface{}{"n": 2, "items": []interface{}{map[string]interface{}{"x": 1, "y": 1}}},
+			expected: schema.ErrCount,
+			path:     "items",
+		},
+		{
+			name:     "nested_path",
+			layout:   schema.Struct(schema.Field{Name: "items", Type: schema.Array(point, schema.Fixed(2))}),
+			value:    map[string]interface{}{"items": []interface{}{map[string]interface{}{"x": 1, "y": 1}, nil}},
+			expected: schema.ErrValue,
+			path:     "items[1]",
+		},
+		{
+			name:     "unknown_reference",
+			layout:   schema.Struct(schema.Field{Name: "data", Type: schema.Bytes(schema.Ref("size"))}),
+			value:    map[string]interface{}{"data": []byte{}},
+			expected: schema.ErrReference,
+			path:     "data",
+		},
+		{
+			name:     "negative_fixed",
+			layout:   schema.Bytes(schema.Fixed(-1)),
+			value:    []byte{},
+			expected: schema.ErrCount,
+		},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			err := schema.Encode(binutils.NewBinaryWriter(new(bytes.Buffer)), tt.layout, tt.value)
+			require.True(t, errors.Is(err, tt.expected), "unexpected error %v", err)
+
+			var fieldError *schema.FieldError
+			if tt.path == "" {
+				require.False(t, errors.As(err, &fieldError))
+				return
+			}
+
+			require.True(t, errors.As(err, &fieldError))
+			require.Equal(t, tt.path, fieldError.Path)
+		})
+	}
+}
+
+func TestDecode_Errors(t *testing.T) {
+	data := []byte{1, 0, 0, 2, 0, 1, 0, 2, 0, 3, 0}
+	tree, err := schema.Decode(binutils.NewBinaryReader(bytes.NewReader(data)), message)
+	require.True(t, errors.Is(err, io.ErrUnexpectedEOF), "unexpected error %v", err)
+	require.Equal(t, "points[1].y: unexpected EOF", err.Error())
+	require.Equal(t, uint16(2), tree.(map[string]interface{})["count"], "fields decoded before error must be returned")
+
+	layout := schema.Struct(
+		schema.Field{Name: "name", Type: schema.StringZ},
+		schema.Field{Name: "items", Type: schema.Array(schema.Uint8, schema.Ref("name"))},
+	)
+	_, err = schema.Decode(binutils.NewBinaryReader(bytes.NewReader([]byte{0})), layout)
+	require.True(t, errors.Is(err, schema.ErrReference), "non-numeric reference must fail, got %v", err)
+}
+
+func TestDecode_OversizedBytes(t *testing.T) {
+	for _, tt := range []struct {
+		name     string
+		size     schema.Type
+		data     []byte
+		expected error
+	}{
+		{
+			name:     "uint32",
+			size:     schema.Uint32,
+			data:     []byte{0xff, 0xff, 0xff, 0xff, 1, 2, 3},
+			expected: io.ErrUnexpectedEOF,
+		},
+		{
+			name:     "uint64",
+			size:     schema.Uint64,
+			data:     []byte{0x3f, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 1},
+			expected: io.ErrUnexpectedEOF,
+		},
+		{
+			name:     "overflow",
+			size:     schema.Uint64,
+			data:     []byte{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+			expected: schema.ErrCount,
+		},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			layout := schema.Struct(
+				schema.Field{Name: "size", Type: tt.size},
+				schema.Field{Name: "data", Type: schema.Bytes(schema.Ref("size"))},
+			)
+
+			_, err := schema.Decode(binutils.NewBinaryReader(bytes.NewReader(tt.data)), layout)
+			require.True(t, errors.Is(err, tt.expected), "unexpected error %v", err)
+		})
+	}
+}
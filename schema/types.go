@@ -0,0 +1,335 @@
+package schema
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/amarin/binutils"
+)
+
+// primitive implements fixed or variable size scalar types.
+type primitive int
+
+// Primitive types. Multi-byte fixed size numbers use reader or writer byte order, see Order.
+const (
+	// Uint8 is unsigned byte decoded into uint8.
+	Uint8 primitive = iota
+	// Uint16 is 2 bytes unsigned integer decoded into uint16.
+	Uint16
+	// Uint32 is 4 bytes unsigned integer decoded into uint32.
+	Uint32
+	// Uint64 is 8 bytes unsigned integer decoded into uint64.
+	Uint64
+	// Int8 is signed byte decoded into int8.
+	Int8
+	// Int16 is 2 bytes signed integer decoded into int16.
+	Int16
+	// Int32 is 4 bytes signed integer decoded into int32.
+	Int32
+	// Int64 is 8 bytes signed integer decoded into int64.
+	Int64
+	// Float32 is 4 bytes IEEE 754 number decoded into float32.
+	Float32
+	// Float64 is 8 bytes IEEE 754 number decoded into float64.
+	Float64
+	// Uvarint is unsigned base 128 varint decoded into uint64.
+	Uvarint
+	// Varint is zig-zag signed base 128 varint decoded into int64.
+	Varint
+	// StringZ is zero-terminated string using reader or writer text encoding decoded into string.
+	StringZ
+)
+
+// decode implements Type.
+func (t primitive) decode(reader *binutils.BinaryReader, _ *Scope) (interface{}, error) {
+	switch t {
+	case Uint8:
+		return reader.ReadUint8()
+	case Uint16:
+		return reader.ReadUint16()
+	case Uint32:
+		return reader.ReadUint32()
+	case Uint64:
+		return reader.ReadUint64()
+	case Int8:
+		return reader.ReadInt8()
+	case Int16:
+		return reader.ReadInt16()
+	case Int32:
+		return reader.ReadInt32()
+	case Int64:
+		return reader.ReadInt64()
+	case Float32:
+		bits, err := reader.ReadUint32()
+		return math.Float32frombits(bits), err
+	case Float64:
+		bits, err := reader.ReadUint64()
+		return math.Float64frombits(bits), err
+	case Uvarint:
+		return reader.ReadUvarint()
+	case Varint:
+		return reader.ReadVarint()
+	default:
+		return reader.ReadStringZ()
+	}
+}
+
+// encode implements Type.
+func (t primitive) encode(writer *binutils.BinaryWriter, _ *Scope, value interface{}) error {
+	switch t {
+	case Float32, Float64:
+		number, err := toFloat64(value)
+		if err != nil {
+			return err
+		}
+
+		if t == Float32 {
+			return writer.WriteUint32(math.Float32bits(float32(number)))
+		}
+
+		return writer.WriteUint64(math.Float64bits(number))
+	case StringZ:
+		text, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%w: expected string, got %T", ErrValue, value)
+		}
+
+		return writer.WriteStringZ(text)
+	case Int8, Int16, Int32, Int64, Varint:
+		return t.encodeSigned(writer, value)
+	default:
+		return t.encodeUnsigned(writer, value)
+	}
+}
+
+// encodeSigned puts signed integer value into writer checking it fits into type.
+func (t primitive) encodeSigned(writer *binutils.BinaryWriter, value interface{}) error {
+	number, err := toInt64(value)
+	if err != nil {
+		return err
+	}
+
+	switch t {
+	case Int8:
+		if number < math.MinInt8 || number > math.MaxInt8 {
+			return fmt.Errorf("%w: %d overflows int8", ErrValue, number)
+		}
+
+		return writer.WriteInt8(int8(number))
+	case Int16:
+		if number < math.MinInt16 || number > math.MaxInt16 {
+			return fmt.Errorf("%w: %d overflows int16", ErrValue, number)
+		}
+
+		return writer.WriteInt16(int16(number))
+	case Int32:
+		if number < math.MinInt32 || number > math.MaxInt32 {
+			return fmt.Errorf("%w: %d overflows int32", ErrValue, number)
+		}
+
+		return writer.WriteInt32(int32(number))
+	case Int64:
+		return writer.WriteInt64(number)
+	default:
+		return writer.WriteVarint(number)
+	}
+}
+
+// encodeUnsigned puts unsigned integer value into writer checking it fits into type.
+func (t primitive) encodeUnsigned(writer *binutils.BinaryWriter, value interface{}) error {
+	number, err := toUint64(value)
+	if err != nil {
+		return err
+	}
+
+	switch t {
+	case Uint8:
+		if number > math.MaxUint8 {
+			return fmt.Errorf("%w: %d overflows uint8", ErrValue, number)
+		}
+
+		return writer.WriteUint8(uint8(number))
+	case Uint16:
+		if number > math.MaxUint16 {
+			return fmt.Errorf("%w: %d overflows uint16", ErrValue, number)
+		}
+
+		return writer.WriteUint16(uint16(number))
+	case Uint32:
+		if number > math.MaxUint32 {
+			return fmt.Errorf("%w: %d overflows uint32", ErrValue, number)
+		}
+
+		return writer.WriteUint32(uint32(number))
+	case Uint64:
+		return writer.WriteUint64(number)
+	default:
+		return writer.WriteUvarint(number)
+	}
+}
+
+// bytesType implements Bytes.
+type bytesType struct {
+	count Count // bytes count
+}
+
+// Bytes returns layout of raw bytes decoded into []byte. Encoded bytes length must match count.
+func Bytes(count Count) Type {
+	return bytesType{count: count}
+}
+
+// decode implements Type.
+// Bytes are read by chunks of maxPreallocated, so corrupted count fails on read, not on allocation.
+func (t bytesType) decode(reader *binutils.BinaryReader, scope *Scope) (interface{}, error) {
+	count, err := t.count.count(scope)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0, minCapacity(count))
+
+	for len(data) < count {
+		offset := len(data)
+		data = append(data, make([]byte, minCapacity(count-offset))...)
+
+		if err = reader.ReadBytesInto(data[offset:]); err != nil {
+			return nil, err
+		}
+	}
+
+	return data, nil
+}
+
+// encode implements Type.
+func (t bytesType) encode(writer *binutils.BinaryWriter, scope *Scope, value interface{}) error {
+	data, ok := value.([]byte)
+	if !ok {
+		return fmt.Errorf("%w: expected []byte, got %T", ErrValue, value)
+	}
+
+	count, err := t.count.count(scope)
+	if err != nil {
+		return err
+	}
+
+	if count != len(data) {
+		return fmt.Errorf("%w: expected %d bytes, got %d", ErrCount, count, len(data))
+	}
+
+	return writer.WriteBytes(data)
+}
+
+// orderType implements Order.
+type orderType struct {
+	order  binary.ByteOrder // byte order used for wrapped layout
+	layout Type             // wrapped layout
+}
+
+// Order returns layout processing wrapped layout using specified byte order.
+// Reader or writer byte order is restored after wrapped layout processed.
+func Order(order binary.ByteOrder, layout Type) Type {
+	return orderType{order: order, layout: layout}
+}
+
+// decode implements Type.
+func (t orderType) decode(reader *binutils.BinaryReader, scope *Scope) (interface{}, error) {
+	previous := reader.ByteOrder()
+	reader.SetByteOrder(t.order)
+	defer reader.SetByteOrder(previous)
+
+	return t.layout.decode(reader, scope)
+}
+
+// encode implements Type.
+func (t orderType) encode(writer *binutils.BinaryWriter, scope *Scope, value interface{}) error {
+	previous := writer.ByteOrder()
+	writer.SetByteOrder(t.order)
+	defer writer.SetByteOrder(previous)
+
+	return t.layout.encode(writer, scope, value)
+}
+
+// toUint64 converts any Go integer value into uint64.
+func toUint64(value interface{}) (uint64, error) {
+	switch number := value.(type) {
+	case uint8:
+		return uint64(number), nil
+	case uint16:
+		return uint64(number), nil
+	case uint32:
+		return uint64(number), nil
+	case uint64:
+		return number, nil
+	case uint:
+		return uint64(number), nil
+	}
+
+	number, err := toInt64(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if number < 0 {
+		return 0, fmt.Errorf("%w: negative %d", ErrValue, number)
+	}
+
+	return uint64(number), nil
+}
+
+// toInt64 converts any Go integer value into int64.
+func toInt64(value interface{}) (int64, error) {
+	switch number := value.(type) {
+	case int8:
+		return int64(number), nil
+	case int16:
+		return int64(number), nil
+	case int32:
+		return int64(number), nil
+	case int64:
+		return number, nil
+	case int:
+		return int64(number), nil
+	case uint8:
+		return int64(number), nil
+	case uint16:
+		return int64(number), nil
+	case uint32:
+		return int64(number), nil
+	case uint64:
+		if number > math.MaxInt64 {
+			return 0, fmt.Errorf("%w: %d overflows int64", ErrValue, number)
+		}
+
+		return int64(number), nil
+	case uint:
+		if uint64(number) > math.MaxInt64 {
+			return 0, fmt.Errorf("%w: %d overflows int64", ErrValue, number)
+		}
+
+		return int64(number), nil
+	default:
+		return 0, fmt.Errorf("%w: expected integer, got %T", ErrValue, value)
+	}
+}
+
+// toFloat64 converts any Go numeric value into float64.
+func toFloat64(value interface{}) (float64, error) {
+	switch number := value.(type) {
+	case float32:
+		return float64(number), nil
+	case float64:
+		return number, nil
+	}
+
+	if number, err := toInt64(value); err == nil {
+		return float64(number), nil
+	}
+
+	number, err := toUint64(value)
+	if err != nil {
+		return 0, fmt.Errorf("%w: expected number, got %T", ErrValue, value)
+	}
+
+	return float64(number), nil
+}
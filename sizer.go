@@ -0,0 +1,243 @@
+package binutils
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"unicode/utf8"
+)
+
+// BinarySizer interface wraps the BinarySize method.
+// Implementation method BinarySize returns exact bytes count written by implementors encoding.
+// SizeWriter and Size use it instead of dry-run encoding, BinaryWriter, BufferWriter and FrameWriter
+// to preallocate buffers.
+type BinarySizer interface {
+	BinarySize() int
+}
+
+// preallocSize returns buffer size to preallocate for data encoding.
+// Size reported by BinarySizer is limited to DefaultMaxFrameSize, so wrong implementation could not
+// exhaust memory or make buffer growth panic. Returns 0 if data is not BinarySizer or reports non-positive size.
+func preallocSize(data interface{}) int {
+	sizer, ok := data.(BinarySizer)
+	if !ok {
+		return 0
+	}
+
+	switch size := sizer.BinarySize(); {
+	case size <= 0:
+		return 0
+	case size > DefaultMaxFrameSize:
+		return DefaultMaxFrameSize
+	default:
+		return size
+	}
+}
+
+// SizeWriter implements Writer counting bytes typed writes would produce without storing them.
+// Use it to compute length prefixes or exact buffer sizes, see Size.
+type SizeWriter struct {
+	bytesWritten int              // counted bytes
+	encoding     TextEncoding     // strings encoding, nil means strings bytes are counted as is
+	order        binary.ByteOrder // multi-byte values bytes order, reported to encoders only
+}
+
+// NewSizeWriter creates SizeWriter using big endian byte order and no text encoding.
+func NewSizeWriter() *SizeWriter {
+	return &SizeWriter{order: binary.BigEndian}
+}
+
+// Size returns encoded length of data as written by BinaryWriter.WriteObject.
+// Uses BinarySize if data implements BinarySizer, otherwise counts dry-run encoding by SizeWriter.
+func Size(data interface{}) (int, error) {
+	if sizer, ok := data.(BinarySizer); ok {
+		return sizer.BinarySize(), nil
+	}
+
+	writer := NewSizeWriter()
+	if err := writer.WriteObject(data); err != nil {
+		return 0, err
+	}
+
+	return writer.BytesWritten(), nil
+}
+
+// BytesWritten returns counted bytes.
+func (w *SizeWriter) BytesWritten() int {
+	return w.bytesWritten
+}
+
+// ResetBytesWritten sets counted bytes to zero.
+func (w *SizeWriter) ResetBytesWritten() {
+	w.bytesWritten = 0
+}
+
+// SetByteOrder sets bytes order reported to encoders, it does not affect sizes.
+func (w *SizeWriter) SetByteOrder(order binary.ByteOrder) {
+	w.order = order
+}
+
+// ByteOrder returns bytes order reported to encoders.
+func (w *SizeWriter) ByteOrder() binary.ByteOrder {
+	return w.order
+}
+
+// SetEncoding sets text encoding used to count WriteStringZ sizes, nil means strings bytes are counted as is.
+func (w *SizeWriter) SetEncoding(encoding TextEncoding) {
+	w.encoding = encoding
+}
+
+// Encoding returns text encoding used to count WriteStringZ sizes.
+func (w *SizeWriter) Encoding() TextEncoding {
+	return w.encoding
+}
+
+// Close does nothing, there is no underlying resources to release.
+func (w *SizeWriter) Close() error {
+	return nil
+}
+
+// count adds size to counted bytes.
+func (w *SizeWriter) count(size int) error {
+	w.bytesWritten += size
+
+	return nil
+}
+
+// Write counts len(p) bytes. Implements io.Writer, never returns error.
+func (w *SizeWriter) Write(p []byte) (int, error) {
+	return len(p), w.count(len(p))
+}
+
+// WriteUint8 counts uint8 value size.
+func (w *SizeWriter) WriteUint8(uint8) error { return w.count(Uint8size) }
+
+// WriteUint16 counts uint16 value size.
+func (w *SizeWriter) WriteUint16(uint16) error { return w.count(Uint16size) }
+
+// WriteUint32 counts uint32 value size.
+func (w *SizeWriter) WriteUint32(uint32) error { return w.count(Uint32size) }
+
+// WriteUint64 counts uint64 value size.
+func (w *SizeWriter) WriteUint64(uint64) error { return w.count(Uint64size) }
+
+// WriteUint counts uint value size, written as uint64.
+func (w *SizeWriter) WriteUint(uint) error { return w.count(Uint64size) }
+
+// WriteInt8 counts int8 value size.
+func (w *SizeWriter) WriteInt8(int8) error { return w.count(Int8size) }
+
+// WriteInt16 counts int16 value size.
+func (w *SizeWriter) WriteInt16(int16) error { return w.count(Int16size) }
+
+// WriteInt32 counts int32 value size.
+func (w *SizeWriter) WriteInt32(int32) error { return w.count(Int32size) }
+
+// WriteInt64 counts int64 value size.
+func (w *SizeWriter) WriteInt64(int64) error { return w.count(Int64size) }
+
+// WriteInt counts int value size, written as int64.
+func (w *SizeWriter) WriteInt(int) error { return w.count(Int64size) }
+
+// WriteRune counts rune value size, written as uint32.
+//
+// Deprecated: use WriteUTF32Rune or WriteUTF8Rune to state rune encoding explicitly.
+func (w *SizeWriter) WriteRune(rune) error { return w.count(RuneSize) }
+
+// WriteUTF32Rune counts 4 bytes UTF-32 code unit size.
+func (w *SizeWriter) WriteUTF32Rune(rune) error { return w.count(RuneSize) }
+
+// WriteUTF8Rune counts UTF-8 encoded character size. Invalid runes are counted as utf8.RuneError.
+func (w *SizeWriter) WriteUTF8Rune(char rune) error {
+	size := utf8.RuneLen(char)
+	if size < 0 {
+		size = utf8.RuneLen(utf8.RuneError)
+	}
+
+	return w.count(size)
+}
+
+// WriteUvarint counts unsigned base 128 varint encoded value size.
+func (w *SizeWriter) WriteUvarint(data uint64) error {
+	size := 1
+	for ; data >= 0x80; data >>= 7 {
+		size++
+	}
+
+	return w.count(size)
+}
+
+// WriteVarint counts signed zig-zag base 128 varint encoded value size.
+func (w *SizeWriter) WriteVarint(data int64) error {
+	unsigned := uint64(data) << 1
+	if data < 0 {
+		unsigned = ^unsigned
+	}
+
+	return w.WriteUvarint(unsigned)
+}
+
+// WriteStringZ counts Zero-terminated string size.
+// If text encoding set using SetEncoding string is encoded to count its size, returning encoding errors.
+func (w *SizeWriter) WriteStringZ(data string) error {
+	if w.encoding == nil {
+		return w.count(len(data) + 1)
+	}
+
+	encoded, err := w.encoding.Encode(data)
+	if err != nil {
+		return fmt.Errorf("%v: %w", ErrWriterWrite, err)
+	}
+
+	return w.count(len(encoded) + w.encoding.UnitSize())
+}
+
+// WriteBytes counts byte string size.
+func (w *SizeWriter) WriteBytes(data []byte) error { return w.count(len(data)) }
+
+// WriteHex counts size of byte string defined by hex string.
+func (w *SizeWriter) WriteHex(hexString string) error {
+	if _, err := hex.DecodeString(hexString); err != nil {
+		return fmt.Errorf("%v: %v: hex: %w", ErrWriter, ErrDecodeTo, err)
+	}
+
+	return w.count(hex.DecodedLen(len(hexString)))
+}
+
+// WriteObject counts object size as written by BinaryWriter.WriteObject.
+// BinarySizer values are counted by BinarySize, BinaryEncoder values are encoded into w,
+// others are encoded by BinaryWriter adapter writing into w.
+func (w *SizeWriter) WriteObject(data interface{}) error {
+	switch typedValue := data.(type) {
+	case BinarySizer:
+		return w.count(typedValue.BinarySize())
+	case BinaryEncoder:
+		return typedValue.EncodeBinary(w)
+	default:
+		return AsBinaryWriter(w).WriteObject(data)
+	}
+}
+
+// WriteUint16s counts uint16 values slice size.
+func (w *SizeWriter) WriteUint16s(data []uint16) error { return w.count(len(data) * Uint16size) }
+
+// WriteInt16s counts int16 values slice size.
+func (w *SizeWriter) WriteInt16s(data []int16) error { return w.count(len(data) * Int16size) }
+
+// WriteUint32s counts uint32 values slice size.
+func (w *SizeWriter) WriteUint32s(data []uint32) error { return w.count(len(data) * Uint32size) }
+
+// WriteInt32s counts int32 values slice size.
+func (w *SizeWriter) WriteInt32s(data []int32) error { return w.count(len(data) * Int32size) }
+
+// WriteUint64s counts uint64 values slice size.
+func (w *SizeWriter) WriteUint64s(data []uint64) error { return w.count(len(data) * Uint64size) }
+
+// WriteInt64s counts int64 values slice size.
+func (w *SizeWriter) WriteInt64s(data []int64) error { return w.count(len(data) * Int64size) }
+
+// WriteFloat32s counts float32 values slice size.
+func (w *SizeWriter) WriteFloat32s(data []float32) error { return w.count(len(data) * Uint32size) }
+
+// WriteFloat64s counts float64 values slice size.
+func (w *SizeWriter) WriteFloat64s(data []float64) error { return w.count(len(data) * Uint64size) }
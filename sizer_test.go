@@ -0,0 +1,170 @@
+package binutils_test
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/amarin/binutils"
+)
+
+// sizedBlob reports its size without encoding.
+type sizedBlob struct {
+	data    []byte
+	encoded *int // counts EncodeBinary calls
+}
+
+func (b sizedBlob) BinarySize() int { return Uint16size + len(b.data) }
+
+func (b sizedBlob) EncodeBinary(writer Writer) error {
+	*b.encoded++
+
+	if err := writer.WriteUint16(uint16(len(b.data))); err != nil {
+		return err
+	}
+
+	return writer.WriteBytes(b.data)
+}
+
+func TestSize(t *testing.T) {
+	value := uint32(1)
+	encoded := 0
+
+	for _, tt := range []struct {
+		name     string
+		data     interface{}
+		expected int
+	}{
+		{name: "uint8", data: uint8(1), expected: 1},
+		{name: "int16", data: int16(1), expected: 2},
+		{name: "*uint32", data: &value, expected: 4},
+		{name: "int", data: 1, expected: 8},
+		{name: "string", data: "строка", expected: 13},
+		{name: "bytes", data: []byte{1, 2, 3}, expected: 3},
+		{name: "BinaryEncoder", data: point{Name: "abc"}, expected: 12},
+		{name: "BinaryWriterTo", data: legacyPoint{}, expected: 4},
+		{name: "io.WriterTo", data: bytes.NewBufferString("raw"), expected: 3},
+		{name: "BinarySizer", data: sizedBlob{data: make([]byte, 10), encoded: &encoded}, expected: 12},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			size, err := Size(tt.data)
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, size)
+
+			writer := NewBinaryWriterBuffer(nil)
+			require.NoError(t, writer.WriteObject(tt.data))
+
+			if _, ok := tt.data.(*bytes.Buffer); !ok { // buffer is drained by the first write
+				require.Equal(t, tt.expected, writer.Len())
+			}
+		})
+	}
+
+	require.Equal(t, 1, encoded, "BinarySizer must not be encoded to get size")
+
+	_, err := Size(struct{}{})
+	require.True(t, errors.Is(err, ErrWriterWrite))
+}
+
+func TestSizeWriter_TypedWrites(t *testing.T) {
+	for _, encoding := range []TextEncoding{nil, UTF16LE, Windows1251} {
+		writer := NewSizeWriter()
+		writer.SetEncoding(encoding)
+
+		require.NoError(t, writer.WriteUint8(0xfe))
+		require.NoError(t, writer.WriteInt16(math.MinInt16))
+		require.NoError(t, writer.WriteUint32(0xdeadbeef))
+		require.NoError(t, writer.WriteInt64(-2))
+		require.NoError(t, writer.WriteUvarint(300))
+		require.NoError(t, writer.WriteVarint(-300))
+		require.NoError(t, writer.WriteUTF8Rune('ж'))
+		require.NoError(t, writer.WriteRune('€'))
+		require.NoError(t, writer.WriteStringZ("строка"))
+		require.NoError(t, writer.WriteFloat64s([]float64{1.5, -2}))
+		require.NoError(t, writer.WriteHex("cafe"))
+
+		require.Equalf(t, len(writeSampleRecord(t, writer.ByteOrder(), encoding)), writer.BytesWritten(), "%v", encoding)
+	}
+
+	writer := NewSizeWriter()
+	for _, value := range []uint64{0, 0x7f, 0x80, math.MaxUint32, math.MaxUint64} {
+		writer.ResetBytesWritten()
+		require.NoError(t, writer.WriteUvarint(value))
+
+		buffer := NewBinaryWriterBuffer(nil)
+		require.NoError(t, buffer.WriteUvarint(value))
+		require.Equal(t, buffer.Len(), writer.BytesWritten())
+	}
+
+	for _, value := range []int64{0, -1, 63, -64, 64, math.MinInt64, math.MaxInt64} {
+		writer.ResetBytesWritten()
+		require.NoError(t, writer.WriteVarint(value))
+
+		buffer := NewBinaryWriterBuffer(nil)
+		require.NoError(t, buffer.WriteVarint(value))
+		require.Equal(t, buffer.Len(), writer.BytesWritten())
+	}
+
+	require.Error(t, writer.WriteHex("xyz"))
+	writer.SetEncoding(Windows1251)
+	require.True(t, errors.Is(writer.WriteStringZ("漢"), ErrUnmappable))
+}
+
+func TestBufferWriter_WriteObjectBinarySizer(t *testing.T) {
+	encoded := 0
+	writer := NewBinaryWriterBuffer(nil)
+
+	require.NoError(t, writer.WriteObject(sizedBlob{data: make([]byte, 100), encoded: &encoded}))
+	require.Equal(t, 102, writer.Len())
+	require.Zero(t, writer.Remaining(), "buffer must be allocated exactly")
+}
+
+func TestBinaryWriter_WriteObjectBinarySizer(t *testing.T) {
+	encoded := 0
+	buffer := NewBinaryWriterBuffer(nil)
+
+	require.NoError(t, NewBinaryWriter(buffer).WriteObject(sizedBlob{data: make([]byte, 100), encoded: &encoded}))
+	require.Equal(t, 102, buffer.Len())
+	require.Zero(t, buffer.Remaining(), "buffer must be allocated exactly")
+}
+
+// wrongSizer reports configured size regardless of single byte it writes.
+type wrongSizer int
+
+func (s wrongSizer) BinarySize() int { return int(s) }
+
+func (s wrongSizer) BinaryWriteTo(writer *BinaryWriter) error { return writer.WriteUint8(1) }
+
+func TestWriteObject_WrongBinarySizer(t *testing.T) {
+	for _, size := range []wrongSizer{-1, math.MinInt32, math.MaxInt32} {
+		buffer := new(bytes.Buffer)
+		require.NoError(t, NewBinaryWriter(buffer).WriteObject(size))
+		require.Equal(t, []byte{1}, buffer.Bytes())
+		require.LessOrEqual(t, buffer.Cap(), 2*DefaultMaxFrameSize, "preallocation must be limited")
+
+		bufferWriter := NewBinaryWriterBuffer(nil)
+		require.NoError(t, bufferWriter.WriteObject(size))
+		require.Equal(t, []byte{1}, bufferWriter.Bytes())
+
+		buffer.Reset()
+		require.NoError(t, NewFrameWriter(NewBinaryWriter(buffer), FrameHeaderUint32, math.MaxInt32).WriteObject(size))
+		require.Equal(t, []byte{0, 0, 0, 1, 1}, buffer.Bytes())
+	}
+}
+
+func TestFrameWriter_WriteObjectBinarySizer(t *testing.T) {
+	encoded := 0
+	buffer := new(bytes.Buffer)
+	frames := NewFrameWriter(NewBinaryWriter(buffer), FrameHeaderUint16, 8)
+
+	err := frames.WriteObject(sizedBlob{data: make([]byte, 7), encoded: &encoded})
+	require.True(t, errors.Is(err, ErrFrameSize))
+	require.Zero(t, encoded, "oversized frame must be rejected before encoding")
+
+	require.NoError(t, frames.WriteObject(sizedBlob{data: []byte{9}, encoded: &encoded}))
+	require.Equal(t, []byte{0, 3, 0, 1, 9}, buffer.Bytes())
+}
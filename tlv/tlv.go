@@ -0,0 +1,324 @@
+// Package tlv implements Tag-Length-Value items encoding on top of binutils.BinaryReader and binutils.BinaryWriter.
+//
+// Tag and length fields widths are configurable using Format.
+// Multi-byte fixed width fields use byte order configured for underlying reader or writer.
+package tlv
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/amarin/binutils"
+)
+
+// Width defines tag or length field encoding.
+type Width int
+
+const (
+	// WidthVarint encodes field as unsigned base 128 varint.
+	WidthVarint Width = 0
+	// Width1 encodes field as single byte.
+	Width1 Width = 1
+	// Width2 encodes field as 2 bytes.
+	Width2 Width = 2
+	// Width4 encodes field as 4 bytes.
+	Width4 Width = 4
+)
+
+// Format defines TLV item layout.
+type Format struct {
+	TagWidth    Width // tag field encoding
+	LengthWidth Width // length field encoding
+	MaxLength   int   // maximum item length accepted by Reader, zero or negative means binutils.DefaultMaxFrameSize
+}
+
+// maxLength returns maximum item length accepted by Reader.
+func (f Format) maxLength() uint64 {
+	if f.MaxLength <= 0 {
+		return binutils.DefaultMaxFrameSize
+	}
+
+	return uint64(f.MaxLength)
+}
+
+// Some predefined errors used during processing.
+var (
+	// Error indicates any TLV errors.
+	Error = fmt.Errorf("%w: tlv", binutils.Error)
+
+	// ErrWidth returned if unsupported field width specified.
+	ErrWidth = fmt.Errorf("%w: unsupported width", Error)
+
+	// ErrOverflow returned if tag or length value does not fit into field width.
+	ErrOverflow = fmt.Errorf("%w: value overflows field width", Error)
+
+	// ErrLength returned if item length exceeds Format.MaxLength.
+	ErrLength = fmt.Errorf("%w: item length exceeds maximum", Error)
+
+	// ErrUnknownTag returned if no factory registered for item tag.
+	ErrUnknownTag = fmt.Errorf("%w: unknown tag", Error)
+)
+
+// maxValue returns maximum value could be stored using width.
+func (w Width) maxValue() (uint64, error) {
+	switch w {
+	case WidthVarint:
+		return math.MaxUint64, nil
+	case Width1:
+		return math.MaxUint8, nil
+	case Width2:
+		return math.MaxUint16, nil
+	case Width4:
+		return math.MaxUint32, nil
+	default:
+		return 0, fmt.Errorf("%w: %d", ErrWidth, w)
+	}
+}
+
+// write writes value into writer using field width.
+func (w Width) write(writer *binutils.BinaryWriter, value uint64) error {
+	maxValue, err := w.maxValue()
+
+	switch {
+	case err != nil:
+		return err
+	case value > maxValue:
+		return fmt.Errorf("%w: %d > %d", ErrOverflow, value, maxValue)
+	}
+
+	switch w {
+	case Width1:
+		return writer.WriteUint8(uint8(value))
+	case Width2:
+		return writer.WriteUint16(uint16(value))
+	case Width4:
+		return writer.WriteUint32(uint32(value))
+	default:
+		return writer.WriteUvarint(value)
+	}
+}
+
+// read reads value from reader using field width.
+func (w Width) read(reader *binutils.BinaryReader) (uint64, error) {
+	switch w {
+	case Width1:
+		value, err := reader.ReadUint8()
+		return uint64(value), err
+	case Width2:
+		value, err := reader.ReadUint16()
+		return uint64(value), err
+	case Width4:
+		value, err := reader.ReadUint32()
+		return uint64(value), err
+	case WidthVarint:
+		return reader.ReadUvarint()
+	default:
+		return 0, fmt.Errorf("%w: %d", ErrWidth, w)
+	}
+}
+
+// Writer writes TLV items into binutils.BinaryWriter.
+type Writer struct {
+	writer *binutils.BinaryWriter
+	format Format
+}
+
+// NewWriter creates Writer writing items of specified format into writer.
+func NewWriter(writer *binutils.BinaryWriter, format Format) *Writer {
+	return &Writer{writer: writer, format: format}
+}
+
+// WriteItem writes single item having specified tag and value.
+func (w *Writer) WriteItem(tag uint64, value []byte) error {
+	if err := w.format.TagWidth.write(w.writer, tag); err != nil {
+		return fmt.Errorf("tag: %w", err)
+	}
+
+	if err := w.format.LengthWidth.write(w.writer, uint64(len(value))); err != nil {
+		return fmt.Errorf("length: %w", err)
+	}
+
+	return w.writer.WriteBytes(value)
+}
+
+// WriteObject encodes value using binutils.BinaryWriter.WriteObject and writes it as single item.
+func (w *Writer) WriteObject(tag uint64, value interface{}) error {
+	buffer := new(bytes.Buffer)
+	if err := w.bufferWriter(buffer).WriteObject(value); err != nil {
+		return err
+	}
+
+	return w.WriteItem(tag, buffer.Bytes())
+}
+
+// WriteContainer writes item containing nested items written by fill.
+func (w *Writer) WriteContainer(tag uint64, fill func(nested *Writer) error) error {
+	buffer := new(bytes.Buffer)
+	if err := fill(NewWriter(w.bufferWriter(buffer), w.format)); err != nil {
+		return err
+	}
+
+	return w.WriteItem(tag, buffer.Bytes())
+}
+
+// bufferWriter creates BinaryWriter into buffer using underlying writer byte order and text encoding.
+func (w *Writer) bufferWriter(buffer *bytes.Buffer) *binutils.BinaryWriter {
+	writer := binutils.NewBinaryWriter(buffer)
+	writer.SetByteOrder(w.writer.ByteOrder())
+	writer.SetEncoding(w.writer.Encoding())
+
+	return writer
+}
+
+// Item represents single TLV item taken by Reader.
+type Item struct {
+	Tag    uint64                 // item tag
+	Length int                    // item value length
+	Value  *binutils.BinaryReader // item value reader limited to item length
+	format Format
+}
+
+// Bytes reads item value bytes not taken yet.
+func (i *Item) Bytes() ([]byte, error) {
+	return i.Value.ReadBytesCount(i.Value.Remaining())
+}
+
+// Items returns Reader over nested items if item is a container.
+func (i *Item) Items() *Reader {
+	return NewReader(i.Value, i.format)
+}
+
+// Reader iterates over TLV items taken from binutils.BinaryReader.
+type Reader struct {
+	reader  *binutils.BinaryReader
+	format  Format
+	current *Item
+}
+
+// NewReader creates Reader taking items of specified format from reader.
+func NewReader(reader *binutils.BinaryReader, format Format) *Reader {
+	return &Reader{reader: reader, format: format}
+}
+
+// Next skips unread value bytes of previous item if any and reads next item header.
+// Returns io.EOF if no more items available or ErrLength if item length exceeds Format.MaxLength.
+func (r *Reader) Next() (*Item, error) {
+	if r.current != nil {
+		if _, err := r.current.Value.Drain(); err != nil {
+			return nil, err
+		}
+
+		r.current = nil
+	}
+
+	tag, err := r.format.TagWidth.read(r.reader)
+	if err != nil {
+		return nil, err // keep io.EOF as is to detect clean end of items
+	}
+
+	length, err := r.format.LengthWidth.read(r.reader)
+
+	switch {
+	case err == io.EOF:
+		return nil, fmt.Errorf("%w: length: %v", Error, io.ErrUnexpectedEOF)
+	case err != nil:
+		return nil, fmt.Errorf("%w: length: %v", Error, err)
+	case length > math.MaxInt32:
+		return nil, fmt.Errorf("%w: length %d", ErrOverflow, length)
+	case length > r.format.maxLength():
+		return nil, fmt.Errorf("%w: %d > %d", ErrLength, length, r.format.maxLength())
+	}
+
+	r.current = &Item{Tag: tag, Length: int(length), Value: r.reader.Sub(int(length)), format: r.format}
+
+	return r.current, nil
+}
+
+// Factory creates new empty value to decode item into.
+type Factory func() binutils.BinaryReaderFrom
+
+// TagError describes error of particular item value decoding.
+// Both Error and underlying error are matched by errors.Is.
+type TagError struct {
+	Tag uint64 // item tag
+	Err error  // underlying error
+}
+
+// Error returns error text annotated by item tag.
+func (e *TagError) Error() string {
+	return fmt.Sprintf("%v: tag %d: %v", Error, e.Tag, e.Err)
+}
+
+// Unwrap returns underlying error.
+func (e *TagError) Unwrap() error {
+	return e.Err
+}
+
+// Is returns true if target is Error.
+func (e *TagError) Is(target error) bool {
+	return target == Error
+}
+
+// Registry maps item tags to value factories.
+type Registry struct {
+	factories map[uint64]Factory
+}
+
+// NewRegistry creates empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{factories: make(map[uint64]Factory)}
+}
+
+// Register sets factory used to create values for items having specified tag.
+func (r *Registry) Register(tag uint64, factory Factory) {
+	r.factories[tag] = factory
+}
+
+// Decode creates value for item tag and decodes item value into it.
+// Item value bytes left unread by decoder are skipped, or ErrUnconsumed returned in strict finish mode.
+// Returns ErrUnknownTag if no factory registered for item tag, decoding errors are wrapped into TagError.
+func (r *Registry) Decode(item *Item) (binutils.BinaryReaderFrom, error) {
+	factory, ok := r.factories[item.Tag]
+	if !ok {
+		return nil, fmt.Errorf("%w: %d", ErrUnknownTag, item.Tag)
+	}
+
+	value := factory()
+	if err := value.BinaryReadFrom(item.Value); err != nil {
+		return nil, &TagError{Tag: item.Tag, Err: err}
+	}
+
+	if err := item.Value.Finish(); err != nil {
+		return nil, &TagError{Tag: item.Tag, Err: err}
+	}
+
+	return value, nil
+}
+
+// DecodeAll decodes all items taken from reader until io.EOF.
+// Items having unregistered tags are skipped for forward compatibility.
+func (r *Registry) DecodeAll(reader *Reader) (values []binutils.BinaryReaderFrom, err error) {
+	for {
+		item, err := reader.Next()
+
+		switch {
+		case err == io.EOF:
+			return values, nil
+		case err != nil:
+			return values, err
+		}
+
+		if _, ok := r.factories[item.Tag]; !ok {
+			continue // skipped by next Next call
+		}
+
+		value, err := r.Decode(item)
+		if err != nil {
+			return values, err
+		}
+
+		values = append(values, value)
+	}
+}
@@ -0,0 +1,169 @@
+package tlv_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/amarin/binutils"
+	"github.com/amarin/binutils/tlv"
+)
+
+// point is a simple test value.
+type point struct {
+	X, Y uint16
+}
+
+func (p *point) BinaryReadFrom(r *binutils.BinaryReader) (err error) {
+	if p.X, err = r.ReadUint16(); err != nil {
+		return err
+	}
+
+	p.Y, err = r.ReadUint16()
+
+	return err
+}
+
+func (p *point) BinaryWriteTo(w *binutils.BinaryWriter) error {
+	if err := w.WriteUint16(p.X); err != nil {
+		return err
+	}
+
+	return w.WriteUint16(p.Y)
+}
+
+func TestWriter_WriteItem(t *testing.T) {
+	for _, tt := range []struct {
+		name   string
+		format tlv.Format
+		hex    string
+	}{
+		{"1_1", tlv.Format{TagWidth: tlv.Width1, LengthWidth: tlv.Width1}, "05020102"},
+		{"2_4", tlv.Format{TagWidth: tlv.Width2, LengthWidth: tlv.Width4}, "0005000000020102"},
+		{"4_2", tlv.Format{TagWidth: tlv.Width4, LengthWidth: tlv.Width2}, "0000000500020102"},
+		{"varint", tlv.Format{TagWidth: tlv.WidthVarint, LengthWidth: tlv.WidthVarint}, "05020102"},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			buffer := new(bytes.Buffer)
+			require.NoError(t, tlv.NewWriter(binutils.NewBinaryWriter(buffer), tt.format).WriteItem(5, []byte{1, 2}))
+			require.Equal(t, tt.hex, hex.EncodeToString(buffer.Bytes()))
+
+			reader := tlv.NewReader(binutils.NewBinaryReader(buffer), tt.format)
+			item, err := reader.Next()
+			require.NoError(t, err)
+			require.Equal(t, uint64(5), item.Tag)
+			require.Equal(t, 2, item.Length)
+			value, err := item.Bytes()
+			require.NoError(t, err)
+			require.Equal(t, []byte{1, 2}, value)
+			_, err = reader.Next()
+			require.Equal(t, io.EOF, err)
+		})
+	}
+}
+
+func TestWriter_Overflow(t *testing.T) {
+	writer := tlv.NewWriter(binutils.NewBinaryWriter(new(bytes.Buffer)), tlv.Format{TagWidth: tlv.Width1})
+	require.True(t, errors.Is(writer.WriteItem(256, nil), tlv.ErrOverflow))
+
+	writer = tlv.NewWriter(binutils.NewBinaryWriter(new(bytes.Buffer)), tlv.Format{TagWidth: 3})
+	require.True(t, errors.Is(writer.WriteItem(1, nil), tlv.ErrWidth))
+}
+
+func TestReader_MaxLength(t *testing.T) {
+	format := tlv.Format{TagWidth: tlv.Width1, LengthWidth: tlv.Width4}
+	reader := tlv.NewReader(binutils.NewBinaryReader(bytes.NewReader([]byte{5, 0x7f, 0xff, 0xff, 0xff})), format)
+	_, err := reader.Next()
+	require.True(t, errors.Is(err, tlv.ErrLength), "default maximum must be checked, got %v", err)
+
+	format.MaxLength = 1
+	reader = tlv.NewReader(binutils.NewBinaryReader(bytes.NewReader([]byte{5, 0, 0, 0, 2, 1, 2})), format)
+	_, err = reader.Next()
+	require.True(t, errors.Is(err, tlv.ErrLength), "configured maximum must be checked, got %v", err)
+}
+
+func TestRegistry_DecodeAll(t *testing.T) {
+	format := tlv.Format{TagWidth: tlv.Width1, LengthWidth: tlv.WidthVarint}
+	buffer := new(bytes.Buffer)
+	writer := tlv.NewWriter(binutils.NewBinaryWriter(buffer), format)
+	require.NoError(t, writer.WriteObject(1, &point{X: 1, Y: 2}))
+	require.NoError(t, writer.WriteItem(99, []byte("unknown tag from newer version")))
+	require.NoError(t, writer.WriteItem(1, []byte{0, 3, 0, 4, 0xff})) // trailing byte is skipped
+	require.NoError(t, writer.WriteObject(2, "text"))
+
+	registry := tlv.NewRegistry()
+	registry.Register(1, func() binutils.BinaryReaderFrom { return new(point) })
+
+	values, err := registry.DecodeAll(tlv.NewReader(binutils.NewBinaryReader(buffer), format))
+	require.NoError(t, err)
+	require.Equal(t, []binutils.BinaryReaderFrom{&point{X: 1, Y: 2}, &point{X: 3, Y: 4}}, values)
+}
+
+func TestRegistry_Decode(t *testing.T) {
+	format := tlv.Format{TagWidth: tlv.Width1, LengthWidth: tlv.Width1}
+	source := binutils.NewBinaryReader(bytes.NewBuffer([]byte{7, 0, 1, 3, 0, 1, 0, 1, 5, 0, 1, 0, 2, 9}))
+	source.SetStrictFinish(true)
+	reader := tlv.NewReader(source, format)
+	registry := tlv.NewRegistry()
+	registry.Register(1, func() binutils.BinaryReaderFrom { return new(point) })
+
+	item, err := reader.Next()
+	require.NoError(t, err)
+	_, err = registry.Decode(item)
+	require.True(t, errors.Is(err, tlv.ErrUnknownTag))
+
+	item, err = reader.Next()
+	require.NoError(t, err)
+	_, err = registry.Decode(item) // value is shorter than point
+	require.True(t, errors.Is(err, tlv.Error))
+	require.True(t, errors.Is(err, io.ErrUnexpectedEOF), "unexpected error %v", err)
+
+	var tagError *tlv.TagError
+	require.True(t, errors.As(err, &tagError))
+	require.Equal(t, uint64(1), tagError.Tag)
+
+	item, err = reader.Next()
+	require.NoError(t, err)
+	_, err = registry.Decode(item) // value is longer than point
+	require.True(t, errors.Is(err, tlv.Error))
+	require.True(t, errors.Is(err, binutils.ErrUnconsumed), "unexpected error %v", err)
+}
+
+func TestWriter_WriteContainer(t *testing.T) {
+	format := tlv.Format{TagWidth: tlv.Width1, LengthWidth: tlv.Width1}
+	buffer := new(bytes.Buffer)
+	writer := tlv.NewWriter(binutils.NewBinaryWriter(buffer), format)
+	require.NoError(t, writer.WriteContainer(10, func(nested *tlv.Writer) error {
+		if err := nested.WriteItem(1, []byte{1}); err != nil {
+			return err
+		}
+
+		return nested.WriteItem(2, []byte{2, 2})
+	}))
+	require.NoError(t, writer.WriteItem(3, nil))
+	require.Equal(t, "0a07010101020202020300", hex.EncodeToString(buffer.Bytes()))
+
+	reader := tlv.NewReader(binutils.NewBinaryReader(buffer), format)
+	container, err := reader.Next()
+	require.NoError(t, err)
+	require.Equal(t, uint64(10), container.Tag)
+
+	nested := container.Items()
+	first, err := nested.Next()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), first.Tag)
+	second, err := nested.Next()
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), second.Tag)
+	_, err = nested.Next()
+	require.Equal(t, io.EOF, err)
+
+	last, err := reader.Next()
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), last.Tag)
+}
@@ -0,0 +1,271 @@
+package binutils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+)
+
+// traceBytesPerLine defines bytes count rendered per Tracer.Dump line.
+const traceBytesPerLine = 16
+
+// TraceEntry describes single typed operation recorded by Tracer.
+type TraceEntry struct {
+	Offset int         // operation start offset counted the same way as BytesTaken or BytesWritten
+	Method string      // traced method name, e.g. ReadUint32
+	Label  string      // field label set by Tracer.Label or empty
+	Data   []byte      // raw bytes taken or written by operation
+	Value  interface{} // decoded or written value, nil for raw bytes operations
+	Err    error       // operation error or nil
+}
+
+// String returns entry annotation as rendered by Tracer.Dump.
+func (e TraceEntry) String() string {
+	annotation := e.Method
+	if e.Label != "" {
+		annotation = e.Label + ": " + annotation
+	}
+
+	if e.Err != nil {
+		return annotation + " error: " + e.Err.Error()
+	}
+
+	switch value := e.Value.(type) {
+	case nil:
+	case string:
+		annotation += fmt.Sprintf(" = %q", value)
+	default:
+		annotation += fmt.Sprintf(" = %v", value)
+	}
+
+	return annotation
+}
+
+// Tracer records typed operations of BinaryReader or BinaryWriter it is attached to using SetTracer.
+// Nested operations, e.g. ReadUint16 called by ReadInt16 or by ReadStringZ, are recorded as single outer entry,
+// while ReadObject and BinaryReadFrom implementations are traced field by field.
+// Tracer expects sequential operations, do not share it between concurrently used readers or writers.
+type Tracer struct {
+	mu       sync.Mutex   // protects fields below
+	entries  []TraceEntry // recorded operations
+	label    string       // label of next recorded entry
+	depth    int          // nested operations depth
+	offset   int          // outer operation start offset
+	captured []byte       // bytes taken or written by outer operation
+}
+
+// NewTracer creates empty Tracer.
+func NewTracer() *Tracer {
+	return new(Tracer)
+}
+
+// Label sets label of next recorded operation, e.g. field name being decoded.
+// Label of nil Tracer does nothing, so decoders may label fields regardless tracing enabled.
+func (t *Tracer) Label(label string) {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	t.label = label
+	t.mu.Unlock()
+}
+
+// Entries returns recorded operations.
+func (t *Tracer) Entries() []TraceEntry {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return append([]TraceEntry(nil), t.entries...)
+}
+
+// Reset drops recorded operations and pending label.
+func (t *Tracer) Reset() {
+	t.mu.Lock()
+	t.entries, t.label = nil, ""
+	t.mu.Unlock()
+}
+
+// begin starts operation at specified offset.
+func (t *Tracer) begin(offset int) {
+	t.mu.Lock()
+	if t.depth == 0 {
+		t.offset, t.captured = offset, nil
+	}
+	t.depth++
+	t.mu.Unlock()
+}
+
+// capture appends bytes taken or written by current operation.
+func (t *Tracer) capture(data []byte) {
+	t.mu.Lock()
+	if t.depth > 0 {
+		t.captured = append(t.captured, data...)
+	}
+	t.mu.Unlock()
+}
+
+// uncapture drops count last captured bytes, e.g. returned back to reader.
+func (t *Tracer) uncapture(count int) {
+	t.mu.Lock()
+	if t.depth > 0 && count <= len(t.captured) {
+		t.captured = t.captured[:len(t.captured)-count]
+	}
+	t.mu.Unlock()
+}
+
+// end finishes operation recording entry if it is outer one.
+func (t *Tracer) end(method string, value interface{}, err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.depth--; t.depth > 0 {
+		return
+	}
+
+	t.entries = append(t.entries, TraceEntry{
+		Offset: t.offset, Method: method, Label: t.label, Data: t.captured, Value: value, Err: err,
+	})
+	t.label, t.captured = "", nil
+}
+
+// drop removes last recorded entry, e.g. rune returned back by UnreadRune.
+func (t *Tracer) drop() {
+	t.mu.Lock()
+	if t.depth == 0 && len(t.entries) > 0 {
+		t.entries = t.entries[:len(t.entries)-1]
+	}
+	t.mu.Unlock()
+}
+
+// Dump renders recorded operations into writer as xxd-like hexdump annotated by entries.
+// Each entry starts new line prefixed by its offset, long entries are wrapped by 16 bytes per line
+// and annotated on the first line only.
+func (t *Tracer) Dump(writer io.Writer) error {
+	buffered := bufio.NewWriter(writer)
+
+	for _, entry := range t.Entries() {
+		data := entry.Data
+		offset := entry.Offset
+
+		for first := true; first || len(data) > 0; first = false {
+			line := data
+			if len(line) > traceBytesPerLine {
+				line = line[:traceBytesPerLine]
+			}
+
+			annotation := ""
+			if first {
+				annotation = entry.String()
+			}
+
+			rendered := fmt.Sprintf("%08x: %-39s  %-16s  %s", offset, traceHex(line), traceText(line), annotation)
+			if _, err := buffered.WriteString(strings.TrimRight(rendered, " ") + "\n"); err != nil {
+				return err
+			}
+
+			data, offset = data[len(line):], offset+len(line)
+		}
+	}
+
+	return buffered.Flush()
+}
+
+// traceHex returns data as xxd-like groups of two bytes in hex.
+func traceHex(data []byte) string {
+	var builder strings.Builder
+
+	for idx, char := range data {
+		if idx > 0 && idx%2 == 0 {
+			builder.WriteByte(' ')
+		}
+
+		fmt.Fprintf(&builder, "%02x", char)
+	}
+
+	return builder.String()
+}
+
+// traceText returns data printable ASCII characters replacing others by dot.
+func traceText(data []byte) string {
+	text := []byte(string(data))
+
+	for idx, char := range text {
+		if char < 0x20 || char > 0x7e {
+			text[idx] = '.'
+		}
+	}
+
+	return string(text)
+}
+
+// SetTracer attaches tracer recording typed reads, nil disables tracing.
+// Readers created by Sub inherit tracer, their entries offsets are counted from r start.
+func (r *BinaryReader) SetTracer(tracer *Tracer) {
+	r.mu.Lock()
+	r.tracer = tracer
+	r.mu.Unlock()
+}
+
+// Tracer returns attached tracer or nil if tracing disabled.
+func (r *BinaryReader) Tracer() (tracer *Tracer) {
+	r.mu.Lock()
+	tracer = r.tracer
+	r.mu.Unlock()
+
+	return tracer
+}
+
+// traceBegin starts traced operation. Returns attached tracer or nil if tracing disabled.
+func (r *BinaryReader) traceBegin() *Tracer {
+	r.mu.Lock()
+	tracer := r.traceBeginLocked()
+	r.mu.Unlock()
+
+	return tracer
+}
+
+// traceBeginLocked starts traced operation. Requires r.mu held.
+func (r *BinaryReader) traceBeginLocked() *Tracer {
+	if r.tracer != nil {
+		r.tracer.begin(r.traceOffset + r.bytesTaken)
+	}
+
+	return r.tracer
+}
+
+// SetTracer attaches tracer recording typed writes, nil disables tracing.
+func (w *BinaryWriter) SetTracer(tracer *Tracer) {
+	w.mu.Lock()
+	w.tracer = tracer
+	w.mu.Unlock()
+}
+
+// Tracer returns attached tracer or nil if tracing disabled.
+func (w *BinaryWriter) Tracer() (tracer *Tracer) {
+	w.mu.Lock()
+	tracer = w.tracer
+	w.mu.Unlock()
+
+	return tracer
+}
+
+// traceBegin starts traced operation. Returns attached tracer or nil if tracing disabled.
+func (w *BinaryWriter) traceBegin() *Tracer {
+	w.mu.Lock()
+	tracer := w.traceBeginLocked()
+	w.mu.Unlock()
+
+	return tracer
+}
+
+// traceBeginLocked starts traced operation. Requires w.mu held.
+func (w *BinaryWriter) traceBeginLocked() *Tracer {
+	if w.tracer != nil {
+		w.tracer.begin(w.bytesWritten)
+	}
+
+	return w.tracer
+}
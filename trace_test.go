@@ -0,0 +1,126 @@
+package binutils_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/amarin/binutils"
+)
+
+// tracedHeader decodes labeled fields.
+type tracedHeader struct {
+	Version uint16
+	Name    string
+	Flags   int8
+}
+
+func (h *tracedHeader) BinaryReadFrom(reader *BinaryReader) (err error) {
+	tracer := reader.Tracer()
+
+	tracer.Label("version")
+	if h.Version, err = reader.ReadUint16(); err != nil {
+		return err
+	}
+
+	tracer.Label("name")
+	if h.Name, err = reader.ReadStringZ(); err != nil {
+		return err
+	}
+
+	tracer.Label("flags")
+	h.Flags, err = reader.ReadInt8()
+
+	return err
+}
+
+func TestBinaryReader_Tracer(t *testing.T) {
+	data := []byte("\x00\x02a rather long header name\x00\xff\x00\x00\x00\x2a")
+	reader := NewBinaryReader(bytes.NewReader(data))
+	tracer := NewTracer()
+	reader.SetTracer(tracer)
+	require.True(t, tracer == reader.Tracer())
+
+	header := new(tracedHeader)
+	require.NoError(t, reader.ReadObject(header))
+	require.Equal(t, tracedHeader{Version: 2, Name: "a rather long header name", Flags: -1}, *header)
+
+	sub := reader.Sub(4)
+	_, err := sub.ReadInt32()
+	require.NoError(t, err)
+
+	_, err = reader.ReadUint8()
+	require.Equal(t, io.EOF, err)
+
+	entries := tracer.Entries()
+	require.Len(t, entries, 5)
+	require.Equal(t, TraceEntry{Offset: 0, Method: "ReadUint16", Label: "version", Data: []byte{0, 2}, Value: uint16(2)}, entries[0])
+	require.Equal(t, "ReadStringZ", entries[1].Method, "nested ReadBytes must be recorded as outer ReadStringZ")
+	require.Equal(t, TraceEntry{Offset: 28, Method: "ReadInt8", Label: "flags", Data: []byte{0xff}, Value: int8(-1)}, entries[2])
+	require.Equal(t, 29, entries[3].Offset, "sub reader entries must be offset by its start")
+	require.Equal(t, io.EOF, entries[4].Err)
+
+	output := new(strings.Builder)
+	require.NoError(t, tracer.Dump(output))
+	require.Equal(t, strings.Join([]string{
+		`00000000: 0002                                     ..                version: ReadUint16 = 2`,
+		`00000002: 6120 7261 7468 6572 206c 6f6e 6720 6865  a rather long he  name: ReadStringZ = "a rather long header name"`,
+		`00000012: 6164 6572 206e 616d 6500                 ader name.`,
+		`0000001c: ff                                       .                 flags: ReadInt8 = -1`,
+		`0000001d: 0000 002a                                ...*              ReadInt32 = 42`,
+		`00000021:                                                            ReadUint8 error: EOF`,
+		``,
+	}, "\n"), output.String())
+
+	tracer.Reset()
+	require.Empty(t, tracer.Entries())
+}
+
+func TestBinaryReader_TracerUnreadRune(t *testing.T) {
+	reader := NewBinaryReader(bytes.NewReader([]byte("\xe0ok")))
+	tracer := NewTracer()
+	reader.SetTracer(tracer)
+
+	_, _, err := reader.ReadUTF8Rune()
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xe0}, tracer.Entries()[0].Data, "bytes returned back must not be traced")
+
+	char, _, err := reader.ReadUTF8Rune()
+	require.NoError(t, err)
+	require.Equal(t, 'o', char)
+	require.NoError(t, reader.UnreadRune())
+	require.Len(t, tracer.Entries(), 1, "rune returned back must not be traced")
+}
+
+func TestBinaryWriter_Tracer(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	writer := NewBinaryWriter(buffer)
+	tracer := NewTracer()
+	writer.SetTracer(tracer)
+
+	tracer.Label("magic")
+	require.NoError(t, writer.WriteHex("cafe"))
+	require.NoError(t, writer.WriteInt16(-2))
+	require.NoError(t, writer.WriteStringZ("ok"))
+	require.NoError(t, writer.WriteUint32s([]uint32{1}))
+	require.NoError(t, writer.WriteObject(uint8(7)))
+
+	output := new(strings.Builder)
+	require.NoError(t, tracer.Dump(output))
+	require.Equal(t, strings.Join([]string{
+		`00000000: cafe                                     ..                magic: WriteHex`,
+		`00000002: fffe                                     ..                WriteInt16 = -2`,
+		`00000004: 6f6b 00                                  ok.               WriteStringZ = "ok"`,
+		`00000007: 0000 0001                                ....              WriteUint32s = [1]`,
+		`0000000b: 07                                       .                 WriteUint8 = 7`,
+		``,
+	}, "\n"), output.String())
+
+	writer.SetTracer(nil)
+	writer.Tracer().Label("ignored")
+	require.NoError(t, writer.WriteUint8(1))
+	require.Len(t, tracer.Entries(), 5)
+}
@@ -109,62 +109,79 @@ func Int8bytes(data int8) []byte { return []byte{uint8(data)} }
 
 // Uint16bytes adds uint16 data to buffer using big-endian bytes order.
 func Uint16bytes(data uint16) []byte {
-	d := AllocateBytes(Int16size)
-	binary.BigEndian.PutUint16(d, data)
-
-	return d
+	return AppendUint16(make([]byte, 0, Uint16size), data)
 }
 
 // Int16bytes adds int16 data to buffer using big-endian bytes order.
 func Int16bytes(data int16) []byte {
-	d := AllocateBytes(Int16size)
-	binary.BigEndian.PutUint16(d, uint16(data))
-
-	return d
+	return AppendInt16(make([]byte, 0, Int16size), data)
 }
 
 // Uint32bytes adds uint32 data to buffer using big-endian bytes order.
 func Uint32bytes(data uint32) []byte {
-	d := AllocateBytes(Uint32size)
-	binary.BigEndian.PutUint32(d, data)
-
-	return d
+	return AppendUint32(make([]byte, 0, Uint32size), data)
 }
 
 // Int32bytes adds int32 data to buffer using big-endian bytes order.
 func Int32bytes(data int32) []byte {
-	d := AllocateBytes(Uint32size)
-	binary.BigEndian.PutUint32(d, uint32(data))
-
-	return d
+	return AppendInt32(make([]byte, 0, Int32size), data)
 }
 
 // RuneBytes returns rune bytes representation using big-endian bytes order.
 func RuneBytes(char rune) []byte {
-	d := AllocateBytes(RuneSize)
-	binary.BigEndian.PutUint32(d, uint32(char))
-
-	return d
+	return AppendRune(make([]byte, 0, RuneSize), char)
 }
 
 // Uint64bytes adds uint64 data to buffer using big-endian bytes order.
 func Uint64bytes(data uint64) []byte {
-	d := AllocateBytes(Uint64size)
-	binary.BigEndian.PutUint64(d, data)
-
-	return d
+	return AppendUint64(make([]byte, 0, Uint64size), data)
 }
 
 // Int64bytes adds uint64 data to buffer using big-endian bytes order.
 func Int64bytes(data int64) []byte {
-	d := AllocateBytes(Uint64size)
-	binary.BigEndian.PutUint64(d, uint64(data))
+	return AppendInt64(make([]byte, 0, Int64size), data)
+}
+
+// AppendUint8 appends uint8 data to dst and returns the extended buffer.
+func AppendUint8(dst []byte, data uint8) []byte { return append(dst, data) }
+
+// AppendInt8 appends int8 data to dst and returns the extended buffer.
+func AppendInt8(dst []byte, data int8) []byte { return append(dst, uint8(data)) }
 
-	return d
+// AppendUint16 appends uint16 data to dst using big-endian bytes order and returns the extended buffer.
+func AppendUint16(dst []byte, data uint16) []byte {
+	return append(dst, byte(data>>8), byte(data))
 }
 
+// AppendInt16 appends int16 data to dst using big-endian bytes order and returns the extended buffer.
+func AppendInt16(dst []byte, data int16) []byte { return AppendUint16(dst, uint16(data)) }
+
+// AppendUint32 appends uint32 data to dst using big-endian bytes order and returns the extended buffer.
+func AppendUint32(dst []byte, data uint32) []byte {
+	return append(dst, byte(data>>24), byte(data>>16), byte(data>>8), byte(data))
+}
+
+// AppendInt32 appends int32 data to dst using big-endian bytes order and returns the extended buffer.
+func AppendInt32(dst []byte, data int32) []byte { return AppendUint32(dst, uint32(data)) }
+
+// AppendRune appends rune as 4 bytes using big-endian bytes order and returns the extended buffer.
+func AppendRune(dst []byte, char rune) []byte { return AppendUint32(dst, uint32(char)) }
+
+// AppendUint64 appends uint64 data to dst using big-endian bytes order and returns the extended buffer.
+func AppendUint64(dst []byte, data uint64) []byte {
+	return append(dst,
+		byte(data>>56), byte(data>>48), byte(data>>40), byte(data>>32),
+		byte(data>>24), byte(data>>16), byte(data>>8), byte(data))
+}
+
+// AppendInt64 appends int64 data to dst using big-endian bytes order and returns the extended buffer.
+func AppendInt64(dst []byte, data int64) []byte { return AppendUint64(dst, uint64(data)) }
+
+// AppendStringZ appends zero-terminated string bytes to dst and returns the extended buffer.
+func AppendStringZ(dst []byte, s string) []byte { return append(append(dst, s...), 0) }
+
 // StringBytes makes a zero-terminated string []byte sequence.
-func StringBytes(s string) []byte { return append([]byte(s), 0) }
+func StringBytes(s string) []byte { return AppendStringZ(make([]byte, 0, len(s)+1), s) }
 
 // String reads a zero-terminated string from []byte sequence
 // Returns error if last byte is not 0.
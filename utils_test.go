@@ -508,3 +508,30 @@ func TestRuneBytes(t *testing.T) {
 		})
 	}
 }
+
+func TestAppendFunctions(t *testing.T) {
+	prefix := []byte{0xaa}
+	for _, tt := range []struct {
+		name string
+		got  []byte
+		hex  string
+	}{
+		{"uint8", AppendUint8(prefix, math.MaxUint8), "aaff"},
+		{"int8", AppendInt8(prefix, math.MinInt8), "aa80"},
+		{"uint16", AppendUint16(prefix, 0x0102), "aa0102"},
+		{"int16", AppendInt16(prefix, math.MinInt16), "aa8000"},
+		{"uint32", AppendUint32(prefix, 0x01020304), "aa01020304"},
+		{"int32", AppendInt32(prefix, -1), "aaffffffff"},
+		{"rune", AppendRune(prefix, '星'), "aa0000661f"},
+		{"uint64", AppendUint64(prefix, 0x0102030405060708), "aa0102030405060708"},
+		{"int64", AppendInt64(prefix, math.MinInt64), "aa8000000000000000"},
+		{"stringZ", AppendStringZ(prefix, "ab"), "aa616200"},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hex.EncodeToString(tt.got); got != tt.hex {
+				t.Errorf("Append%v() = %v, want %v", tt.name, got, tt.hex)
+			}
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package binutils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+// WriteVersioned writes data as versioned envelope: version and payload length as unsigned base 128 varints
+// followed by payload encoded by data.BinaryWriteTo.
+// Envelope lets readers decode payloads written by other versions, see ReadVersioned.
+// New fields should be appended to the end of payload only, so older readers could skip them.
+// If data implements BinarySizer payload buffer is preallocated, but not more than DefaultMaxFrameSize.
+func (w *BinaryWriter) WriteVersioned(version uint64, data BinaryWriterTo) error {
+	buffer := new(bytes.Buffer)
+
+	buffer.Grow(preallocSize(data))
+
+	if err := data.BinaryWriteTo(w.derive(buffer)); err != nil {
+		return err
+	}
+
+	if err := w.WriteUvarint(version); err != nil {
+		return err
+	}
+
+	if err := w.WriteUvarint(uint64(buffer.Len())); err != nil {
+		return err
+	}
+
+	return w.WriteBytes(buffer.Bytes())
+}
+
+// ReadVersioned reads versioned envelope written by WriteVersioned decoding its payload into target.
+// Target reads payload from reader limited to payload length, its Version returns envelope version,
+// so target could default fields missing in older versions. Payload bytes left unread by target,
+// e.g. fields appended by newer versions, are skipped regardless strict finish mode.
+// Returns envelope version, io.EOF if no envelope available or ErrFrameSize if payload length overflows int.
+func (r *BinaryReader) ReadVersioned(target BinaryReaderFrom) (version uint64, err error) {
+	if version, err = r.ReadUvarint(); err != nil {
+		return 0, err
+	}
+
+	length, err := r.ReadUvarint()
+	if err == io.EOF {
+		return version, io.ErrUnexpectedEOF // envelope ends after version
+	} else if err != nil {
+		return version, err
+	}
+
+	if size := int(length); size < 0 || uint64(size) != length {
+		return version, fmt.Errorf("%w: versioned payload length %d", ErrFrameSize, length)
+	}
+
+	payload := r.Sub(int(length))
+	payload.version, payload.versioned = version, true
+
+	if err = target.BinaryReadFrom(payload); err != nil {
+		return version, err
+	}
+
+	_, err = payload.Drain()
+
+	return version, err
+}
+
+// Version returns version of envelope being decoded and true if reader is created by ReadVersioned
+// or derived from such reader, e.g. using Sub. Returns zero and false otherwise.
+func (r *BinaryReader) Version() (version uint64, ok bool) {
+	r.mu.Lock()
+	version, ok = r.version, r.versioned
+	r.mu.Unlock()
+
+	return version, ok
+}
@@ -0,0 +1,201 @@
+package binutils_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/amarin/binutils"
+)
+
+// settingsV1 is the first settings version.
+type settingsV1 struct {
+	Width uint16
+}
+
+func (s *settingsV1) BinaryWriteTo(writer *BinaryWriter) error {
+	return writer.WriteUint16(s.Width)
+}
+
+func (s *settingsV1) BinaryReadFrom(reader *BinaryReader) (err error) {
+	s.Width, err = reader.ReadUint16()
+	return err
+}
+
+// settingsV2 appends title field defaulted for older versions.
+type settingsV2 struct {
+	Width uint16
+	Title string
+}
+
+func (s *settingsV2) BinaryWriteTo(writer *BinaryWriter) error {
+	if err := writer.WriteUint16(s.Width); err != nil {
+		return err
+	}
+
+	return writer.WriteStringZ(s.Title)
+}
+
+func (s *settingsV2) BinaryReadFrom(reader *BinaryReader) (err error) {
+	if s.Width, err = reader.ReadUint16(); err != nil {
+		return err
+	}
+
+	if version, _ := reader.Version(); version < 2 {
+		s.Title = "untitled"
+		return nil
+	}
+
+	s.Title, err = reader.ReadStringZ()
+
+	return err
+}
+
+func TestBinaryReader_ReadVersioned(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	writer := NewBinaryWriter(buffer)
+	require.NoError(t, writer.WriteVersioned(2, &settingsV2{Width: 640, Title: "main"}))
+	require.NoError(t, writer.WriteVersioned(1, &settingsV1{Width: 800}))
+	require.Equal(t, []byte{2, 7, 0x02, 0x80, 'm', 'a', 'i', 'n', 0, 1, 2, 0x03, 0x20}, buffer.Bytes())
+
+	t.Run("old_reader", func(t *testing.T) {
+		reader := NewBinaryReader(bytes.NewReader(buffer.Bytes()))
+		reader.SetStrictFinish(true)
+
+		first := new(settingsV1)
+		version, err := reader.ReadVersioned(first)
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), version)
+		require.Equal(t, settingsV1{Width: 640}, *first, "newer trailing fields must be skipped")
+
+		second := new(settingsV1)
+		version, err = reader.ReadVersioned(second)
+		require.NoError(t, err)
+		require.Equal(t, uint64(1), version)
+		require.Equal(t, settingsV1{Width: 800}, *second)
+
+		_, err = reader.ReadVersioned(second)
+		require.Equal(t, io.EOF, err)
+	})
+
+	t.Run("new_reader", func(t *testing.T) {
+		reader := NewBinaryReader(bytes.NewReader(buffer.Bytes()))
+
+		first := new(settingsV2)
+		_, err := reader.ReadVersioned(first)
+		require.NoError(t, err)
+		require.Equal(t, settingsV2{Width: 640, Title: "main"}, *first)
+
+		second := new(settingsV2)
+		_, err = reader.ReadVersioned(second)
+		require.NoError(t, err)
+		require.Equal(t, settingsV2{Width: 800, Title: "untitled"}, *second, "missing fields must be defaulted")
+	})
+}
+
+// versionProbe records versions seen by envelope payload reader and its sub reader.
+type versionProbe struct {
+	versions []uint64
+}
+
+func (p *versionProbe) BinaryReadFrom(reader *BinaryReader) error {
+	version, ok := reader.Version()
+	if !ok {
+		return errors.New("payload reader must be versioned")
+	}
+
+	subVersion, ok := reader.Sub(1).Version()
+	if !ok {
+		return errors.New("sub reader must inherit version")
+	}
+
+	p.versions = append(p.versions, version, subVersion)
+
+	return nil
+}
+
+func TestBinaryReader_Version(t *testing.T) {
+	reader := NewBinaryReader(bytes.NewReader([]byte{0x96, 0x01, 1, 0xff, 7}))
+
+	version, ok := reader.Version()
+	require.False(t, ok)
+	require.Zero(t, version)
+
+	probe := new(versionProbe)
+	version, err := reader.ReadVersioned(probe)
+	require.NoError(t, err)
+	require.Equal(t, uint64(150), version)
+	require.Equal(t, []uint64{150, 150}, probe.versions)
+
+	_, ok = reader.Version()
+	require.False(t, ok, "envelope version must not leak into outer reader")
+
+	next, err := reader.ReadUint8()
+	require.NoError(t, err)
+	require.Equal(t, uint8(7), next)
+}
+
+func TestBinaryReader_ReadVersionedErrors(t *testing.T) {
+	hugeLength := NewBinaryWriterBuffer(nil)
+	require.NoError(t, hugeLength.WriteUvarint(1))
+	require.NoError(t, hugeLength.WriteUvarint(math.MaxUint64))
+
+	targetError := errors.New("target error")
+
+	for _, tt := range []struct {
+		name     string
+		data     []byte
+		target   BinaryReaderFrom
+		expected error
+	}{
+		{name: "empty", data: nil, target: new(settingsV1), expected: io.EOF},
+		{name: "no_length", data: []byte{1}, target: new(settingsV1), expected: io.ErrUnexpectedEOF},
+		{name: "short_payload", data: []byte{1, 4, 0, 1}, target: new(settingsV1), expected: io.ErrUnexpectedEOF},
+		{name: "payload_too_short", data: []byte{1, 1, 0}, target: new(settingsV1), expected: io.ErrUnexpectedEOF},
+		{name: "huge_length", data: hugeLength.Bytes(), target: new(settingsV1), expected: ErrFrameSize},
+		{name: "target_error", data: []byte{1, 0}, target: failingDecoder{err: targetError}, expected: targetError},
+	} {
+		tt := tt // pin tt
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewBinaryReader(bytes.NewReader(tt.data)).ReadVersioned(tt.target)
+			require.True(t, errors.Is(err, tt.expected), "unexpected error %v", err)
+		})
+	}
+}
+
+// failingDecoder returns configured error.
+type failingDecoder struct {
+	err error
+}
+
+func (d failingDecoder) BinaryReadFrom(*BinaryReader) error {
+	return d.err
+}
+
+func TestBinaryWriter_WriteVersionedError(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	writer := NewBinaryWriter(buffer)
+
+	err := writer.WriteVersioned(1, failingEncoder{})
+	require.True(t, errors.Is(err, io.ErrShortWrite))
+	require.Zero(t, buffer.Len(), "nothing must be written if payload encoding failed")
+}
+
+func TestBinaryWriter_WriteVersionedWrongSizer(t *testing.T) {
+	for _, size := range []wrongSizer{-1, math.MaxInt32} {
+		buffer := new(bytes.Buffer)
+		require.NoError(t, NewBinaryWriter(buffer).WriteVersioned(1, size))
+		require.Equal(t, []byte{1, 1, 1}, buffer.Bytes())
+	}
+}
+
+// failingEncoder fails payload encoding.
+type failingEncoder struct{}
+
+func (failingEncoder) BinaryWriteTo(*BinaryWriter) error {
+	return io.ErrShortWrite
+}
@@ -2,6 +2,7 @@ package binutils
 
 import (
 	"encoding"
+	"encoding/binary"
 	"encoding/hex"
 	"errors"
 	"fmt"
@@ -9,6 +10,7 @@ import (
 	"os"
 	"path/filepath"
 	"sync"
+	"unicode/utf8"
 )
 
 var (
@@ -21,14 +23,18 @@ var (
 
 // BinaryWriter implements binary writing for various data types into file writer.
 type BinaryWriter struct {
-	mu           *sync.Mutex // write mutex protects underlying fields
-	writer       io.Writer   // underlying io.Writer
-	bytesWritten int         // written bytes counter
+	mu           *sync.Mutex                 // write mutex protects underlying fields
+	writer       io.Writer                   // underlying io.Writer
+	bytesWritten int                         // written bytes counter
+	encoding     TextEncoding                // strings encoding, nil means strings bytes are written as is
+	scratch      [binary.MaxVarintLen64]byte // typed writes buffer, protected by mu
+	order        binary.ByteOrder            // multi-byte values bytes order
+	tracer       *Tracer                     // typed operations tracer or nil, see SetTracer
 }
 
 // NewBinaryWriter wraps existing io.Writer instance into BinaryWriter.
 func NewBinaryWriter(writer io.Writer) *BinaryWriter {
-	return &BinaryWriter{writer: writer, bytesWritten: 0, mu: new(sync.Mutex)}
+	return &BinaryWriter{writer: writer, bytesWritten: 0, mu: new(sync.Mutex), order: binary.BigEndian}
 }
 
 // BytesWritten returns written bytes counter value.
@@ -54,6 +60,39 @@ func (w *BinaryWriter) increaseBytesWritten(addBytesCount int) {
 	w.mu.Unlock()
 }
 
+// SetByteOrder sets bytes order used to write multi-byte values. Big-endian bytes order used by default.
+func (w *BinaryWriter) SetByteOrder(order binary.ByteOrder) {
+	w.mu.Lock()
+	w.order = order
+	w.mu.Unlock()
+}
+
+// ByteOrder returns bytes order used to write multi-byte values.
+func (w *BinaryWriter) ByteOrder() (order binary.ByteOrder) {
+	w.mu.Lock()
+	order = w.order
+	w.mu.Unlock()
+
+	return order
+}
+
+// SetEncoding sets text encoding used to encode strings written by WriteStringZ and WriteObject.
+// Nil encoding makes strings bytes written as is without any encoding.
+func (w *BinaryWriter) SetEncoding(encoding TextEncoding) {
+	w.mu.Lock()
+	w.encoding = encoding
+	w.mu.Unlock()
+}
+
+// Encoding returns text encoding used to encode strings or nil if strings bytes are written as is.
+func (w *BinaryWriter) Encoding() (encoding TextEncoding) {
+	w.mu.Lock()
+	encoding = w.encoding
+	w.mu.Unlock()
+
+	return encoding
+}
+
 // CreateFile creates file and wrap file writer into BinaryWriter.
 // Target file will be created.
 func CreateFile(filePath string) (*BinaryWriter, error) {
@@ -84,13 +123,24 @@ func (w BinaryWriter) Close() error {
 // Implements io.Writer.
 func (w *BinaryWriter) Write(p []byte) (bytesWritten int, err error) {
 	w.mu.Lock()
+	bytesWritten, err = w.writeLocked(p)
+	w.mu.Unlock()
+
+	return bytesWritten, err
+}
+
+// writeLocked writes into underlying writer counting written bytes. Requires w.mu held.
+func (w *BinaryWriter) writeLocked(p []byte) (bytesWritten int, err error) {
 	bytesWritten, err = w.writer.Write(p)
 	w.bytesWritten += bytesWritten
-	w.mu.Unlock()
+
+	if w.tracer != nil && bytesWritten > 0 {
+		w.tracer.capture(p[:bytesWritten])
+	}
 
 	switch {
 	case err != nil:
-		return bytesWritten, fmt.Errorf("%v: uint8: %w", ErrWriterWrite, err)
+		return bytesWritten, fmt.Errorf("%v: %w", ErrWriterWrite, err)
 	case bytesWritten != len(p):
 		return bytesWritten, fmt.Errorf(
 			"%v: %w: expected %v written %v",
@@ -108,73 +158,261 @@ func (w *BinaryWriter) write(p []byte) (err error) {
 }
 
 // WriteUint8 writes uint8 value into writer as bytes.
-func (w *BinaryWriter) WriteUint8(data uint8) error {
-	return w.write(Uint8bytes(data))
+func (w *BinaryWriter) WriteUint8(data uint8) (err error) {
+	w.mu.Lock()
+	tracer := w.traceBeginLocked()
+	_, err = w.writeLocked(AppendUint8(w.scratch[:0], data))
+	w.mu.Unlock()
+
+	if tracer != nil {
+		tracer.end("WriteUint8", data, err)
+	}
+
+	return err
 }
 
 // WriteUint16 writes uint16 value into writer as bytes.
-func (w *BinaryWriter) WriteUint16(data uint16) error {
-	return w.write(Uint16bytes(data))
+func (w *BinaryWriter) WriteUint16(data uint16) (err error) {
+	w.mu.Lock()
+	tracer := w.traceBeginLocked()
+	w.order.PutUint16(w.scratch[:Uint16size], data)
+	_, err = w.writeLocked(w.scratch[:Uint16size])
+	w.mu.Unlock()
+
+	if tracer != nil {
+		tracer.end("WriteUint16", data, err)
+	}
+
+	return err
 }
 
 // WriteUint32 writes uint16 value into writer as bytes.
-func (w *BinaryWriter) WriteUint32(data uint32) error {
-	return w.write(Uint32bytes(data))
+func (w *BinaryWriter) WriteUint32(data uint32) (err error) {
+	w.mu.Lock()
+	tracer := w.traceBeginLocked()
+	w.order.PutUint32(w.scratch[:Uint32size], data)
+	_, err = w.writeLocked(w.scratch[:Uint32size])
+	w.mu.Unlock()
+
+	if tracer != nil {
+		tracer.end("WriteUint32", data, err)
+	}
+
+	return err
 }
 
 // WriteRune writes rune value into writer as uint32 bytes.
+// Use BinaryReader.ReadRune or ReadUTF32Rune to read it back or WriteUTF8Rune to write UTF-8 encoded character.
+//
+// Deprecated: use WriteUTF32Rune or WriteUTF8Rune to state rune encoding explicitly.
 func (w *BinaryWriter) WriteRune(char rune) error {
-	return w.write(RuneBytes(char))
+	tracer := w.traceBegin()
+	err := w.WriteUint32(uint32(char))
+
+	if tracer != nil {
+		tracer.end("WriteRune", char, err)
+	}
+
+	return err
+}
+
+// WriteUTF32Rune writes rune value into writer as 4 bytes UTF-32 code unit. Same as WriteRune.
+func (w *BinaryWriter) WriteUTF32Rune(char rune) error {
+	tracer := w.traceBegin()
+	err := w.WriteUint32(uint32(char))
+
+	if tracer != nil {
+		tracer.end("WriteUTF32Rune", char, err)
+	}
+
+	return err
+}
+
+// WriteUTF8Rune writes rune value into writer as UTF-8 encoded character.
+func (w *BinaryWriter) WriteUTF8Rune(char rune) (err error) {
+	w.mu.Lock()
+	tracer := w.traceBeginLocked()
+	encoded := w.scratch[:utf8.UTFMax]
+	_, err = w.writeLocked(encoded[:utf8.EncodeRune(encoded, char)])
+	w.mu.Unlock()
+
+	if tracer != nil {
+		tracer.end("WriteUTF8Rune", char, err)
+	}
+
+	return err
 }
 
 // WriteUint64 writes uint64 value into writer as bytes.
-func (w *BinaryWriter) WriteUint64(data uint64) error {
-	return w.write(Uint64bytes(data))
+func (w *BinaryWriter) WriteUint64(data uint64) (err error) {
+	w.mu.Lock()
+	tracer := w.traceBeginLocked()
+	w.order.PutUint64(w.scratch[:Uint64size], data)
+	_, err = w.writeLocked(w.scratch[:Uint64size])
+	w.mu.Unlock()
+
+	if tracer != nil {
+		tracer.end("WriteUint64", data, err)
+	}
+
+	return err
+}
+
+// WriteUvarint writes uint64 value into writer as unsigned base 128 varint like binary.PutUvarint does.
+func (w *BinaryWriter) WriteUvarint(data uint64) (err error) {
+	w.mu.Lock()
+	tracer := w.traceBeginLocked()
+	_, err = w.writeLocked(w.scratch[:binary.PutUvarint(w.scratch[:], data)])
+	w.mu.Unlock()
+
+	if tracer != nil {
+		tracer.end("WriteUvarint", data, err)
+	}
+
+	return err
+}
+
+// WriteVarint writes int64 value into writer as signed zig-zag base 128 varint like binary.PutVarint does.
+func (w *BinaryWriter) WriteVarint(data int64) (err error) {
+	w.mu.Lock()
+	tracer := w.traceBeginLocked()
+	_, err = w.writeLocked(w.scratch[:binary.PutVarint(w.scratch[:], data)])
+	w.mu.Unlock()
+
+	if tracer != nil {
+		tracer.end("WriteVarint", data, err)
+	}
+
+	return err
 }
 
 // WriteUint uint value into writer as bytes.
-func (w *BinaryWriter) WriteUint(data uint) (err error) {
-	return w.write(Uint64bytes(uint64(data)))
+func (w *BinaryWriter) WriteUint(data uint) error {
+	tracer := w.traceBegin()
+	err := w.WriteUint64(uint64(data))
+
+	if tracer != nil {
+		tracer.end("WriteUint", data, err)
+	}
+
+	return err
 }
 
 // WriteInt8 writes int8 value into writer as byte.
 func (w *BinaryWriter) WriteInt8(data int8) error {
-	return w.write(Int8bytes(data))
+	tracer := w.traceBegin()
+	err := w.WriteUint8(uint8(data))
+
+	if tracer != nil {
+		tracer.end("WriteInt8", data, err)
+	}
+
+	return err
 }
 
 // WriteInt16 writes int16 value into writer as bytes.
 func (w *BinaryWriter) WriteInt16(data int16) error {
-	return w.write(Int16bytes(data))
+	tracer := w.traceBegin()
+	err := w.WriteUint16(uint16(data))
+
+	if tracer != nil {
+		tracer.end("WriteInt16", data, err)
+	}
+
+	return err
 }
 
 // WriteInt32 writes int32 value into writer as bytes.
 func (w *BinaryWriter) WriteInt32(data int32) error {
-	return w.write(Int32bytes(data))
+	tracer := w.traceBegin()
+	err := w.WriteUint32(uint32(data))
+
+	if tracer != nil {
+		tracer.end("WriteInt32", data, err)
+	}
+
+	return err
 }
 
 // WriteInt64 writes int64 value into writer as bytes.
 func (w *BinaryWriter) WriteInt64(data int64) error {
-	return w.write(Int64bytes(data))
+	tracer := w.traceBegin()
+	err := w.WriteUint64(uint64(data))
+
+	if tracer != nil {
+		tracer.end("WriteInt64", data, err)
+	}
+
+	return err
 }
 
 // WriteInt int value into writer as bytes.
 func (w *BinaryWriter) WriteInt(data int) error {
-	return w.write(Int64bytes(int64(data)))
+	tracer := w.traceBegin()
+	err := w.WriteUint64(uint64(data))
+
+	if tracer != nil {
+		tracer.end("WriteInt", data, err)
+	}
+
+	return err
 }
 
 // WriteStringZ writes string bytes into underlying writer as Zero-terminated string.
+// If text encoding set using SetEncoding string is encoded and terminated by zero code unit of that encoding.
 func (w *BinaryWriter) WriteStringZ(data string) error {
-	return w.write(StringBytes(data))
+	tracer := w.traceBegin()
+	err := w.writeStringZ(data)
+
+	if tracer != nil {
+		tracer.end("WriteStringZ", data, err)
+	}
+
+	return err
+}
+
+// writeStringZ implements WriteStringZ.
+func (w *BinaryWriter) writeStringZ(data string) error {
+	encoding := w.Encoding()
+	if encoding == nil {
+		return w.write(StringBytes(data))
+	}
+
+	encoded, err := encoding.Encode(data)
+	if err != nil {
+		return fmt.Errorf("%v: %w", ErrWriterWrite, err)
+	}
+
+	return w.write(append(encoded, AllocateBytes(encoding.UnitSize())...))
 }
 
 // WriteBytes writes byte string into underlying writer.
 // Returns error if written bytes count mismatch specified byte string length or any underlying error if occurs.
 func (w *BinaryWriter) WriteBytes(data []byte) error {
-	return w.write(data)
+	tracer := w.traceBegin()
+	err := w.write(data)
+
+	if tracer != nil {
+		tracer.end("WriteBytes", nil, err)
+	}
+
+	return err
 }
 
 // WriteHex adds byte string defined by hex string into writer.
 func (w *BinaryWriter) WriteHex(hexString string) error {
+	tracer := w.traceBegin()
+	err := w.writeHex(hexString)
+
+	if tracer != nil {
+		tracer.end("WriteHex", nil, err)
+	}
+
+	return err
+}
+
+// writeHex implements WriteHex.
+func (w *BinaryWriter) writeHex(hexString string) error {
 	data, err := hex.DecodeString(hexString)
 	if err != nil {
 		return fmt.Errorf("%v: %v: hex: %w", ErrWriter, ErrDecodeTo, err)
@@ -184,17 +422,28 @@ func (w *BinaryWriter) WriteHex(hexString string) error {
 }
 
 // WriteObject writes object data into underlying writer.
-// User specified data types data must be one of io.WriterTo, BinaryWriterTo, BinaryUint8, BinaryUint16, BinaryUint32, BinaryUint64,
+// User specified data types data must be one of BinaryEncoder, io.WriterTo, BinaryWriterTo, BinaryUint8, BinaryUint16, BinaryUint32, BinaryUint64,
 // BinaryInt8, BinaryInt16, BinaryInt32, BinaryInt64 or BinaryRune interface implementation.
-// Basic Int[8-64], Uint[8-64] or pointers to it are simply generates bigEndian bytes.
+// Basic Int[8-64], Uint[8-64] or pointers to it are simply generates bytes using configured byte order.
 //
 // If multiple interfaces implemented first of described order will be used.
 // Use required method directly to fully determined behaviour.
 // Returns error if caused internally. To get written bytes counter use BytesWritten result.
+//
+// If data implements BinarySizer and underlying writer has Grow method, e.g. bytes.Buffer or BufferWriter,
+// underlying writer is extended once beforehand, but not more than DefaultMaxFrameSize.
 func (w *BinaryWriter) WriteObject(data interface{}) (err error) {
 	n := int64(0)
 
+	if size := preallocSize(data); size > 0 {
+		if grower, ok := w.writer.(interface{ Grow(n int) }); ok {
+			grower.Grow(size)
+		}
+	}
+
 	switch typedValue := data.(type) {
+	case BinaryEncoder:
+		return typedValue.EncodeBinary(w)
 	case io.WriterTo:
 		n, err = typedValue.WriteTo(w)
 		w.increaseBytesWritten(int(n))
@@ -293,7 +542,7 @@ func (w *BinaryWriter) WriteObject(data interface{}) (err error) {
 		return w.WriteBytes(*typedValue)
 	default:
 		return fmt.Errorf(
-			"%w: %T should implement io.WriterTo, binutils.BinaryWriterTo or any Binary<Type> interface",
+			"%w: %T should implement binutils.BinaryEncoder, io.WriterTo, binutils.BinaryWriterTo or any Binary<Type> interface",
 			ErrWriterWrite, typedValue,
 		)
 	}
@@ -3,6 +3,9 @@ package binutils_test
 import (
 	"bytes"
 	"encoding/hex"
+	"errors"
+	"io"
+	"io/ioutil"
 	"math"
 	"testing"
 
@@ -445,3 +448,103 @@ func TestBinaryWriter_WriteObject(t *testing.T) {
 		})
 	}
 }
+
+func TestBinaryWriter_WriteUTF8Rune(t *testing.T) {
+	buffer := new(bytes.Buffer)
+	writer := binutils.NewBinaryWriter(buffer)
+	require.NoError(t, writer.WriteUTF8Rune('星'))
+	require.NoError(t, writer.WriteUTF32Rune('星'))
+	require.Equal(t, "e6989f0000661f", hex.EncodeToString(buffer.Bytes()))
+	require.Equal(t, 7, writer.BytesWritten())
+}
+
+func TestBinaryWriter_ZeroAllocations(t *testing.T) {
+	writer := binutils.NewBinaryWriter(ioutil.Discard)
+
+	for name, write := range map[string]func(){
+		"WriteUint8":     func() { _ = writer.WriteUint8(uint8Value) },
+		"WriteUint16":    func() { _ = writer.WriteUint16(uint16Value) },
+		"WriteUint32":    func() { _ = writer.WriteUint32(uint32Value) },
+		"WriteUint64":    func() { _ = writer.WriteUint64(uint64Value) },
+		"WriteInt64":     func() { _ = writer.WriteInt64(int64Value) },
+		"WriteRune":      func() { _ = writer.WriteRune(runeValue) },
+		"WriteUTF8Rune":  func() { _ = writer.WriteUTF8Rune(runeValue) },
+		"WriteBytes":     func() { _ = writer.WriteBytes(bytesValue) },
+		"WriteInt":       func() { _ = writer.WriteInt(intValue) },
+		"WriteUTF32Rune": func() { _ = writer.WriteUTF32Rune(runeValue) },
+	} {
+		write := write // pin write
+		t.Run(name, func(t *testing.T) {
+			require.Zero(t, testing.AllocsPerRun(100, write))
+		})
+	}
+}
+
+func BenchmarkBinaryWriter_WriteUint16(b *testing.B) {
+	writer := binutils.NewBinaryWriter(ioutil.Discard)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if err := writer.WriteUint16(uint16Value); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBinaryWriter_WriteUint32(b *testing.B) {
+	writer := binutils.NewBinaryWriter(ioutil.Discard)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if err := writer.WriteUint32(uint32Value); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBinaryWriter_WriteInt64(b *testing.B) {
+	writer := binutils.NewBinaryWriter(ioutil.Discard)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if err := writer.WriteInt64(int64Value); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkBinaryWriter_WriteRune(b *testing.B) {
+	writer := binutils.NewBinaryWriter(ioutil.Discard)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		if err := writer.WriteRune(runeValue); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkAppendUint64(b *testing.B) {
+	buffer := make([]byte, 0, binutils.Uint64size)
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		buffer = binutils.AppendUint64(buffer[:0], uint64(i))
+	}
+}
+
+// failingWriter fails every write.
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
+
+func TestBinaryWriter_WriteError(t *testing.T) {
+	writer := binutils.NewBinaryWriter(failingWriter{})
+	err := writer.WriteUint32(1)
+	require.True(t, errors.Is(err, io.ErrClosedPipe))
+	require.Contains(t, err.Error(), binutils.ErrWriterWrite.Error())
+	require.NotContains(t, err.Error(), "uint8", "error must not name wrong type")
+	require.Zero(t, writer.BytesWritten())
+}